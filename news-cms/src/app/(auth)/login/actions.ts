@@ -1,6 +1,6 @@
 'use server';
 
-import { api } from '@/lib/api';
+import { api, apiErrorMessage } from '@/lib/api';
 import { setAuthToken, removeAuthToken } from '@/lib/auth';
 import { redirect } from 'next/navigation';
 
@@ -20,7 +20,7 @@ export async function loginAction(prevState: any, formData: FormData) {
         await setAuthToken(token);
     } catch (err: any) {
         return {
-            error: err.response?.data?.message || err.response?.data || 'Invalid credentials'
+            error: apiErrorMessage(err, 'Invalid credentials')
         };
     }
 
@@ -2,10 +2,14 @@
 
 import { api } from '@/lib/api';
 import { DashboardStats } from '@/types';
+import { getAuthToken } from '@/lib/auth';
 
 export async function getDashboardStats(): Promise<DashboardStats | null> {
     try {
-        const response = await api.get('/stats');
+        const token = await getAuthToken();
+        const response = await api.get('/stats', {
+            headers: { Authorization: `Bearer ${token}` }
+        });
         return response.data;
     } catch (error: any) {
         console.error('Failed to fetch dashboard stats:', error.message);
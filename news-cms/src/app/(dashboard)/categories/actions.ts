@@ -1,6 +1,6 @@
 'use server';
 
-import { api } from '@/lib/api';
+import { api, apiErrorMessage } from '@/lib/api';
 import { getAuthToken } from '@/lib/auth';
 import { Category } from '@/types';
 import { revalidatePath } from 'next/cache';
@@ -31,7 +31,7 @@ export async function createCategoryAction(prevState: any, formData: FormData) {
 
         revalidatePath('/categories');
     } catch (error: any) {
-        return { error: error.response?.data?.message || 'Failed to create category' };
+        return { error: apiErrorMessage(error, 'Failed to create category') };
     }
     redirect('/categories');
 }
@@ -51,7 +51,7 @@ export async function updateCategoryAction(id: string, prevState: any, formData:
 
         revalidatePath('/categories');
     } catch (error: any) {
-        return { error: error.response?.data?.message || 'Failed to update category' };
+        return { error: apiErrorMessage(error, 'Failed to update category') };
     }
     redirect('/categories');
 }
@@ -65,6 +65,6 @@ export async function deleteCategoryAction(id: string) {
         revalidatePath('/categories');
         return { success: true };
     } catch (error: any) {
-        return { error: error.response?.data?.message || 'Failed to delete category' };
+        return { error: apiErrorMessage(error, 'Failed to delete category') };
     }
 }
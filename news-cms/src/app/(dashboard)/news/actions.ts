@@ -1,6 +1,6 @@
 'use server';
 
-import { api } from '@/lib/api';
+import { api, apiErrorMessage } from '@/lib/api';
 import { getAuthToken } from '@/lib/auth';
 import { revalidatePath } from 'next/cache';
 import { News, PaginatedResponse } from '@/types';
@@ -42,7 +42,7 @@ export async function createNewsAction(prevState: any, formData: FormData) {
         return { success: true };
     } catch (error: any) {
         console.error('Create news error:', error.response?.data || error.message);
-        return { error: error.response?.data?.message || 'Failed to create news' };
+        return { error: apiErrorMessage(error, 'Failed to create news') };
     }
 }
 
@@ -61,7 +61,7 @@ export async function updateNewsAction(id: string, prevState: any, formData: For
         return { success: true };
     } catch (error: any) {
         console.error('Update news error:', error.response?.data || error.message);
-        return { error: error.response?.data?.message || 'Failed to update news' };
+        return { error: apiErrorMessage(error, 'Failed to update news') };
     }
 }
 
@@ -74,6 +74,6 @@ export async function deleteNewsAction(id: string) {
         revalidatePath('/news');
         return { success: true };
     } catch (error: any) {
-        return { error: error.response?.data?.message || 'Failed to delete news' };
+        return { error: apiErrorMessage(error, 'Failed to delete news') };
     }
 }
@@ -1,6 +1,6 @@
 'use server';
 
-import { api } from '@/lib/api';
+import { api, apiErrorMessage } from '@/lib/api';
 import { getAuthToken } from '@/lib/auth';
 import { revalidatePath } from 'next/cache';
 import { redirect } from 'next/navigation';
@@ -20,6 +20,6 @@ export async function createNewsAction(prevState: any, formData: FormData) {
         return { success: true };
     } catch (error: any) {
         console.error('Create news error:', error.response?.data || error.message);
-        return { error: error.response?.data?.message || 'Failed to create news' };
+        return { error: apiErrorMessage(error, 'Failed to create news') };
     }
 }
@@ -1,6 +1,6 @@
 'use server';
 
-import { api } from '@/lib/api';
+import { api, apiErrorMessage } from '@/lib/api';
 import { getAuthToken } from '@/lib/auth';
 import { revalidatePath } from 'next/cache';
 import { redirect } from 'next/navigation';
@@ -30,6 +30,6 @@ export async function updateNewsAction(id: string, prevState: any, formData: For
         return { success: true };
     } catch (error: any) {
         console.error('Update news error:', error.response?.data || error.message);
-        return { error: error.response?.data?.message || 'Failed to update news' };
+        return { error: apiErrorMessage(error, 'Failed to update news') };
     }
 }
@@ -1,6 +1,6 @@
 'use server';
 
-import { api } from '@/lib/api';
+import { api, apiErrorMessage } from '@/lib/api';
 import { User } from '@/types';
 import { revalidatePath } from 'next/cache';
 import { getAuthToken } from '@/lib/auth';
@@ -27,7 +27,7 @@ export async function createUser(formData: any) {
         revalidatePath('/users');
         return { success: true };
     } catch (error: any) {
-        return { success: false, error: error.response?.data || error.message };
+        return { success: false, error: apiErrorMessage(error, 'Request failed') };
     }
 }
 
@@ -42,6 +42,6 @@ export async function changePassword(oldPassword: string, newPassword: string) {
         });
         return { success: true };
     } catch (error: any) {
-        return { success: false, error: error.response?.data || error.message };
+        return { success: false, error: apiErrorMessage(error, 'Request failed') };
     }
 }
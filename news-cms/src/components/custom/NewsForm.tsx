@@ -30,6 +30,14 @@ const formSchema = z.object({
     content: z.string().min(20, 'Content must be at least 20 characters'),
     is_featured: z.boolean(),
     thumbnail: z.any().refine((val) => val && (val instanceof File || typeof val === 'string' && val.length > 0), 'Thumbnail is required'),
+    thumbnail_alt: z.string().max(500, 'Alt text must be at most 500 characters'),
+    thumbnail_caption: z.string().max(1000, 'Caption must be at most 1000 characters'),
+    thumbnail_credit: z.string().max(255, 'Credit must be at most 255 characters'),
+    thumbnail_source_url: z.union([z.literal(''), z.string().max(2000).url('Source must be a valid http(s) URL')]),
+    override_alt_check: z.boolean(),
+}).refine((values) => values.override_alt_check || values.thumbnail_alt.trim().length > 0, {
+    message: 'Describe the image for screen readers, or tick the override below',
+    path: ['thumbnail_alt'],
 });
 
 interface NewsFormProps {
@@ -52,6 +60,11 @@ export function NewsForm({ categories, initialData, action: serverAction }: News
             content: initialData?.content || '',
             is_featured: initialData ? initialData.is_featured : false,
             thumbnail: initialData?.thumbnail || '',
+            thumbnail_alt: initialData?.thumbnail_meta?.alt || '',
+            thumbnail_caption: initialData?.thumbnail_meta?.caption || '',
+            thumbnail_credit: initialData?.thumbnail_meta?.credit || '',
+            thumbnail_source_url: initialData?.thumbnail_meta?.source_url || '',
+            override_alt_check: false,
         },
     });
 
@@ -99,6 +112,13 @@ export function NewsForm({ categories, initialData, action: serverAction }: News
             } else if (typeof values.thumbnail === 'string') {
                 formData.append('thumbnail', values.thumbnail);
             }
+            formData.append('thumbnail_alt', values.thumbnail_alt.trim());
+            formData.append('thumbnail_caption', values.thumbnail_caption.trim());
+            formData.append('thumbnail_credit', values.thumbnail_credit.trim());
+            formData.append('thumbnail_source_url', values.thumbnail_source_url.trim());
+            if (values.override_alt_check) {
+                formData.append('override_alt_check', 'true');
+            }
 
             const result = await serverAction(null, formData);
 
@@ -228,6 +248,90 @@ export function NewsForm({ categories, initialData, action: serverAction }: News
                     )}
                 />
 
+                <FormField
+                    control={form.control}
+                    name="thumbnail_alt"
+                    render={({ field }) => (
+                        <FormItem>
+                            <FormLabel>Thumbnail Alt Text</FormLabel>
+                            <FormControl>
+                                <Input placeholder="What the image shows, for screen readers" {...field} />
+                            </FormControl>
+                            <FormDescription className="text-xs italic text-gray-500">
+                                Required to publish an article with a thumbnail
+                            </FormDescription>
+                            <FormMessage />
+                        </FormItem>
+                    )}
+                />
+
+                <FormField
+                    control={form.control}
+                    name="override_alt_check"
+                    render={({ field }) => (
+                        <FormItem className="flex flex-row items-center justify-between rounded-lg border p-3 shadow-sm">
+                            <div className="space-y-0.5">
+                                <FormLabel>Publish Without Alt Text</FormLabel>
+                                <FormDescription>
+                                    Only for purely decorative images
+                                </FormDescription>
+                            </div>
+                            <FormControl>
+                                <input
+                                    type="checkbox"
+                                    checked={field.value}
+                                    onChange={field.onChange}
+                                    className="h-5 w-5 rounded border-gray-300 text-primary focus:ring-primary"
+                                />
+                            </FormControl>
+                        </FormItem>
+                    )}
+                />
+
+                <div className="grid grid-cols-2 gap-4">
+                    <FormField
+                        control={form.control}
+                        name="thumbnail_caption"
+                        render={({ field }) => (
+                            <FormItem>
+                                <FormLabel>Caption</FormLabel>
+                                <FormControl>
+                                    <Input placeholder="Optional caption" {...field} />
+                                </FormControl>
+                                <FormMessage />
+                            </FormItem>
+                        )}
+                    />
+
+                    <FormField
+                        control={form.control}
+                        name="thumbnail_credit"
+                        render={({ field }) => (
+                            <FormItem>
+                                <FormLabel>Credit</FormLabel>
+                                <FormControl>
+                                    <Input placeholder="Photographer or agency" {...field} />
+                                </FormControl>
+                                <FormMessage />
+                            </FormItem>
+                        )}
+                    />
+                </div>
+
+                <FormField
+                    control={form.control}
+                    name="thumbnail_source_url"
+                    render={({ field }) => (
+                        <FormItem>
+                            <FormLabel>Image Source URL</FormLabel>
+                            <FormControl>
+                                <Input type="url" placeholder="https://" {...field} />
+                            </FormControl>
+                            <FormMessage />
+                        </FormItem>
+                    )}
+                />
+
                 <FormField
                     control={form.control}
                     name="excerpt"
@@ -11,3 +11,16 @@ export const api = axios.create({
 
 // We don't add interceptors here because auth is handled by the server (Next.js)
 // or by passing the token explicitly in Server Actions.
+
+// The API answers errors with RFC 9457 problem documents:
+// { title, detail, code, errors: [{ field, code, message }], request_id }
+export function apiErrorMessage(error: any, fallback: string): string {
+    const problem = error?.response?.data;
+    if (problem && typeof problem === 'object') {
+        if (Array.isArray(problem.errors) && problem.errors.length > 0) {
+            return problem.errors.map((e: any) => e.message).join(', ');
+        }
+        return problem.detail || problem.title || fallback;
+    }
+    return error?.message || fallback;
+}
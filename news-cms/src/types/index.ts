@@ -5,6 +5,7 @@ export interface News {
     excerpt?: string;
     content?: string;
     thumbnail: string;
+    thumbnail_meta?: ThumbnailMeta | null;
     category_id: string;
     category_name?: string;
     author_name?: string;
@@ -15,6 +16,13 @@ export interface News {
     views_count: number;
 }
 
+export interface ThumbnailMeta {
+    alt: string;
+    caption: string;
+    credit: string;
+    source_url: string;
+}
+
 export interface Category {
     id: string;
     name: string;
@@ -2,6 +2,10 @@ package main
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -13,9 +17,14 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
+	"golang.org/x/time/rate"
 
 	"news-portal-backend/internal/adapter/handler"
+	"news-portal-backend/internal/adapter/logging"
+	"news-portal-backend/internal/adapter/metrics"
+	customMiddleware "news-portal-backend/internal/adapter/middleware"
 	"news-portal-backend/internal/adapter/storage"
+	"news-portal-backend/internal/adapter/telemetry"
 	"news-portal-backend/internal/core/port"
 	"news-portal-backend/internal/core/service"
 )
@@ -27,11 +36,13 @@ func main() {
 	// 1. Logger Setup
 	appEnv := os.Getenv("APP_ENV")
 	var logger *slog.Logger
+	logOptions := &slog.HandlerOptions{ReplaceAttr: logging.ReplaceAttr}
 	if appEnv == "production" {
-		logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+		logger = slog.New(slog.NewJSONHandler(os.Stdout, logOptions))
 	} else {
-		logger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+		logger = slog.New(slog.NewTextHandler(os.Stdout, logOptions))
 	}
+	logger = slog.New(telemetry.LogHandler(logger.Handler()))
 	slog.SetDefault(logger)
 
 	// 2. Config Loading
@@ -59,6 +70,14 @@ func main() {
 	}
 	allowedOrigins := strings.Split(corsOrigins, ",")
 
+	// Reverse proxies whose X-Forwarded-For is believed. Without any, the
+	// client IP is always the TCP peer.
+	trustedProxies, err := customMiddleware.ParseNetworks(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		logger.Error("Invalid TRUSTED_PROXIES", "error", err)
+		os.Exit(1)
+	}
+
 	// Rate Limit Config
 	rpsStr := os.Getenv("RATE_LIMIT_RPS")
 	burstStr := os.Getenv("RATE_LIMIT_BURST")
@@ -70,16 +89,71 @@ func main() {
 	if burst == 0 {
 		burst = 30
 	}
+	loginPerMinute, _ := strconv.ParseFloat(os.Getenv("RATE_LIMIT_LOGIN_PER_MINUTE"), 64)
+	loginBurst, _ := strconv.Atoi(os.Getenv("RATE_LIMIT_LOGIN_BURST"))
+	if loginPerMinute == 0 {
+		loginPerMinute = 5
+	}
+	if loginBurst == 0 {
+		loginBurst = 5
+	}
+	userRPS, _ := strconv.ParseFloat(os.Getenv("RATE_LIMIT_USER_RPS"), 64)
+	userBurst, _ := strconv.Atoi(os.Getenv("RATE_LIMIT_USER_BURST"))
+	if userRPS == 0 {
+		userRPS = 20
+	}
+	if userBurst == 0 {
+		userBurst = 60
+	}
+	sharePerMinute, _ := strconv.ParseFloat(os.Getenv("RATE_LIMIT_SHARE_PER_MINUTE"), 64)
+	shareBurst, _ := strconv.Atoi(os.Getenv("RATE_LIMIT_SHARE_BURST"))
+	if sharePerMinute == 0 {
+		sharePerMinute = 10
+	}
+	if shareBurst == 0 {
+		shareBurst = 10
+	}
+	rateLimitAllowlist, err := customMiddleware.ParseNetworks(os.Getenv("RATE_LIMIT_ALLOWLIST"))
+	if err != nil {
+		logger.Error("Invalid RATE_LIMIT_ALLOWLIST", "error", err)
+		os.Exit(1)
+	}
+	rateLimitConfig := customMiddleware.RateLimitConfig{
+		Policies: []customMiddleware.RateLimitPolicy{
+			{Name: customMiddleware.RateLimitPublic, Rate: rate.Limit(rps), Burst: burst},
+			{Name: customMiddleware.RateLimitLogin, Rate: rate.Limit(loginPerMinute / 60), Burst: loginBurst},
+			{Name: customMiddleware.RateLimitUser, Rate: rate.Limit(userRPS), Burst: userBurst},
+			{Name: customMiddleware.RateLimitShare, Rate: rate.Limit(sharePerMinute / 60), Burst: shareBurst},
+		},
+		Allowlist: rateLimitAllowlist,
+	}
 
-	// 3. Database
 	ctx := context.Background()
+
+	// Tracing
+	otelServiceName := os.Getenv("OTEL_SERVICE_NAME")
+	if otelServiceName == "" {
+		otelServiceName = "news-portal-backend"
+	}
+	shutdownTracing, err := telemetry.Setup(ctx, os.Getenv("OTEL_TRACES_EXPORTER"), otelServiceName)
+	if err != nil {
+		logger.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	// 3. Database
+	poolConfig, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		logger.Error("Invalid DATABASE_URL", "error", err)
+		os.Exit(1)
+	}
+	poolConfig.ConnConfig.Tracer = telemetry.QueryTracer{}
 	var dbPool *pgxpool.Pool
-	var err error
 
 	// Retry connection with exponential backoff
 	maxRetries := 10
 	for i := 0; i < maxRetries; i++ {
-		dbPool, err = pgxpool.New(ctx, dbURL)
+		dbPool, err = pgxpool.NewWithConfig(ctx, poolConfig)
 		if err == nil {
 			// Test the connection
 			err = dbPool.Ping(ctx)
@@ -98,6 +172,10 @@ func main() {
 	}
 	defer dbPool.Close()
 
+	if err := metrics.RegisterPool(dbPool); err != nil {
+		logger.Error("Failed to register database pool metrics", "error", err)
+	}
+
 	// 3b. Auto-Provisioning (Migration & Initial Data)
 	logger.Info("Checking for database migrations...")
 	if err := RunMigrations(dbURL); err != nil {
@@ -112,49 +190,270 @@ func main() {
 		logger.Error("Failed to ensure initial data", "error", err)
 	}
 
-	authService := service.NewAuthService(store, jwtSecret)
-	categoryService := service.NewCategoryService(store)
-	newsService := service.NewNewsService(store, store)
+	// Rate Limit Store: Postgres shares limits between replicas and falls
+	// back to per-replica limits while the database is slow or unreachable
+	var rateLimitStore port.RateLimitStore = service.NewMemoryRateLimitStore()
+	var rateLimitCleanupJob *service.Job
+	switch os.Getenv("RATE_LIMIT_STORE") {
+	case "", "memory":
+	case "postgres":
+		rateLimitTimeout, err := time.ParseDuration(os.Getenv("RATE_LIMIT_STORE_TIMEOUT"))
+		if err != nil || rateLimitTimeout <= 0 {
+			rateLimitTimeout = 50 * time.Millisecond
+		}
+		rateLimitStore = service.NewFallbackRateLimitStore(store, rateLimitStore, rateLimitTimeout, 10*time.Second)
+		rateLimitCleanupJob = service.NewJob("rate limit cleanup", time.Minute, 30*time.Second, store.DeleteExpiredRateLimits)
+		go rateLimitCleanupJob.Run()
+	default:
+		logger.Error("RATE_LIMIT_STORE must be memory or postgres", "value", os.Getenv("RATE_LIMIT_STORE"))
+		os.Exit(1)
+	}
+	rateLimiter := customMiddleware.NewRateLimiter(rateLimitStore, rateLimitConfig)
 
-	// File Service (R2 Enforced)
-	r2AccountID := os.Getenv("R2_ACCOUNT_ID")
-	r2AccessKey := os.Getenv("R2_ACCESS_KEY_ID")
-	r2SecretKey := os.Getenv("R2_SECRET_ACCESS_KEY")
-	r2Bucket := os.Getenv("R2_BUCKET_NAME")
-	r2PublicURL := os.Getenv("R2_PUBLIC_URL")
+	loginAccountAttempts, _ := strconv.Atoi(os.Getenv("RATE_LIMIT_LOGIN_ACCOUNT_ATTEMPTS"))
+	if loginAccountAttempts <= 0 {
+		loginAccountAttempts = 20
+	}
+	loginAccountWindow, err := time.ParseDuration(os.Getenv("RATE_LIMIT_LOGIN_ACCOUNT_WINDOW"))
+	if err != nil || loginAccountWindow <= 0 {
+		loginAccountWindow = 15 * time.Minute
+	}
+	authService := telemetry.TraceAuthService(service.NewAuthService(store, jwtSecret, rateLimitStore, loginAccountAttempts, loginAccountWindow))
+	// Response Cache (homepage, listings and article pages)
+	cacheFresh, err := time.ParseDuration(os.Getenv("CACHE_FRESH_TTL"))
+	if err != nil || cacheFresh <= 0 {
+		cacheFresh = 30 * time.Second
+	}
+	cacheStale, err := time.ParseDuration(os.Getenv("CACHE_STALE_TTL"))
+	if err != nil || cacheStale <= 0 {
+		cacheStale = 5 * time.Minute
+	}
+	cacheMaxEntries, _ := strconv.Atoi(os.Getenv("CACHE_MAX_ENTRIES"))
+	if cacheMaxEntries <= 0 {
+		cacheMaxEntries = 10000
+	}
+	responseCache := service.NewResponseCache(service.NewMemoryCache(cacheMaxEntries), cacheFresh, cacheStale)
 
-	if r2AccountID == "" || r2AccessKey == "" || r2SecretKey == "" || r2Bucket == "" {
-		logger.Error("R2 configuration missing")
-		os.Exit(1)
+	// CDN Purging (queued and retried so a CDN outage never fails a save)
+	var cdnPurger port.CachePurger = service.NoopCachePurger{}
+	if purgeURL := os.Getenv("CDN_PURGE_URL"); purgeURL != "" {
+		cdnPurger = service.NewHTTPCachePurger(purgeURL, os.Getenv("CDN_PURGE_TOKEN"), os.Getenv("PUBLIC_API_URL"))
+		logger.Info("Purging CDN on content changes", "endpoint", purgeURL)
 	}
+	purgeQueue := service.NewPurgeQueue(cdnPurger, 1000, 5, 2*time.Second)
+	go purgeQueue.Run()
 
-	r2Service, err := service.NewR2Service(r2AccountID, r2AccessKey, r2SecretKey, r2Bucket, r2PublicURL)
-	if err != nil {
-		logger.Error("Failed to initialize R2 service", "error", err)
-		os.Exit(1)
+	categoryService := service.NewCategoryService(store, responseCache, purgeQueue)
+	// View Counting
+	viewDedupWindow, err := time.ParseDuration(os.Getenv("VIEW_DEDUP_WINDOW"))
+	if err != nil || viewDedupWindow <= 0 {
+		viewDedupWindow = 30 * time.Minute
+	}
+	// Per replica: unique views are only exact with sticky sessions or a
+	// single API instance
+	visitorDedup := service.NewVisitorDedup(viewDedupWindow, 500000)
+	// Referrers from our own frontend count as internal navigation
+	trafficClassifier := service.NewTrafficClassifier(allowedOrigins)
+	viewCounter := service.NewViewCounter(store, store, visitorDedup, trafficClassifier, 5*time.Second, 10000)
+	go viewCounter.Run()
+	newsService := telemetry.TraceNewsService(service.NewNewsService(store, store, viewCounter, responseCache, purgeQueue))
+
+	hourlyRetention, err := time.ParseDuration(os.Getenv("VIEW_HOURLY_RETENTION"))
+	if err != nil || hourlyRetention <= 0 {
+		hourlyRetention = 14 * 24 * time.Hour
+	}
+	viewRollup := service.NewViewRollup(store, hourlyRetention)
+	rollupJob := service.NewJob("view rollup", time.Hour, 5*time.Minute, viewRollup.Rollup)
+	go rollupJob.Run()
+
+	trendingScorer := service.NewTrendingScorer(store, 6*time.Hour, 48*time.Hour)
+	trendingJob := service.NewJob("trending scores", 5*time.Minute, time.Minute, trendingScorer.Refresh)
+	go trendingJob.Run()
+
+	// File Service (R2 by default, local disk for development)
+	storageDriver := os.Getenv("STORAGE_DRIVER")
+	if storageDriver == "" {
+		storageDriver = "r2"
+	}
+
+	var fileService port.FileService
+	var localStorage *service.LocalStorageService
+	uploadDir := os.Getenv("LOCAL_STORAGE_DIR")
+	if uploadDir == "" {
+		uploadDir = "./uploads"
+	}
+
+	switch storageDriver {
+	case "local":
+		publicAPIURL := os.Getenv("PUBLIC_API_URL")
+		if publicAPIURL == "" {
+			publicAPIURL = "http://localhost:" + serverPort
+		}
+		publicAPIURL = strings.TrimSuffix(publicAPIURL, "/")
+
+		// Upload URLs get their own key, so a leaked upload signature says
+		// nothing about the key that signs logins
+		uploadSecret := os.Getenv("LOCAL_STORAGE_SIGNING_SECRET")
+		if uploadSecret == "" {
+			mac := hmac.New(sha256.New, []byte(jwtSecret))
+			mac.Write([]byte("local-storage-upload-signing"))
+			uploadSecret = hex.EncodeToString(mac.Sum(nil))
+		}
+
+		localStorage, err = service.NewLocalStorageService(uploadDir, publicAPIURL+"/uploads", publicAPIURL+"/api/v1/media/local", uploadSecret)
+		if err != nil {
+			logger.Error("Failed to initialize local storage", "error", err)
+			os.Exit(1)
+		}
+		fileService = localStorage
+		logger.Info("Using local disk storage", "dir", uploadDir)
+	default:
+		r2AccountID := os.Getenv("R2_ACCOUNT_ID")
+		r2AccessKey := os.Getenv("R2_ACCESS_KEY_ID")
+		r2SecretKey := os.Getenv("R2_SECRET_ACCESS_KEY")
+		r2Bucket := os.Getenv("R2_BUCKET_NAME")
+		r2PublicURL := os.Getenv("R2_PUBLIC_URL")
+
+		if r2AccountID == "" || r2AccessKey == "" || r2SecretKey == "" || r2Bucket == "" {
+			logger.Error("R2 configuration missing")
+			os.Exit(1)
+		}
+
+		r2Service, err := service.NewR2Service(r2AccountID, r2AccessKey, r2SecretKey, r2Bucket, r2PublicURL, telemetry.AWSMiddleware)
+		if err != nil {
+			logger.Error("Failed to initialize R2 service", "error", err)
+			os.Exit(1)
+		}
+		fileService = r2Service
+		logger.Info("Using Cloudflare R2 Storage", "bucket", r2Bucket)
+	}
+
+	maxUploadBytes, _ := strconv.ParseInt(os.Getenv("MEDIA_MAX_UPLOAD_BYTES"), 10, 64)
+	if maxUploadBytes == 0 {
+		maxUploadBytes = 500 << 20 // 500 MB
 	}
-	var fileService port.FileService = r2Service
-	logger.Info("Using Cloudflare R2 Storage", "bucket", r2Bucket)
+	mediaService := service.NewMediaService(store, fileService, maxUploadBytes)
 
 	// Handlers
 	authHandler := handler.NewAuthHandler(authService)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
-	newsHandler := handler.NewNewsHandler(newsService, fileService)
+	newsHandler := handler.NewNewsHandler(newsService, mediaService)
 	seedHandler := handler.NewSeedHandler(newsService)
-	statsService := service.NewStatsService(store, store, store)
+	statsService := service.NewStatsService(store, store, store, store, store)
 	statsHandler := handler.NewStatsHandler(statsService)
+	exportHandler := handler.NewExportHandler(newsService, statsService)
+
+	// Mail (SMTP when configured, otherwise .eml files on disk)
+	mailFrom := os.Getenv("MAIL_FROM")
+	if mailFrom == "" {
+		mailFrom = "News Portal <noreply@localhost>"
+	}
+	var mailer port.Mailer
+	if smtpHost := os.Getenv("SMTP_HOST"); smtpHost != "" {
+		smtpPort, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
+		if smtpPort == 0 {
+			smtpPort = 587
+		}
+		mailer = service.NewSMTPMailer(smtpHost, smtpPort, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), mailFrom)
+		logger.Info("Sending mail over SMTP", "host", smtpHost)
+	} else {
+		mailDir := os.Getenv("MAIL_DIR")
+		if mailDir == "" {
+			mailDir = "./mail"
+		}
+		mailer, err = service.NewFileMailer(mailDir, mailFrom)
+		if err != nil {
+			logger.Error("Failed to initialize file mailer", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("SMTP_HOST not set, writing mail to disk", "dir", mailDir)
+	}
+
+	reportService := service.NewReportService(store, statsService, mailer)
+	reportJob := service.NewJob("editorial reports", time.Minute, 5*time.Minute, reportService.RunDue)
+	go reportJob.Run()
+	reportHandler := handler.NewReportHandler(reportService)
+	mediaHandler := handler.NewMediaHandler(mediaService)
+	var localStorageHandler *handler.LocalStorageHandler
+	if localStorage != nil {
+		localStorageHandler = handler.NewLocalStorageHandler(localStorage)
+	}
+
+	// Health: readiness checks the database, the schema version and storage
+	latestMigration, err := LatestMigrationVersion("migrations")
+	if err != nil {
+		logger.Error("Failed to read migrations", "error", err)
+		os.Exit(1)
+	}
+	healthService := service.NewHealthService(2*time.Second,
+		port.HealthCheck{Name: "database", Check: store.Ping},
+		port.HealthCheck{Name: "migrations", Check: func(ctx context.Context) error {
+			version, dirty, err := store.SchemaVersion(ctx)
+			if err != nil {
+				return err
+			}
+			if dirty {
+				return fmt.Errorf("migration %d failed and left the schema dirty", version)
+			}
+			if version < latestMigration {
+				return fmt.Errorf("schema is at version %d, expected %d", version, latestMigration)
+			}
+			return nil
+		}},
+		// R2 bills every HeadBucket, and probes run every few seconds
+		port.HealthCheck{Name: "storage", Check: service.CachedCheck(fileService.Ping, 15*time.Second)},
+	)
+	healthHandler := handler.NewHealthHandler(healthService)
+
+	// Metrics: a separate admin port keeps /metrics off the public
+	// listener; otherwise it is served on the API port behind a token
+	metricsAddr := os.Getenv("METRICS_ADDR")
+	metricsToken := os.Getenv("METRICS_TOKEN")
+	var metricsHandler http.Handler
+	var adminSrv *http.Server
+	switch {
+	case metricsAddr != "":
+		adminMux := http.NewServeMux()
+		adminMux.Handle("GET /metrics", metrics.Handler(metricsToken))
+		adminSrv = &http.Server{Addr: metricsAddr, Handler: adminMux}
+	case metricsToken != "":
+		metricsHandler = metrics.Handler(metricsToken)
+	default:
+		logger.Info("Metrics disabled, set METRICS_ADDR or METRICS_TOKEN to enable them")
+	}
+
+	// Access log: successful health probes are sampled, failures always logged
+	healthSampleRate, err := strconv.ParseFloat(os.Getenv("ACCESS_LOG_HEALTH_SAMPLE_RATE"), 64)
+	if err != nil || healthSampleRate < 0 {
+		healthSampleRate = 0
+	}
 
 	// 5. Router Setup
 	router := NewRouter(RouterConfig{
 		AllowedOrigins:  allowedOrigins,
-		RPS:             rps,
-		Burst:           burst,
+		TrustedProxies:  trustedProxies,
+		RateLimiter:     rateLimiter,
 		JWTSecret:       jwtSecret,
 		AuthHandler:     authHandler,
 		CategoryHandler: categoryHandler,
 		NewsHandler:     newsHandler,
 		StatsHandler:    statsHandler,
 		SeedHandler:     seedHandler,
+		MediaHandler:    mediaHandler,
+		ExportHandler:   exportHandler,
+		ReportHandler:   reportHandler,
+		HealthHandler:   healthHandler,
+
+		LocalStorageHandler: localStorageHandler,
+		UploadDir:           uploadDir,
+
+		AccessLog: logging.Config{
+			HealthSampleRate: healthSampleRate,
+			Headers:          os.Getenv("ACCESS_LOG_HEADERS") == "true",
+		},
+
+		MetricsHandler:    metricsHandler,
+		ValidateResponses: os.Getenv("OPENAPI_VALIDATE_RESPONSES") == "true",
 	})
 
 	// 6. Graceful Shutdown Setup
@@ -171,9 +470,31 @@ func main() {
 		}
 	}()
 
+	if adminSrv != nil {
+		go func() {
+			logger.Info("Metrics server starting", "addr", metricsAddr)
+			if err := adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("Metrics server failed", "error", err)
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
+
+	// Fail readiness first and keep serving while load balancers notice,
+	// so no new traffic arrives once the listener closes
+	drainDelay := 5 * time.Second
+	if v := os.Getenv("SHUTDOWN_DRAIN_DELAY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			drainDelay = d
+		}
+	}
+	healthService.Drain()
+	logger.Info("Draining before shutdown", "delay", drainDelay)
+	time.Sleep(drainDelay)
+
 	logger.Info("Shutting down server...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -182,6 +503,33 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Error("Server forced to shutdown", "error", err)
 	}
+	if adminSrv != nil {
+		if err := adminSrv.Shutdown(ctx); err != nil {
+			logger.Error("Metrics server forced to shutdown", "error", err)
+		}
+	}
+
+	// Write out buffered view counts before the pool closes
+	if err := viewCounter.Close(ctx); err != nil {
+		logger.Error("Failed to flush view counts", "error", err)
+	}
+	if err := purgeQueue.Close(ctx); err != nil {
+		logger.Error("Failed to send pending CDN purges", "error", err)
+	}
+	jobs := []*service.Job{rollupJob, trendingJob, reportJob}
+	if rateLimitCleanupJob != nil {
+		jobs = append(jobs, rateLimitCleanupJob)
+	}
+	for _, job := range jobs {
+		if err := job.Close(ctx); err != nil {
+			logger.Error("Background job did not stop in time", "error", err)
+		}
+	}
+
+	// Export the spans still buffered, including those of the flushes above
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("Failed to flush traces", "error", err)
+	}
 
 	logger.Info("Server exiting")
 }
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -89,3 +92,22 @@ func RunMigrations(dbURL string) error {
 
 	return nil
 }
+
+// LatestMigrationVersion returns the highest version among the up
+// migrations in dir, which is where RunMigrations leaves the schema
+func LatestMigrationVersion(dir string) (int64, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return 0, err
+	}
+	var latest int64
+	for _, f := range files {
+		prefix, _, _ := strings.Cut(filepath.Base(f), "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unexpected migration file name %q", filepath.Base(f))
+		}
+		latest = max(latest, version)
+	}
+	return latest, nil
+}
@@ -1,77 +1,212 @@
 package main
 
 import (
+	"log/slog"
 	"net/http"
+	"net/netip"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 
 	"news-portal-backend/internal/adapter/handler"
+	"news-portal-backend/internal/adapter/logging"
+	"news-portal-backend/internal/adapter/metrics"
 	customMiddleware "news-portal-backend/internal/adapter/middleware"
+	"news-portal-backend/internal/adapter/openapi"
+	"news-portal-backend/internal/adapter/problem"
+	"news-portal-backend/internal/adapter/telemetry"
+	"news-portal-backend/internal/core/domain"
+	"news-portal-backend/internal/core/port"
 )
 
 type RouterConfig struct {
 	AllowedOrigins  []string
-	RPS             float64
-	Burst           int
+	TrustedProxies  []netip.Prefix
+	RateLimiter     *customMiddleware.RateLimiter
 	JWTSecret       string
 	AuthHandler     *handler.AuthHandler
 	CategoryHandler *handler.CategoryHandler
 	NewsHandler     *handler.NewsHandler
 	StatsHandler    *handler.StatsHandler
 	SeedHandler     *handler.SeedHandler
+	MediaHandler    *handler.MediaHandler
+	ExportHandler   *handler.ExportHandler
+	ReportHandler   *handler.ReportHandler
+	HealthHandler   *handler.HealthHandler
+
+	// Only set when uploads are stored on local disk
+	LocalStorageHandler *handler.LocalStorageHandler
+	UploadDir           string
+
+	// Serves /metrics on the API port when set; nil when metrics are
+	// served on the admin port or disabled
+	MetricsHandler http.Handler
+
+	AccessLog logging.Config
+
+	// ValidateResponses logs every response that doesn't match the
+	// OpenAPI spec. Meant for development and CI.
+	ValidateResponses bool
 }
 
+// Cache policies for the public read endpoints
+var (
+	homepageCache = customMiddleware.CachePolicy{
+		MaxAge:               30 * time.Second,
+		SharedMaxAge:         time.Minute,
+		StaleWhileRevalidate: 5 * time.Minute,
+		SurrogateKeys:        []string{port.SurrogateKeyHomepage},
+	}
+	newsListCache = customMiddleware.CachePolicy{
+		MaxAge:               30 * time.Second,
+		SharedMaxAge:         time.Minute,
+		StaleWhileRevalidate: 5 * time.Minute,
+		SurrogateKeys:        []string{port.SurrogateKeyNewsList},
+	}
+	// Article pages stay out of shared caches, since views served by a CDN
+	// would never reach the view counter. Browsers revalidate every time,
+	// which is counted too, and get a 304 when nothing changed.
+	newsDetailCache = customMiddleware.CachePolicy{Private: true}
+	categoriesCache = customMiddleware.CachePolicy{
+		MaxAge:        5 * time.Minute,
+		SharedMaxAge:  time.Hour,
+		SurrogateKeys: []string{port.SurrogateKeyCategories},
+	}
+	// The spec only changes with a deploy
+	docsCache = customMiddleware.CachePolicy{
+		MaxAge:       5 * time.Minute,
+		SharedMaxAge: time.Hour,
+	}
+)
+
 func NewRouter(cfg RouterConfig) http.Handler {
 	r := chi.NewRouter()
 
 	// Middlewares
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
+	r.Use(customMiddleware.RealIP(cfg.TrustedProxies))
+	r.Use(telemetry.Middleware)
+	r.Use(metrics.Middleware)
+	r.Use(logging.Middleware(cfg.AccessLog))
 	r.Use(middleware.Recoverer)
+	if cfg.ValidateResponses {
+		r.Use(openapi.ValidateResponses)
+	}
+
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		problem.New(w, r, http.StatusNotFound, domain.CodeNotFound, "No route matches "+r.URL.Path)
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		problem.New(w, r, http.StatusMethodNotAllowed, domain.CodeMethodNotAllowed, r.Method+" is not allowed here")
+	})
+
+	// Probes and scrapes are never rate limited
+	r.With(customMiddleware.NoStore).Get("/healthz", cfg.HealthHandler.Live)
+	r.With(customMiddleware.NoStore).Get("/readyz", cfg.HealthHandler.Ready)
+	if cfg.MetricsHandler != nil {
+		r.Method(http.MethodGet, "/metrics", cfg.MetricsHandler)
+	}
+
+	r.Group(func(r chi.Router) {
+		r.Use(cors.Handler(cors.Options{
+			AllowedOrigins:   cfg.AllowedOrigins,
+			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "If-None-Match", "If-Modified-Since"},
+			ExposedHeaders:   []string{"Link", "Content-Disposition", "ETag", "Last-Modified", "RateLimit-Policy", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
+			AllowCredentials: true,
+			MaxAge:           300,
+		}))
+
+		apiRoutes(r, cfg)
+
+		if cfg.LocalStorageHandler != nil {
+			FileServer(r.With(cfg.RateLimiter.Limit(customMiddleware.RateLimitPublic)), "/uploads", http.Dir(cfg.UploadDir))
+		}
+	})
+
+	for _, route := range openapi.UndocumentedRoutes(r) {
+		slog.Warn("Route is missing from the OpenAPI spec", "route", route)
+	}
 
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   cfg.AllowedOrigins,
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300,
-	}))
+	return r
+}
 
-	r.Use(customMiddleware.RateLimitMiddleware(cfg.RPS, cfg.Burst))
+// apiRoutes mounts the versioned API. Anonymous requests are rate limited
+// per client IP; authenticated ones per user, after AuthMiddleware, so
+// editors behind one office IP don't share a bucket. Authenticated routes
+// are also limited per client IP before AuthMiddleware, so requests with a
+// missing or invalid token don't get through unlimited.
+func apiRoutes(r chi.Router, cfg RouterConfig) {
+	publicLimit := cfg.RateLimiter.Limit(customMiddleware.RateLimitPublic)
+	loginLimit := cfg.RateLimiter.Limit(customMiddleware.RateLimitLogin)
+	userLimit := cfg.RateLimiter.Limit(customMiddleware.RateLimitUser)
+	shareLimit := cfg.RateLimiter.Limit(customMiddleware.RateLimitShare)
+	// Without a user in the context the user policy counts per client IP
+	clientLimit := userLimit
 
-	// API Routes
 	r.Route("/api/v1", func(r chi.Router) {
 		r.Route("/auth", func(r chi.Router) {
-			r.Post("/login", cfg.AuthHandler.Login)
+			r.With(loginLimit).Post("/login", cfg.AuthHandler.Login)
 			r.Group(func(r chi.Router) {
-				r.Use(handler.AuthMiddleware(cfg.JWTSecret))
+				r.Use(clientLimit, handler.AuthMiddleware(cfg.JWTSecret), userLimit, customMiddleware.NoStore)
 				r.Get("/me", cfg.AuthHandler.GetMe)
 			})
 		})
 
-		r.Get("/categories", cfg.CategoryHandler.ListCategories)
-		r.Get("/news", cfg.NewsHandler.ListNews)
-		r.Get("/news/homepage", cfg.NewsHandler.GetHomepage)
-		r.Get("/news/check-slug", cfg.NewsHandler.CheckSlug)
-		r.Get("/news/{slug}", cfg.NewsHandler.GetNews)
-		r.Get("/stats", cfg.StatsHandler.GetStats)
+		r.Group(func(r chi.Router) {
+			r.Use(publicLimit)
+
+			r.With(customMiddleware.HTTPCache(docsCache)).Get("/openapi.json", openapi.Spec)
+			r.With(customMiddleware.HTTPCache(docsCache)).Get("/docs", openapi.Docs)
+
+			r.With(customMiddleware.HTTPCache(categoriesCache)).Get("/categories", cfg.CategoryHandler.ListCategories)
+			r.With(customMiddleware.HTTPCache(newsListCache)).Get("/news", cfg.NewsHandler.ListNews)
+			r.With(customMiddleware.HTTPCache(homepageCache)).Get("/news/homepage", cfg.NewsHandler.GetHomepage)
+			r.With(customMiddleware.NoStore).Get("/news/check-slug", cfg.NewsHandler.CheckSlug)
+			r.With(customMiddleware.HTTPCache(newsDetailCache)).Get("/news/{slug}", cfg.NewsHandler.GetNews)
+			r.With(shareLimit).Post("/news/{id}/share", cfg.NewsHandler.RecordShare)
+
+			if cfg.LocalStorageHandler != nil {
+				// Authenticated by the URL signature, not a JWT
+				r.Put("/media/local/{key}", cfg.LocalStorageHandler.SignedUpload)
+			}
+		})
 
 		r.Group(func(r chi.Router) {
-			r.Use(handler.AuthMiddleware(cfg.JWTSecret))
+			r.Use(clientLimit, handler.AuthMiddleware(cfg.JWTSecret), userLimit, customMiddleware.NoStore)
 
 			r.Post("/news", cfg.NewsHandler.CreateNews)
 			r.Put("/news/{id}", cfg.NewsHandler.UpdateNews)
 			r.Delete("/news/{id}", cfg.NewsHandler.DeleteNews)
 
+			r.Post("/media/upload-url", cfg.MediaHandler.CreateUploadURL)
+			r.Post("/media/{id}/complete", cfg.MediaHandler.CompleteUpload)
+
 			r.Post("/categories", cfg.CategoryHandler.CreateCategory)
 			r.Put("/categories/{id}", cfg.CategoryHandler.UpdateCategory)
 			r.Delete("/categories/{id}", cfg.CategoryHandler.DeleteCategory)
 
+			r.Get("/stats", cfg.StatsHandler.GetStats)
+			r.Get("/stats/timeseries", cfg.StatsHandler.GetSiteTimeSeries)
+			r.Get("/stats/news/{id}/timeseries", cfg.StatsHandler.GetNewsTimeSeries)
+			r.Get("/stats/categories/{id}/timeseries", cfg.StatsHandler.GetCategoryTimeSeries)
+			r.Get("/stats/traffic", cfg.StatsHandler.GetSiteTraffic)
+			r.Get("/stats/news/{id}/traffic", cfg.StatsHandler.GetNewsTraffic)
+
+			r.Get("/export/stats", cfg.ExportHandler.ExportStats)
+			r.Get("/export/news", cfg.ExportHandler.ExportNews)
+			r.Get("/export/news/{id}/timeseries", cfg.ExportHandler.ExportNewsTimeSeries)
+
+			r.Get("/reports/schedules", cfg.ReportHandler.ListSchedules)
+			r.Post("/reports/schedules", cfg.ReportHandler.CreateSchedule)
+			r.Put("/reports/schedules/{id}", cfg.ReportHandler.UpdateSchedule)
+			r.Delete("/reports/schedules/{id}", cfg.ReportHandler.DeleteSchedule)
+			r.Get("/reports/schedules/{id}/runs", cfg.ReportHandler.ListRuns)
+			r.Post("/reports/schedules/{id}/send", cfg.ReportHandler.SendNow)
+
 			r.Get("/users", cfg.AuthHandler.ListUsers)
 			r.Post("/users", cfg.AuthHandler.Register)
 			r.Post("/users/change-password", cfg.AuthHandler.ChangePassword)
@@ -79,8 +214,6 @@ func NewRouter(cfg RouterConfig) http.Handler {
 			r.Post("/SEED_news", cfg.SeedHandler.SEED_CreateNews)
 		})
 	})
-
-	return r
 }
 
 // FileServer conveniently sets up a http.FileServer handler at a given path.
@@ -96,6 +229,9 @@ func FileServer(r chi.Router, path string, root http.FileSystem) {
 	path += "*"
 
 	r.Get(path, func(w http.ResponseWriter, r *http.Request) {
+		// Uploads share the API's origin: never let a stored file run script
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; img-src 'self'; media-src 'self'; style-src 'unsafe-inline'; sandbox")
 		rctx := chi.RouteContext(r.Context())
 		pathPrefix := strings.TrimSuffix(rctx.RoutePattern(), "/*")
 		fs := http.StripPrefix(pathPrefix, http.FileServer(root))
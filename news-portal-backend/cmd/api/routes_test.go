@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"news-portal-backend/internal/adapter/handler"
+	customMiddleware "news-portal-backend/internal/adapter/middleware"
+	"news-portal-backend/internal/adapter/openapi"
+	"news-portal-backend/internal/core/domain"
+	"news-portal-backend/internal/core/port"
+	"news-portal-backend/internal/core/service"
+)
+
+const testJWTSecret = "contract-test-secret"
+
+var (
+	testUserID = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	testNewsID = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	testCatID  = uuid.MustParse("33333333-3333-3333-3333-333333333333")
+	testTime   = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+)
+
+// The fakes embed the port interfaces so calling a method a test doesn't
+// set up panics, which the router turns into an undocumented 500.
+
+type fakeAuthService struct{ port.AuthService }
+
+func (fakeAuthService) Login(ctx context.Context, email, password string) (string, error) {
+	if password != "correct horse" {
+		return "", domain.ErrInvalidCredentials
+	}
+	return "token", nil
+}
+
+func (fakeAuthService) GetMe(ctx context.Context, id uuid.UUID) (*domain.Owner, error) {
+	return testOwner(), nil
+}
+
+func (fakeAuthService) ListUsers(ctx context.Context) ([]*domain.Owner, error) {
+	return []*domain.Owner{testOwner()}, nil
+}
+
+type fakeNewsService struct{ port.NewsService }
+
+func (fakeNewsService) GetNewsBySlug(ctx context.Context, slug string, viewer port.Viewer) (*domain.News, error) {
+	if slug != "hello-world" {
+		return nil, nil
+	}
+	return testNews(), nil
+}
+
+func (fakeNewsService) ListNews(ctx context.Context, page, limit int32, categorySlug string, authorID *uuid.UUID, sortBy string, isFeatured *bool, search string) ([]*domain.News, int64, error) {
+	return []*domain.News{testNews()}, 1, nil
+}
+
+func (fakeNewsService) GetHomepageData(ctx context.Context) (*port.HomepageData, error) {
+	return &port.HomepageData{
+		Featured: testNews(),
+		Latest:   []*domain.News{testNews()},
+		Popular:  []*domain.News{},
+	}, nil
+}
+
+func (fakeNewsService) CheckSlug(ctx context.Context, slug string) (bool, error) {
+	return slug == "hello-world", nil
+}
+
+func (fakeNewsService) RecordShare(ctx context.Context, id uuid.UUID, network string, viewer port.Viewer) error {
+	if id != testNewsID {
+		return domain.NewError(domain.ErrNotFound, domain.CodeNotFound, "News not found")
+	}
+	return nil
+}
+
+type fakeCategoryService struct{ port.CategoryService }
+
+func (fakeCategoryService) ListCategories(ctx context.Context) ([]*domain.Category, error) {
+	description := "Local and national news"
+	return []*domain.Category{{
+		ID:          uuid.New(),
+		Name:        "News",
+		Slug:        "news",
+		Description: &description,
+		CreatedAt:   testTime,
+	}}, nil
+}
+
+type fakeReportService struct{ port.ReportService }
+
+func (fakeReportService) ListSchedules(ctx context.Context, ownerID uuid.UUID) ([]*domain.ReportSchedule, error) {
+	return []*domain.ReportSchedule{}, nil
+}
+
+type fakeHealthService struct{}
+
+func (fakeHealthService) Ready(ctx context.Context) *port.HealthReport {
+	return &port.HealthReport{
+		Status: port.HealthOK,
+		Checks: map[string]port.DependencyHealth{"database": {Status: port.HealthOK, DurationMS: 1}},
+	}
+}
+
+func testOwner() *domain.Owner {
+	return &domain.Owner{ID: testUserID, Name: "Editor", Email: "editor@example.com", Role: "admin", CreatedAt: testTime}
+}
+
+func testNews() *domain.News {
+	excerpt := "A short summary"
+	category := "News"
+	return &domain.News{
+		ID:            testNewsID,
+		AuthorID:      testUserID,
+		CategoryID:    testCatID,
+		Title:         "Hello world",
+		Excerpt:       &excerpt,
+		Content:       "<p>Body</p>",
+		Thumbnail:     "https://cdn.example.com/a.webp",
+		ThumbnailInfo: &domain.ImageInfo{Width: 1600, Height: 900},
+		ThumbnailMeta: &domain.ThumbnailMeta{Alt: "A harbour at dawn"},
+		Slug:          "hello-world",
+		Status:        "published",
+		PublishedAt:   testTime,
+		CreatedAt:     testTime,
+		UpdatedAt:     testTime,
+		CategoryName:  &category,
+	}
+}
+
+func newTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	local, err := service.NewLocalStorageService(t.TempDir(), "http://localhost/uploads", "http://localhost/api/v1/media/local", "upload-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	news := fakeNewsService{}
+	stats := service.NewStatsService(nil, nil, nil, nil, nil)
+	return NewRouter(RouterConfig{
+		RateLimiter: customMiddleware.NewRateLimiter(service.NewMemoryRateLimitStore(), customMiddleware.RateLimitConfig{
+			Policies: []customMiddleware.RateLimitPolicy{
+				{Name: customMiddleware.RateLimitPublic, Rate: 100, Burst: 100},
+				{Name: customMiddleware.RateLimitLogin, Rate: 100, Burst: 100},
+				{Name: customMiddleware.RateLimitUser, Rate: 100, Burst: 100},
+				{Name: customMiddleware.RateLimitShare, Rate: 100, Burst: 100},
+			},
+		}),
+		JWTSecret:           testJWTSecret,
+		AuthHandler:         handler.NewAuthHandler(fakeAuthService{}),
+		CategoryHandler:     handler.NewCategoryHandler(fakeCategoryService{}),
+		NewsHandler:         handler.NewNewsHandler(news, nil),
+		StatsHandler:        handler.NewStatsHandler(stats),
+		SeedHandler:         handler.NewSeedHandler(news),
+		MediaHandler:        handler.NewMediaHandler(nil),
+		ExportHandler:       handler.NewExportHandler(news, stats),
+		ReportHandler:       handler.NewReportHandler(fakeReportService{}),
+		HealthHandler:       handler.NewHealthHandler(fakeHealthService{}),
+		LocalStorageHandler: handler.NewLocalStorageHandler(local),
+		UploadDir:           t.TempDir(),
+	})
+}
+
+func testToken(t *testing.T) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": testUserID.String(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// routePattern resolves the chi pattern a request is routed to, which is
+// how the document's paths are keyed
+func routePattern(t *testing.T, router http.Handler, method, target string) string {
+	t.Helper()
+	rctx := chi.NewRouteContext()
+	path := strings.SplitN(target, "?", 2)[0]
+	if !router.(chi.Routes).Match(rctx, method, path) {
+		t.Fatalf("%s %s matches no route", method, path)
+	}
+	return rctx.RoutePattern()
+}
+
+func TestRoutesAreDocumented(t *testing.T) {
+	router := newTestRouter(t)
+	if missing := openapi.UndocumentedRoutes(router.(chi.Routes)); len(missing) > 0 {
+		t.Errorf("routes missing from openapi.json:\n%s", strings.Join(missing, "\n"))
+	}
+}
+
+func TestDocumentedOperationsAreRouted(t *testing.T) {
+	router := newTestRouter(t)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	routed := make(map[string]bool)
+	chi.Walk(router.(chi.Routes), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routed[method+" "+strings.TrimSuffix(route, "/")] = true
+		return nil
+	})
+	for path, item := range doc.Paths {
+		for method := range item {
+			if method == "parameters" {
+				continue
+			}
+			if op := strings.ToUpper(method) + " " + path; !routed[op] {
+				t.Errorf("%s is documented but not routed", op)
+			}
+		}
+	}
+}
+
+func TestResponsesMatchSpec(t *testing.T) {
+	router := newTestRouter(t)
+	token := testToken(t)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		auth   bool
+		status int
+	}{
+		{"liveness", "GET", "/healthz", "", false, http.StatusOK},
+		{"readiness", "GET", "/readyz", "", false, http.StatusOK},
+		{"spec", "GET", "/api/v1/openapi.json", "", false, http.StatusOK},
+		{"docs", "GET", "/api/v1/docs", "", false, http.StatusOK},
+		{"categories", "GET", "/api/v1/categories", "", false, http.StatusOK},
+		{"news list", "GET", "/api/v1/news?page=1&limit=10", "", false, http.StatusOK},
+		{"news list invalid sort", "GET", "/api/v1/news?sort=random", "", false, http.StatusUnprocessableEntity},
+		{"news list malformed page", "GET", "/api/v1/news?page=two", "", false, http.StatusBadRequest},
+		{"news list limit too large", "GET", "/api/v1/news?limit=1000000", "", false, http.StatusUnprocessableEntity},
+		{"news list negative page", "GET", "/api/v1/news?page=-1", "", false, http.StatusUnprocessableEntity},
+		{"homepage", "GET", "/api/v1/news/homepage", "", false, http.StatusOK},
+		{"news detail", "GET", "/api/v1/news/hello-world", "", false, http.StatusOK},
+		{"news detail missing", "GET", "/api/v1/news/missing", "", false, http.StatusNotFound},
+		{"check slug", "GET", "/api/v1/news/check-slug?slug=hello-world", "", false, http.StatusOK},
+		{"check slug missing param", "GET", "/api/v1/news/check-slug", "", false, http.StatusUnprocessableEntity},
+		{"share", "POST", "/api/v1/news/" + testNewsID.String() + "/share", `{"network":"facebook"}`, false, http.StatusNoContent},
+		{"share invalid id", "POST", "/api/v1/news/not-a-uuid/share", `{"network":"facebook"}`, false, http.StatusBadRequest},
+		{"share unknown article", "POST", "/api/v1/news/" + uuid.NewString() + "/share", `{"network":"facebook"}`, false, http.StatusNotFound},
+		{"share malformed body", "POST", "/api/v1/news/" + testNewsID.String() + "/share", `{"network":`, false, http.StatusBadRequest},
+		{"login", "POST", "/api/v1/auth/login", `{"email":"editor@example.com","password":"correct horse"}`, false, http.StatusOK},
+		{"login wrong password", "POST", "/api/v1/auth/login", `{"email":"editor@example.com","password":"wrong"}`, false, http.StatusUnauthorized},
+		{"login malformed", "POST", "/api/v1/auth/login", `{"email":`, false, http.StatusBadRequest},
+		{"login unknown field", "POST", "/api/v1/auth/login", `{"email":"a@b.c","password":"x","admin":true}`, false, http.StatusBadRequest},
+		{"login missing fields", "POST", "/api/v1/auth/login", `{}`, false, http.StatusUnprocessableEntity},
+		{"me", "GET", "/api/v1/auth/me", "", true, http.StatusOK},
+		{"me without token", "GET", "/api/v1/auth/me", "", false, http.StatusUnauthorized},
+		{"users", "GET", "/api/v1/users", "", true, http.StatusOK},
+		{"report schedules", "GET", "/api/v1/reports/schedules", "", true, http.StatusOK},
+		{"create news without token", "POST", "/api/v1/news", "", false, http.StatusUnauthorized},
+		{"delete category invalid id", "DELETE", "/api/v1/categories/not-a-uuid", "", true, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if tt.auth {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body: %s", rec.Code, tt.status, rec.Body)
+			}
+			pattern := routePattern(t, router, tt.method, tt.target)
+			violations := openapi.CheckResponse(tt.method, pattern, rec.Code, rec.Header().Get("Content-Type"), rec.Body.Bytes())
+			for _, v := range violations {
+				t.Errorf("%s %s %d: %s", tt.method, pattern, rec.Code, v)
+			}
+		})
+	}
+}
+
+// Requests without a valid token are limited per client IP before they
+// reach AuthMiddleware
+func TestUnauthenticatedRequestsAreLimited(t *testing.T) {
+	router := newTestRouter(t)
+
+	var rec *httptest.ResponseRecorder
+	for i := 0; i <= 100; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/stats", nil)
+		req.Header.Set("Authorization", "Bearer invalid")
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+	}
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d after the burst, want 429", rec.Code)
+	}
+}
+
+func TestNotModifiedMatchesSpec(t *testing.T) {
+	router := newTestRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/news/hello-world", nil))
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("article response has no ETag")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/news/hello-world", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("status = %d, want 304", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("304 has a body: %q", rec.Body)
+	}
+	if v := openapi.CheckResponse(http.MethodGet, "/api/v1/news/{slug}", rec.Code, rec.Header().Get("Content-Type"), rec.Body.Bytes()); len(v) > 0 {
+		t.Errorf("304 violates the spec: %v", v)
+	}
+}
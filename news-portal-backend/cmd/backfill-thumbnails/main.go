@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"news-portal-backend/internal/adapter/storage"
+	"news-portal-backend/internal/core/service"
+)
+
+// Thumbnails larger than this are skipped rather than buffered in memory
+const maxThumbnailBytes = 25 << 20
+
+func main() {
+	batch := flag.Int("batch", 100, "Articles to process per batch")
+	dryRun := flag.Bool("dry-run", false, "Analyze thumbnails without saving the results")
+	flag.Parse()
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL environment variable is required")
+	}
+
+	ctx := context.Background()
+	dbPool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		log.Fatalf("Unable to connect to database: %v", err)
+	}
+	defer dbPool.Close()
+
+	store := storage.NewAdapter(dbPool)
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	var updated, failed int
+	after := uuid.Nil
+	for {
+		refs, err := store.ListNewsMissingThumbnailInfo(ctx, int32(*batch), after)
+		if err != nil {
+			log.Fatalf("Failed to list articles: %v", err)
+		}
+		if len(refs) == 0 {
+			break
+		}
+
+		for _, ref := range refs {
+			after = ref.ID
+
+			data, err := download(client, ref.Thumbnail)
+			if err != nil {
+				log.Printf("Skipping %s: %v", ref.ID, err)
+				failed++
+				continue
+			}
+
+			info, err := service.AnalyzeImage(bytes.NewReader(data))
+			if err != nil {
+				log.Printf("Skipping %s: %v", ref.ID, err)
+				failed++
+				continue
+			}
+
+			if !*dryRun {
+				if err := store.UpdateNewsThumbnailInfo(ctx, ref.ID, info); err != nil {
+					log.Printf("Failed to save %s: %v", ref.ID, err)
+					failed++
+					continue
+				}
+			}
+			updated++
+		}
+	}
+
+	fmt.Printf("Backfill finished: %d updated, %d failed\n", updated, failed)
+}
+
+func download(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxThumbnailBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxThumbnailBytes {
+		return nil, fmt.Errorf("thumbnail larger than %d bytes", maxThumbnailBytes)
+	}
+	return data, nil
+}
@@ -0,0 +1,49 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+type csvWriter struct {
+	w      io.Writer
+	csv    *csv.Writer
+	sheets int
+	row    []string
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: w, csv: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) Sheet(name string, header []string) error {
+	if c.sheets == 0 {
+		// Excel only reads CSV as UTF-8, and so shows Bangla correctly,
+		// when the file starts with a byte order mark
+		if _, err := io.WriteString(c.w, "\uFEFF"); err != nil {
+			return err
+		}
+	} else {
+		if err := c.csv.Write(nil); err != nil {
+			return err
+		}
+		if err := c.csv.Write([]string{name}); err != nil {
+			return err
+		}
+	}
+	c.sheets++
+	return c.csv.Write(header)
+}
+
+func (c *csvWriter) Row(values ...any) error {
+	c.row = c.row[:0]
+	for _, v := range values {
+		c.row = append(c.row, formatValue(v))
+	}
+	return c.csv.Write(c.row)
+}
+
+func (c *csvWriter) Close() error {
+	c.csv.Flush()
+	return c.csv.Error()
+}
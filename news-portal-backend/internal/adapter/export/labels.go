@@ -0,0 +1,88 @@
+package export
+
+import "strings"
+
+// Export languages
+const (
+	LangEnglish = "en"
+	LangBangla  = "bn"
+)
+
+// labels holds the English and Bangla text of every column and sheet name
+var labels = map[string][2]string{
+	// Sheets
+	"summary":    {"Summary", "সারসংক্ষেপ"},
+	"categories": {"Categories", "বিভাগ"},
+	"top_news":   {"Top news", "শীর্ষ সংবাদ"},
+	"authors":    {"Authors", "লেখক"},
+	"referrers":  {"Referrers", "রেফারার"},
+	"articles":   {"Articles", "সংবাদ"},
+	"views":      {"Views", "ভিউ"},
+
+	// Columns
+	"metric":             {"Metric", "সূচক"},
+	"value":              {"Value", "মান"},
+	"current_period":     {"Current period", "বর্তমান সময়কাল"},
+	"previous_period":    {"Previous period", "পূর্ববর্তী সময়কাল"},
+	"change":             {"Change", "পরিবর্তন"},
+	"change_percent":     {"Change (%)", "পরিবর্তন (%)"},
+	"id":                 {"ID", "আইডি"},
+	"name":               {"Name", "নাম"},
+	"title":              {"Title", "শিরোনাম"},
+	"slug":               {"Slug", "স্লাগ"},
+	"category":           {"Category", "বিভাগ"},
+	"author":             {"Author", "লেখক"},
+	"featured":           {"Featured", "ফিচার্ড"},
+	"published_at":       {"Published at", "প্রকাশের সময়"},
+	"updated_at":         {"Updated at", "হালনাগাদের সময়"},
+	"time":               {"Time", "সময়"},
+	"article_views":      {"Views", "ভিউ"},
+	"raw_views":          {"Raw views", "মোট হিট"},
+	"articles_published": {"Articles published", "প্রকাশিত সংবাদ"},
+	"avg_views":          {"Average views per article", "সংবাদপ্রতি গড় ভিউ"},
+	"count":              {"Count", "সংখ্যা"},
+	"referrer":           {"Referrer", "রেফারার"},
+
+	// Summary metrics
+	"period_from":      {"From", "শুরু"},
+	"period_to":        {"To", "শেষ"},
+	"total_news":       {"Total news", "মোট সংবাদ"},
+	"total_categories": {"Total categories", "মোট বিভাগ"},
+	"total_users":      {"Total users", "মোট ব্যবহারকারী"},
+	"total_views":      {"Total views", "মোট ভিউ"},
+	"total_raw_views":  {"Total raw views", "মোট হিট"},
+}
+
+// Label returns the text for a key in the language, falling back to the key
+// itself for unknown keys
+func Label(lang, key string) string {
+	l, ok := labels[key]
+	if !ok {
+		return key
+	}
+	if lang == LangBangla {
+		return l[1]
+	}
+	return l[0]
+}
+
+// Header returns the labels for a row of column keys
+func Header(lang string, keys ...string) []string {
+	header := make([]string, len(keys))
+	for i, key := range keys {
+		header[i] = Label(lang, key)
+	}
+	return header
+}
+
+// ParseLang picks the export language from an explicit choice, then from an
+// Accept-Language header. English is the default.
+func ParseLang(lang, acceptLanguage string) string {
+	if lang == "" {
+		lang = acceptLanguage
+	}
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(lang)), LangBangla) {
+		return LangBangla
+	}
+	return LangEnglish
+}
@@ -0,0 +1,110 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Writer streams tabular data to a spreadsheet. Rows are written as they
+// arrive, so exports of any size use constant memory.
+type Writer interface {
+	// Sheet starts a new table with the given header row. XLSX output gets
+	// one worksheet per table; CSV output separates them with a blank line
+	// and the table name.
+	Sheet(name string, header []string) error
+	// Row writes one row. Values may be strings, integers, floats, bools,
+	// time.Time, pointers to strings, floats or times, or nil.
+	Row(values ...any) error
+	Close() error
+}
+
+// NewWriter returns a writer for the format, or an error if the format is
+// not supported
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case "", FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w), nil
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// ContentType returns the MIME type of a format
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Extension returns the file extension of a format, without the dot
+func Extension(format string) string {
+	if format == FormatXLSX {
+		return FormatXLSX
+	}
+	return FormatCSV
+}
+
+// formatValue renders a cell as text. Times are written in RFC 3339 so they
+// sort correctly and keep their zone. Text is passed through escapeFormula.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return escapeFormula(v)
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return escapeFormula(*v)
+	case int:
+		return strconv.Itoa(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case *float64:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return formatValue(*v)
+	case fmt.Stringer:
+		return escapeFormula(v.String())
+	}
+	return escapeFormula(fmt.Sprint(v))
+}
+
+// escapeFormula keeps text that a spreadsheet would evaluate as a formula,
+// such as an article titled "=HYPERLINK(...)", from running when the export
+// is opened, by prefixing it with an apostrophe
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,87 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"=HYPERLINK(\"http://evil\")", "'=HYPERLINK(\"http://evil\")"},
+		{"+1+1", "'+1+1"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"Election results", "Election results"},
+		{"নির্বাচন", "নির্বাচন"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := escapeFormula(tt.in); got != tt.want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCSVEscapesTextButNotNumbers(t *testing.T) {
+	var buf bytes.Buffer
+	w := newCSVWriter(&buf)
+	if err := w.Sheet("Articles", []string{"title", "change"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Row("=cmd|' /C calc'!A0", int64(-5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), "\uFEFF"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := records[1]; got[0] != "'=cmd|' /C calc'!A0" || got[1] != "-5" {
+		t.Errorf("row = %q, want the title escaped and the number untouched", got)
+	}
+}
+
+func TestXLSXEscapesText(t *testing.T) {
+	var buf bytes.Buffer
+	w := newXLSXWriter(&buf)
+	if err := w.Sheet("Articles", []string{"title"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Row("@SUM(A1)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(readXLSXSheet(t, buf.Bytes()), "&#39;@SUM(A1)") {
+		t.Error("formula text was not escaped")
+	}
+}
+
+func readXLSXSheet(t *testing.T, b []byte) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := zr.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	sheet, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(sheet)
+}
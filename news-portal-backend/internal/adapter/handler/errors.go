@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"news-portal-backend/internal/core/domain"
+)
+
+// errUnauthorized is returned when the request carries no valid user
+var errUnauthorized = domain.NewError(domain.ErrUnauthorized, domain.CodeUnauthorized, "unauthorized")
+
+// badRequest reports a request body or form the handler could not parse
+func badRequest(message string) error {
+	return domain.NewError(domain.ErrBadRequest, domain.CodeBadRequest, message)
+}
+
+// invalidField reports a malformed path, query or form parameter
+func invalidField(field, message string) error {
+	return &domain.Error{
+		Kind:    domain.ErrBadRequest,
+		Code:    domain.CodeBadRequest,
+		Message: message,
+		Fields:  []domain.FieldError{{Field: field, Code: domain.FieldInvalid, Message: message}},
+	}
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"news-portal-backend/internal/adapter/export"
+	"news-portal-backend/internal/adapter/problem"
+	"news-portal-backend/internal/core/domain"
+	"news-portal-backend/internal/core/port"
+)
+
+// Export Handler
+
+// ExportHandler streams analytics and article lists as CSV or XLSX. Every
+// endpoint takes ?format=csv|xlsx and ?lang=en|bn (falling back to
+// Accept-Language) for the column headers.
+type ExportHandler struct {
+	news  port.NewsService
+	stats port.StatsService
+}
+
+func NewExportHandler(news port.NewsService, stats port.StatsService) *ExportHandler {
+	return &ExportHandler{news: news, stats: stats}
+}
+
+// ExportStats exports the dashboard stats, one sheet per section
+func (h *ExportHandler) ExportStats(w http.ResponseWriter, r *http.Request) {
+	filter, err := statsFilterFromRequest(r)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	stats, err := h.stats.GetDashboardStats(r.Context(), filter)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	h.write(w, r, "stats", func(ew export.Writer, lang string) error {
+		if err := ew.Sheet(export.Label(lang, "summary"), export.Header(lang, "metric", "value")); err != nil {
+			return err
+		}
+		summary := []struct {
+			key   string
+			value any
+		}{
+			{"period_from", stats.Period.From},
+			{"period_to", stats.Period.To},
+			{"total_news", stats.TotalNews},
+			{"total_categories", stats.TotalCategories},
+			{"total_users", stats.TotalUsers},
+			{"total_views", stats.TotalViews},
+			{"total_raw_views", stats.TotalRawViews},
+		}
+		for _, m := range summary {
+			if err := ew.Row(export.Label(lang, m.key), m.value); err != nil {
+				return err
+			}
+		}
+
+		if err := ew.Sheet(export.Label(lang, "views"), export.Header(lang, "metric", "current_period", "previous_period", "change", "change_percent")); err != nil {
+			return err
+		}
+		periods := []struct {
+			key               string
+			current, previous int64
+			delta             port.StatDelta
+		}{
+			{"articles_published", stats.Period.ArticlesPublished, stats.PreviousPeriod.ArticlesPublished, stats.Deltas.ArticlesPublished},
+			{"article_views", stats.Period.Views, stats.PreviousPeriod.Views, stats.Deltas.Views},
+			{"raw_views", stats.Period.RawViews, stats.PreviousPeriod.RawViews, stats.Deltas.RawViews},
+		}
+		for _, p := range periods {
+			if err := ew.Row(export.Label(lang, p.key), p.current, p.previous, p.delta.Change, p.delta.Percent); err != nil {
+				return err
+			}
+		}
+
+		if err := ew.Sheet(export.Label(lang, "categories"), export.Header(lang, "category", "article_views")); err != nil {
+			return err
+		}
+		for _, c := range stats.CategoryStats {
+			if err := ew.Row(c.Name, c.Value); err != nil {
+				return err
+			}
+		}
+
+		if err := ew.Sheet(export.Label(lang, "top_news"), export.Header(lang, "id", "title", "article_views")); err != nil {
+			return err
+		}
+		for _, n := range stats.TopNews {
+			if err := ew.Row(n.ID, n.Title, n.Views); err != nil {
+				return err
+			}
+		}
+
+		if err := ew.Sheet(export.Label(lang, "authors"), export.Header(lang, "id", "name", "articles_published", "article_views", "avg_views")); err != nil {
+			return err
+		}
+		for _, a := range stats.Authors {
+			if err := ew.Row(a.ID, a.Name, a.ArticlesPublished, a.Views, a.AvgViewsPerArticle); err != nil {
+				return err
+			}
+		}
+
+		if err := ew.Sheet(export.Label(lang, "referrers"), export.Header(lang, "referrer", "count")); err != nil {
+			return err
+		}
+		for _, ref := range stats.TopReferrers {
+			if err := ew.Row(ref.Value, ref.Count); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ExportNewsTimeSeries exports an article's views over time
+func (h *ExportHandler) ExportNewsTimeSeries(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, r, invalidField("id", "Invalid ID"))
+		return
+	}
+
+	filter := port.TimeSeriesFilter{NewsID: &id}
+	if err := timeSeriesRangeFromRequest(r, &filter); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	series, err := h.stats.GetViewTimeSeries(r.Context(), filter)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	h.write(w, r, "views-"+id.String(), func(ew export.Writer, lang string) error {
+		if err := ew.Sheet(export.Label(lang, "views"), export.Header(lang, "time", "article_views", "raw_views")); err != nil {
+			return err
+		}
+		for _, p := range series.Points {
+			if err := ew.Row(p.Bucket, p.Views, p.RawViews); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ExportNews exports every article matching the ListNews filters, streamed
+// from the database
+func (h *ExportHandler) ExportNews(w http.ResponseWriter, r *http.Request) {
+	q, err := newsQueryFromRequest(r)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	h.write(w, r, "news", func(ew export.Writer, lang string) error {
+		header := export.Header(lang, "id", "title", "slug", "category", "author", "featured", "published_at", "updated_at", "article_views", "raw_views")
+		if err := ew.Sheet(export.Label(lang, "articles"), header); err != nil {
+			return err
+		}
+		return h.news.ExportNews(r.Context(), q.category, q.authorID, q.sort, q.isFeatured, q.search, func(n *domain.News) error {
+			return ew.Row(n.ID.String(), n.Title, n.Slug, n.CategoryName, n.AuthorName, n.IsFeatured, n.PublishedAt, n.UpdatedAt, n.ViewsCount, n.RawViewsCount)
+		})
+	})
+}
+
+// write runs fill against a writer for the requested format. Output is
+// buffered and the download headers are only sent with the first flush, so
+// an error before then, such as a failed query, still gets a problem
+// response. Once bytes are sent the status can't change, so a failure
+// midway is logged and the download is cut short.
+func (h *ExportHandler) write(w http.ResponseWriter, r *http.Request, name string, fill func(export.Writer, string) error) {
+	format := r.URL.Query().Get("format")
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format(time.DateOnly), export.Extension(format))
+	dw := &downloadWriter{w: w, contentType: export.ContentType(format), filename: filename}
+	out := bufio.NewWriterSize(dw, exportBufferSize)
+	ew, err := export.NewWriter(format, out)
+	if err != nil {
+		problem.Write(w, r, invalidField("format", "Invalid format"))
+		return
+	}
+	lang := export.ParseLang(r.URL.Query().Get("lang"), r.Header.Get("Accept-Language"))
+
+	err = fill(ew, lang)
+	if err == nil {
+		err = ew.Close()
+	}
+	if err == nil {
+		err = out.Flush()
+	}
+	if err == nil {
+		return
+	}
+	if !dw.started {
+		problem.Write(w, r, err)
+		return
+	}
+	slog.Error("Export failed", "export", name, "error", err)
+	panic(http.ErrAbortHandler)
+}
+
+// exportBufferSize is how much of an export is held back before the
+// download starts
+const exportBufferSize = 32 << 10
+
+// downloadWriter sets the download headers on the first write
+type downloadWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (d *downloadWriter) Write(p []byte) (int, error) {
+	if !d.started {
+		d.started = true
+		d.w.Header().Set("Content-Type", d.contentType)
+		d.w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, d.filename))
+		d.w.Header().Set("Cache-Control", "no-store")
+	}
+	return d.w.Write(p)
+}
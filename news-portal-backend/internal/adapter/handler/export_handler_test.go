@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"news-portal-backend/internal/adapter/problem"
+	"news-portal-backend/internal/core/domain"
+	"news-portal-backend/internal/core/port"
+)
+
+// exportNews streams news to ExportNews callbacks, then returns err
+type exportNews struct {
+	port.NewsService
+	news []*domain.News
+	err  error
+}
+
+func (s *exportNews) ExportNews(ctx context.Context, categorySlug string, authorID *uuid.UUID, sortBy string, isFeatured *bool, search string, fn func(*domain.News) error) error {
+	for _, n := range s.news {
+		if err := fn(n); err != nil {
+			return err
+		}
+	}
+	return s.err
+}
+
+func TestExportNewsReportsEarlyFailure(t *testing.T) {
+	for _, format := range []string{"csv", "xlsx"} {
+		t.Run(format, func(t *testing.T) {
+			h := NewExportHandler(&exportNews{err: errors.New("connection reset")}, nil)
+			rec := httptest.NewRecorder()
+			h.ExportNews(rec, httptest.NewRequest(http.MethodGet, "/admin/export/news?format="+format, nil))
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+			}
+			if got := rec.Header().Get("Content-Type"); got != problem.ContentType {
+				t.Errorf("Content-Type = %q, want %q", got, problem.ContentType)
+			}
+			if got := rec.Header().Get("Content-Disposition"); got != "" {
+				t.Errorf("failed export was sent as a download: %q", got)
+			}
+		})
+	}
+}
+
+func TestExportNews(t *testing.T) {
+	news := &domain.News{ID: uuid.New(), Title: "=HYPERLINK(\"http://example.com\")", Slug: "a"}
+	h := NewExportHandler(&exportNews{news: []*domain.News{news}}, nil)
+	rec := httptest.NewRecorder()
+	h.ExportNews(rec, httptest.NewRequest(http.MethodGet, "/admin/export/news?format=csv", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(got, `attachment; filename="news-`) {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	if !strings.Contains(rec.Body.String(), news.ID.String()) || !strings.Contains(rec.Body.String(), `'=HYPERLINK`) {
+		t.Errorf("body is missing the escaped article: %q", rec.Body.String())
+	}
+}
@@ -3,13 +3,20 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"io"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
+	"news-portal-backend/internal/adapter/metrics"
+	customMiddleware "news-portal-backend/internal/adapter/middleware"
+	"news-portal-backend/internal/adapter/problem"
+	"news-portal-backend/internal/adapter/validate"
 	"news-portal-backend/internal/core/domain"
 	"news-portal-backend/internal/core/port"
 	"news-portal-backend/internal/core/service"
@@ -25,19 +32,28 @@ func NewAuthHandler(svc port.AuthService) *AuthHandler {
 
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Email    string `json:"email" validate:"required,max=255,email"`
+		Password string `json:"password" validate:"required,max=72"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		problem.Write(w, r, err)
 		return
 	}
 
 	token, err := h.svc.Login(r.Context(), req.Email, req.Password)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		switch {
+		case errors.Is(err, domain.ErrUnauthorized):
+			metrics.Login(metrics.LoginFailure)
+		case errors.Is(err, domain.ErrRateLimited):
+			metrics.Login(metrics.LoginLimited)
+		default:
+			metrics.Login(metrics.LoginError)
+		}
+		problem.Write(w, r, err)
 		return
 	}
+	metrics.Login(metrics.LoginSuccess)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"token": token})
@@ -45,23 +61,18 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Name     string `json:"name"`
-		Email    string `json:"email"`
-		Password string `json:"password"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+		Name     string `json:"name" validate:"required,max=255"`
+		Email    string `json:"email" validate:"required,max=255,email"`
+		Password string `json:"password" validate:"required,password"`
 	}
-
-	if req.Email == "" || req.Password == "" || req.Name == "" {
-		http.Error(w, "Name, Email and Password are required", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		problem.Write(w, r, err)
 		return
 	}
 
 	user, err := h.svc.Register(r.Context(), req.Name, req.Email, req.Password)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -71,38 +82,29 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		OldPassword string `json:"old_password"`
-		NewPassword string `json:"new_password"`
+		OldPassword string `json:"old_password" validate:"required,max=72"`
+		NewPassword string `json:"new_password" validate:"required,password"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	if req.OldPassword == "" || req.NewPassword == "" {
-		http.Error(w, "Old and new passwords are required", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		problem.Write(w, r, err)
 		return
 	}
 
 	// Get user_id from context (set by AuthMiddleware)
 	userIDStr, ok := r.Context().Value("user_id").(string)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		problem.Write(w, r, errUnauthorized)
 		return
 	}
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusUnauthorized)
+		problem.Write(w, r, errUnauthorized)
 		return
 	}
 
 	err = h.svc.ChangePassword(r.Context(), userID, req.OldPassword, req.NewPassword)
 	if err != nil {
-		if err.Error() == "invalid old password" {
-			http.Error(w, "Invalid old password", http.StatusUnauthorized)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -113,7 +115,7 @@ func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 	users, err := h.svc.ListUsers(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -124,19 +126,19 @@ func (h *AuthHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 	userIDStr, ok := r.Context().Value("user_id").(string)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		problem.Write(w, r, errUnauthorized)
 		return
 	}
 
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusInternalServerError)
+		problem.Write(w, r, errUnauthorized)
 		return
 	}
 
 	user, err := h.svc.GetMe(r.Context(), userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -147,74 +149,64 @@ func (h *AuthHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 // News Handler
 
 type NewsHandler struct {
-	svc     port.NewsService
-	fileSvc port.FileService
+	svc      port.NewsService
+	mediaSvc port.MediaService
 }
 
-func NewNewsHandler(svc port.NewsService, fileSvc port.FileService) *NewsHandler {
-	return &NewsHandler{svc: svc, fileSvc: fileSvc}
+func NewNewsHandler(svc port.NewsService, mediaSvc port.MediaService) *NewsHandler {
+	return &NewsHandler{svc: svc, mediaSvc: mediaSvc}
 }
 
 func (h *NewsHandler) CreateNews(w http.ResponseWriter, r *http.Request) {
 	// Parse multipart form
 	if err := r.ParseMultipartForm(10 << 20); err != nil { // 10 MB
-		http.Error(w, "Failed to parse form", http.StatusBadRequest)
-		return
-	}
-
-	title := r.FormValue("title")
-	categoryIDStr := r.FormValue("category_id")
-	excerpt := r.FormValue("excerpt")
-	content := r.FormValue("content")
-	isFeatured := r.FormValue("is_featured") == "true"
-
-	if title == "" || content == "" {
-		http.Error(w, "Title and Content are required", http.StatusBadRequest)
+		problem.Write(w, r, badRequest("Failed to parse form"))
 		return
 	}
 
-	categoryID, err := uuid.Parse(categoryIDStr)
-	if err != nil {
-		http.Error(w, "Invalid Category ID", http.StatusBadRequest)
+	form := newsFormFromRequest(r)
+	if err := validate.Struct(form); err != nil {
+		problem.Write(w, r, err)
 		return
 	}
+	categoryID := uuid.MustParse(form.CategoryID)
 
 	// Get author_id from context
 	userIDStr, ok := r.Context().Value("user_id").(string)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		problem.Write(w, r, errUnauthorized)
 		return
 	}
 	authorID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		http.Error(w, "Invalid author ID", http.StatusUnauthorized)
+		problem.Write(w, r, errUnauthorized)
 		return
 	}
 
 	// Handle Image Upload if present
 	var thumbnail string
+	var thumbnailInfo *domain.ImageInfo
 	file, header, err := r.FormFile("thumbnail")
 	if err == nil {
 		defer file.Close()
-		// Validate file type
-		contentType := header.Header.Get("Content-Type")
-		if !strings.HasPrefix(contentType, "image/") {
-			http.Error(w, "Only image files are allowed", http.StatusBadRequest)
-			return
-		}
 
-		thumbnail, err = h.fileSvc.UploadFile(file, header)
+		start := time.Now()
+		uploaded, err := h.mediaSvc.UploadFile(r.Context(), authorID, file, header, "image/")
 		if err != nil {
-			http.Error(w, "Failed to upload image: "+err.Error(), http.StatusInternalServerError)
+			problem.Write(w, r, err)
 			return
 		}
+		metrics.UploadSize(metrics.UploadForm, header.Size)
+		metrics.UploadDuration(metrics.UploadForm, time.Since(start))
+		thumbnail, thumbnailInfo = uploaded.URL, uploaded.Image
 	}
 
-	news, err := h.svc.CreateNews(r.Context(), authorID, categoryID, title, excerpt, content, thumbnail, isFeatured)
+	news, err := h.svc.CreateNews(r.Context(), authorID, categoryID, form.Title, form.Excerpt, form.Content, thumbnail, thumbnailInfo, form.thumbnailMeta(), form.IsFeatured, form.AllowMissingAlt)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
+	metrics.ArticlePublished()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -227,57 +219,55 @@ func (h *NewsHandler) UpdateNews(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		problem.Write(w, r, invalidField("id", "Invalid ID"))
 		return
 	}
 
 	if err := r.ParseMultipartForm(10 << 20); err != nil {
-		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		problem.Write(w, r, badRequest("Failed to parse form"))
 		return
 	}
 
-	title := r.FormValue("title")
-	categoryIDStr := r.FormValue("category_id")
-	excerpt := r.FormValue("excerpt")
-	content := r.FormValue("content")
-	isFeatured := r.FormValue("is_featured") == "true"
+	form := newsFormFromRequest(r)
+	if err := validate.Struct(form); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	categoryID := uuid.MustParse(form.CategoryID)
 	existingThumbnail := r.FormValue("thumbnail") // Keep existing if no new one
 
-	if title == "" || content == "" {
-		http.Error(w, "Title and Content are required", http.StatusBadRequest)
+	// Uploads are attributed to the editor making the change
+	userIDStr, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		problem.Write(w, r, errUnauthorized)
 		return
 	}
-
-	categoryID, err := uuid.Parse(categoryIDStr)
+	editorID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		http.Error(w, "Invalid Category ID", http.StatusBadRequest)
+		problem.Write(w, r, errUnauthorized)
 		return
 	}
 
 	// Handle Image Upload if present
 	thumbnail := existingThumbnail
+	var thumbnailInfo *domain.ImageInfo
 	file, header, err := r.FormFile("thumbnail")
 	if err == nil {
 		defer file.Close()
-		contentType := header.Header.Get("Content-Type")
-		if !strings.HasPrefix(contentType, "image/") {
-			http.Error(w, "Only image files are allowed", http.StatusBadRequest)
-			return
-		}
 
-		thumbnail, err = h.fileSvc.UploadFile(file, header)
+		start := time.Now()
+		uploaded, err := h.mediaSvc.UploadFile(r.Context(), editorID, file, header, "image/")
 		if err != nil {
-			http.Error(w, "Failed to upload image: "+err.Error(), http.StatusInternalServerError)
+			problem.Write(w, r, err)
 			return
 		}
+		metrics.UploadSize(metrics.UploadForm, header.Size)
+		metrics.UploadDuration(metrics.UploadForm, time.Since(start))
+		thumbnail, thumbnailInfo = uploaded.URL, uploaded.Image
 	}
 
-	if err := h.svc.UpdateNews(r.Context(), id, categoryID, title, excerpt, content, thumbnail, isFeatured); err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			http.Error(w, "News not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.svc.UpdateNews(r.Context(), id, categoryID, form.Title, form.Excerpt, form.Content, thumbnail, thumbnailInfo, form.thumbnailMeta(), form.IsFeatured, form.AllowMissingAlt); err != nil {
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -285,20 +275,54 @@ func (h *NewsHandler) UpdateNews(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"message": "News updated"})
 }
 
+// newsForm holds the multipart fields of CreateNews and UpdateNews
+type newsForm struct {
+	Title              string `form:"title" validate:"required,max=255"`
+	CategoryID         string `form:"category_id" validate:"required,uuid"`
+	Excerpt            string `form:"excerpt" validate:"max=1000"`
+	Content            string `form:"content" validate:"required,max=1000000"`
+	IsFeatured         bool   `form:"is_featured"`
+	AllowMissingAlt    bool   `form:"override_alt_check"`
+	ThumbnailAlt       string `form:"thumbnail_alt" validate:"max=500"`
+	ThumbnailCaption   string `form:"thumbnail_caption" validate:"max=1000"`
+	ThumbnailCredit    string `form:"thumbnail_credit" validate:"max=255"`
+	ThumbnailSourceURL string `form:"thumbnail_source_url" validate:"max=2000,url"`
+}
+
+func newsFormFromRequest(r *http.Request) newsForm {
+	return newsForm{
+		Title:              r.FormValue("title"),
+		CategoryID:         r.FormValue("category_id"),
+		Excerpt:            r.FormValue("excerpt"),
+		Content:            r.FormValue("content"),
+		IsFeatured:         r.FormValue("is_featured") == "true",
+		AllowMissingAlt:    r.FormValue("override_alt_check") == "true",
+		ThumbnailAlt:       r.FormValue("thumbnail_alt"),
+		ThumbnailCaption:   r.FormValue("thumbnail_caption"),
+		ThumbnailCredit:    r.FormValue("thumbnail_credit"),
+		ThumbnailSourceURL: strings.TrimSpace(r.FormValue("thumbnail_source_url")),
+	}
+}
+
+func (f newsForm) thumbnailMeta() domain.ThumbnailMeta {
+	return domain.ThumbnailMeta{
+		Alt:       f.ThumbnailAlt,
+		Caption:   f.ThumbnailCaption,
+		Credit:    f.ThumbnailCredit,
+		SourceURL: f.ThumbnailSourceURL,
+	}
+}
+
 func (h *NewsHandler) DeleteNews(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		problem.Write(w, r, invalidField("id", "Invalid ID"))
 		return
 	}
 
 	if err := h.svc.DeleteNews(r.Context(), id); err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			http.Error(w, "News not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -307,81 +331,251 @@ func (h *NewsHandler) DeleteNews(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *NewsHandler) ListNews(w http.ResponseWriter, r *http.Request) {
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	category := r.URL.Query().Get("category")
-	sort := r.URL.Query().Get("sort")
-	featuredStr := r.URL.Query().Get("featured")
-
-	var isFeatured *bool
-	if featuredStr != "" {
-		b, err := strconv.ParseBool(featuredStr)
-		if err == nil {
-			isFeatured = &b
-		}
+	paging, err := pageParamsFromRequest(r)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
 	}
-
-	search := r.URL.Query().Get("search")
-	authorIDStr := r.URL.Query().Get("author_id")
-
-	var authorID *uuid.UUID
-	if authorIDStr != "" {
-		id, err := uuid.Parse(authorIDStr)
-		if err == nil {
-			authorID = &id
-		}
+	q, err := newsQueryFromRequest(r)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
 	}
 
-	newsList, total, err := h.svc.ListNews(r.Context(), int32(page), int32(limit), category, authorID, sort, isFeatured, search)
+	newsList, total, err := h.svc.ListNews(r.Context(), int32(paging.Page), int32(paging.Limit), q.category, q.authorID, q.sort, q.isFeatured, q.search)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
 
+	// No Last-Modified on lists: a deleted or reordered article changes the
+	// page without raising any updated_at, so only the ETag is reliable
+	for _, n := range newsList {
+		customMiddleware.AddSurrogateKeys(w, port.SurrogateKeyForNews(n.ID))
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"newsList": newsList,
+		"newsList": publicNewsList(newsList),
 		"total":    total,
 	})
 }
 
+// pageParams are the paging query parameters of the article list. Zero
+// leaves the default to the service. Every page and limit gets its own
+// response cache entry, so out-of-range values are rejected, not clamped.
+type pageParams struct {
+	Page  int `query:"page" validate:"min=1"`
+	Limit int `query:"limit" validate:"min=1,max=100"`
+}
+
+func pageParamsFromRequest(r *http.Request) (pageParams, error) {
+	var params pageParams
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"page", &params.Page}, {"limit", &params.Limit}} {
+		raw := r.URL.Query().Get(p.name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return pageParams{}, invalidField(p.name, "Invalid "+p.name)
+		}
+		*p.dst = n
+	}
+	if err := validate.Struct(params); err != nil {
+		return pageParams{}, err
+	}
+	return params, nil
+}
+
+// newsQuery holds the article list filters shared by ListNews and its export
+type newsQuery struct {
+	category   string
+	sort       string
+	isFeatured *bool
+	search     string
+	authorID   *uuid.UUID
+}
+
+// newsQueryParams are the raw query parameters behind a newsQuery
+type newsQueryParams struct {
+	Category string `query:"category" validate:"max=100"`
+	Sort     string `query:"sort" validate:"oneof=latest oldest popular trending views_desc views_asc"`
+	Featured string `query:"featured" validate:"oneof=true false"`
+	Search   string `query:"search" validate:"max=255"`
+	AuthorID string `query:"author_id" validate:"uuid"`
+}
+
+func newsQueryFromRequest(r *http.Request) (newsQuery, error) {
+	params := newsQueryParams{
+		Category: r.URL.Query().Get("category"),
+		Sort:     r.URL.Query().Get("sort"),
+		Featured: r.URL.Query().Get("featured"),
+		Search:   r.URL.Query().Get("search"),
+		AuthorID: r.URL.Query().Get("author_id"),
+	}
+	if err := validate.Struct(params); err != nil {
+		return newsQuery{}, err
+	}
+
+	q := newsQuery{
+		category: params.Category,
+		sort:     params.Sort,
+		search:   params.Search,
+	}
+	if params.Featured != "" {
+		featured := params.Featured == "true"
+		q.isFeatured = &featured
+	}
+	if params.AuthorID != "" {
+		id := uuid.MustParse(params.AuthorID)
+		q.authorID = &id
+	}
+	return q, nil
+}
+
 func (h *NewsHandler) GetNews(w http.ResponseWriter, r *http.Request) {
 	slug := chi.URLParam(r, "slug")
-	news, err := h.svc.GetNewsBySlug(r.Context(), slug)
+	news, err := h.svc.GetNewsBySlug(r.Context(), slug, viewerFromRequest(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
 	if news == nil {
-		http.Error(w, "Not found", http.StatusNotFound)
+		problem.Write(w, r, domain.NewError(domain.ErrNotFound, domain.CodeNotFound, "News not found"))
 		return
 	}
 
+	customMiddleware.SetLastModified(w, news.UpdatedAt)
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(news)
+	json.NewEncoder(w).Encode(publicNews{News: news})
+}
+
+// publicNews is an article as served by the public endpoints. The raw view
+// counter is shadowed and left out; it is only reported to editors.
+type publicNews struct {
+	*domain.News
+	RawViewsCount *int64 `json:"raw_views_count,omitempty"`
+}
+
+func publicNewsList(news []*domain.News) []publicNews {
+	list := make([]publicNews, len(news))
+	for i, n := range news {
+		list[i] = publicNews{News: n}
+	}
+	return list
+}
+
+// viewerFromRequest collects what the view counter needs to tell unique
+// human visits apart from refreshes, bots and prefetches. RemoteAddr is the
+// TCP peer unless a trusted proxy forwarded the client (see RealIP), so a
+// visitor can't defeat deduplication by sending a new X-Forwarded-For.
+func viewerFromRequest(r *http.Request) port.Viewer {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	// The frontend forwards the page's document.referrer and UTM parameters,
+	// since the API request's own Referer is the frontend itself
+	q := r.URL.Query()
+	referrer := q.Get("ref")
+	if referrer == "" {
+		referrer = r.Referer()
+	}
+
+	purpose := strings.ToLower(r.Header.Get("Sec-Purpose") + r.Header.Get("Purpose") + r.Header.Get("X-Purpose") + r.Header.Get("X-Moz"))
+	return port.Viewer{
+		IP:          ip,
+		UserAgent:   r.UserAgent(),
+		Prefetch:    strings.Contains(purpose, "prefetch") || strings.Contains(purpose, "preview"),
+		Referrer:    referrer,
+		UTMSource:   q.Get("utm_source"),
+		UTMMedium:   q.Get("utm_medium"),
+		UTMCampaign: q.Get("utm_campaign"),
+	}
+}
+
+// RecordShare is the beacon behind the social share buttons. It accepts
+// navigator.sendBeacon bodies (JSON or form encoded) as well as ?network=,
+// and answers 204 whether or not the click was counted.
+func (h *NewsHandler) RecordShare(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, r, invalidField("id", "Invalid ID"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<10)
+	network := r.URL.Query().Get("network")
+	if network == "" {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+			network = r.PostFormValue("network")
+		} else {
+			var req struct {
+				Network string `json:"network"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+				problem.Write(w, r, decodeError(err))
+				return
+			}
+			network = req.Network
+		}
+	}
+	if network == "" {
+		problem.Write(w, r, validate.Value("network", network, "required"))
+		return
+	}
+
+	if err := h.svc.RecordShare(r.Context(), id, network, viewerFromRequest(r)); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (h *NewsHandler) GetHomepage(w http.ResponseWriter, r *http.Request) {
 	data, err := h.svc.GetHomepageData(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
 
+	if data.Featured != nil {
+		customMiddleware.AddSurrogateKeys(w, port.SurrogateKeyForNews(data.Featured.ID))
+	}
+	for _, list := range [][]*domain.News{data.Latest, data.Popular} {
+		for _, n := range list {
+			customMiddleware.AddSurrogateKeys(w, port.SurrogateKeyForNews(n.ID))
+		}
+	}
+
+	var featured *publicNews
+	if data.Featured != nil {
+		featured = &publicNews{News: data.Featured}
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(data)
+	json.NewEncoder(w).Encode(struct {
+		Featured *publicNews  `json:"featured"`
+		Latest   []publicNews `json:"latest"`
+		Popular  []publicNews `json:"popular"`
+	}{featured, publicNewsList(data.Latest), publicNewsList(data.Popular)})
 }
 
 func (h *NewsHandler) CheckSlug(w http.ResponseWriter, r *http.Request) {
 	slug := r.URL.Query().Get("slug")
-	if slug == "" {
-		http.Error(w, "Slug required", http.StatusBadRequest)
+	if err := validate.Value("slug", slug, "required,max=255"); err != nil {
+		problem.Write(w, r, err)
 		return
 	}
 
 	exists, err := h.svc.CheckSlug(r.Context(), slug)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -391,6 +585,13 @@ func (h *NewsHandler) CheckSlug(w http.ResponseWriter, r *http.Request) {
 
 // Category Handler
 
+// categoryRequest is the body of CreateCategory and UpdateCategory
+type categoryRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	NameBN      string `json:"name_bn" validate:"max=100"`
+	Description string `json:"description" validate:"max=2000"`
+}
+
 type CategoryHandler struct {
 	svc port.CategoryService
 }
@@ -400,19 +601,15 @@ func NewCategoryHandler(svc port.CategoryService) *CategoryHandler {
 }
 
 func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Name        string `json:"name"`
-		NameBN      string `json:"name_bn"`
-		Description string `json:"description"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+	var req categoryRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		problem.Write(w, r, err)
 		return
 	}
 
 	category, err := h.svc.CreateCategory(r.Context(), req.Name, req.NameBN, req.Description)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -425,23 +622,19 @@ func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		problem.Write(w, r, invalidField("id", "Invalid ID"))
 		return
 	}
 
-	var req struct {
-		Name        string `json:"name"`
-		NameBN      string `json:"name_bn"`
-		Description string `json:"description"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+	var req categoryRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		problem.Write(w, r, err)
 		return
 	}
 
 	err = h.svc.UpdateCategory(r.Context(), id, req.Name, req.NameBN, req.Description)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -452,13 +645,13 @@ func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request)
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		problem.Write(w, r, invalidField("id", "Invalid ID"))
 		return
 	}
 
 	err = h.svc.DeleteCategory(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -468,7 +661,7 @@ func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request)
 func (h *CategoryHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
 	categories, err := h.svc.ListCategories(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -487,12 +680,160 @@ func NewStatsHandler(svc *service.StatsService) *StatsHandler {
 }
 
 func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.svc.GetDashboardStats(r.Context())
+	filter, err := statsFilterFromRequest(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, err)
+		return
+	}
+
+	stats, err := h.svc.GetDashboardStats(r.Context(), filter)
+	if err != nil {
+		problem.Write(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
 }
+
+// GetSiteTimeSeries returns views over time for the whole site
+func (h *StatsHandler) GetSiteTimeSeries(w http.ResponseWriter, r *http.Request) {
+	h.writeTimeSeries(w, r, port.TimeSeriesFilter{})
+}
+
+func (h *StatsHandler) GetNewsTimeSeries(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, r, invalidField("id", "Invalid ID"))
+		return
+	}
+	h.writeTimeSeries(w, r, port.TimeSeriesFilter{NewsID: &id})
+}
+
+func (h *StatsHandler) GetCategoryTimeSeries(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, r, invalidField("id", "Invalid ID"))
+		return
+	}
+	h.writeTimeSeries(w, r, port.TimeSeriesFilter{CategoryID: &id})
+}
+
+func (h *StatsHandler) writeTimeSeries(w http.ResponseWriter, r *http.Request, filter port.TimeSeriesFilter) {
+	if err := timeSeriesRangeFromRequest(r, &filter); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	series, err := h.svc.GetViewTimeSeries(r.Context(), filter)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(series)
+}
+
+func (h *StatsHandler) GetSiteTraffic(w http.ResponseWriter, r *http.Request) {
+	h.writeTraffic(w, r, port.TrafficFilter{})
+}
+
+func (h *StatsHandler) GetNewsTraffic(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, r, invalidField("id", "Invalid ID"))
+		return
+	}
+	h.writeTraffic(w, r, port.TrafficFilter{NewsID: &id})
+}
+
+func (h *StatsHandler) writeTraffic(w http.ResponseWriter, r *http.Request, filter port.TrafficFilter) {
+	var err error
+	if filter.From, err = parseTimeParam(r.URL.Query().Get("from"), false); err != nil {
+		problem.Write(w, r, invalidField("from", "Invalid from"))
+		return
+	}
+	if filter.To, err = parseTimeParam(r.URL.Query().Get("to"), true); err != nil {
+		problem.Write(w, r, invalidField("to", "Invalid to"))
+		return
+	}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			problem.Write(w, r, invalidField("limit", "Invalid limit"))
+			return
+		}
+	}
+
+	breakdown, err := h.svc.GetTrafficBreakdown(r.Context(), filter)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(breakdown)
+}
+
+// statsFilterFromRequest reads the from, to, category and author query
+// parameters of the dashboard stats
+func statsFilterFromRequest(r *http.Request) (port.StatsFilter, error) {
+	q := r.URL.Query()
+
+	var filter port.StatsFilter
+	var err error
+	if filter.From, err = parseTimeParam(q.Get("from"), false); err != nil {
+		return filter, invalidField("from", "Invalid from")
+	}
+	if filter.To, err = parseTimeParam(q.Get("to"), true); err != nil {
+		return filter, invalidField("to", "Invalid to")
+	}
+	if category := q.Get("category"); category != "" {
+		id, err := uuid.Parse(category)
+		if err != nil {
+			return filter, invalidField("category", "Invalid category")
+		}
+		filter.CategoryID = &id
+	}
+	if author := q.Get("author"); author != "" {
+		id, err := uuid.Parse(author)
+		if err != nil {
+			return filter, invalidField("author", "Invalid author")
+		}
+		filter.AuthorID = &id
+	}
+	return filter, nil
+}
+
+// timeSeriesRangeFromRequest reads the from, to and granularity query
+// parameters of a time series
+func timeSeriesRangeFromRequest(r *http.Request, filter *port.TimeSeriesFilter) error {
+	var err error
+	if filter.From, err = parseTimeParam(r.URL.Query().Get("from"), false); err != nil {
+		return invalidField("from", "Invalid from")
+	}
+	if filter.To, err = parseTimeParam(r.URL.Query().Get("to"), true); err != nil {
+		return invalidField("to", "Invalid to")
+	}
+	filter.Granularity = r.URL.Query().Get("granularity")
+	return nil
+}
+
+// parseTimeParam accepts RFC 3339 timestamps or plain dates. A plain date used
+// as the end of a range includes that whole day.
+func parseTimeParam(value string, endOfRange bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"news-portal-backend/internal/core/port"
+)
+
+// Health Handler
+
+// HealthHandler answers the liveness and readiness probes of Docker and
+// the load balancer
+type HealthHandler struct {
+	svc port.HealthService
+}
+
+func NewHealthHandler(svc port.HealthService) *HealthHandler {
+	return &HealthHandler{svc: svc}
+}
+
+// Live reports that the process is up and serving requests. It checks no
+// dependencies, so a database outage doesn't get the container restarted.
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(port.HealthReport{Status: port.HealthOK})
+}
+
+// Ready reports whether the instance should receive traffic, with the
+// result of every dependency check. It answers 503 while any check fails
+// and once shutdown has begun.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	report := h.svc.Ready(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	if report.Status != port.HealthOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
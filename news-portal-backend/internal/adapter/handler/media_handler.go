@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"news-portal-backend/internal/adapter/metrics"
+	"news-portal-backend/internal/adapter/problem"
+	"news-portal-backend/internal/core/domain"
+	"news-portal-backend/internal/core/port"
+	"news-portal-backend/internal/core/service"
+)
+
+// Media Handler
+
+type MediaHandler struct {
+	svc port.MediaService
+}
+
+func NewMediaHandler(svc port.MediaService) *MediaHandler {
+	return &MediaHandler{svc: svc}
+}
+
+func (h *MediaHandler) CreateUploadURL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Filename    string `json:"filename" validate:"required,max=255"`
+		ContentType string `json:"content_type" validate:"required,max=100"`
+		Size        int64  `json:"size" validate:"required,min=1"`
+		SHA256      string `json:"sha256" validate:"len=64,hex"`
+	}
+	if err := decodeJSON(w, r, &req); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	userIDStr, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		problem.Write(w, r, errUnauthorized)
+		return
+	}
+	ownerID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		problem.Write(w, r, errUnauthorized)
+		return
+	}
+
+	upload, err := h.svc.RequestUpload(r.Context(), ownerID, req.Filename, req.ContentType, req.Size, req.SHA256)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if upload.Duplicate {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(upload)
+}
+
+func (h *MediaHandler) CompleteUpload(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, r, invalidField("id", "Invalid ID"))
+		return
+	}
+
+	userIDStr, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		problem.Write(w, r, errUnauthorized)
+		return
+	}
+	ownerID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		problem.Write(w, r, errUnauthorized)
+		return
+	}
+
+	media, err := h.svc.CompleteUpload(r.Context(), ownerID, id)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	metrics.UploadSize(metrics.UploadPresigned, media.SizeBytes)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(media)
+}
+
+// Local Storage Handler
+
+// LocalStorageHandler receives presigned PUTs when uploads are stored on disk
+// instead of R2.
+type LocalStorageHandler struct {
+	store *service.LocalStorageService
+}
+
+func NewLocalStorageHandler(store *service.LocalStorageService) *LocalStorageHandler {
+	return &LocalStorageHandler{store: store}
+}
+
+func (h *LocalStorageHandler) SignedUpload(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "key")
+	expires, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, invalidField("expires", "Invalid expires"))
+		return
+	}
+	size, err := strconv.ParseInt(r.URL.Query().Get("size"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, invalidField("size", "Invalid size"))
+		return
+	}
+	if r.ContentLength != size {
+		problem.Write(w, r, badRequest("Content-Length does not match signed size"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, size)
+	start := time.Now()
+	err = h.store.AcceptSignedUpload(service.SignedUpload{
+		Key:         key,
+		ContentType: r.Header.Get("Content-Type"),
+		Size:        size,
+		SHA256:      r.URL.Query().Get("sha256"),
+		Expires:     expires,
+		Signature:   r.URL.Query().Get("signature"),
+	}, r.Body)
+	if err != nil {
+		// A bad signature or a body that doesn't match what was signed
+		if errors.Is(err, domain.ErrInvalidInput) {
+			problem.Write(w, r, domain.NewError(domain.ErrForbidden, domain.CodeForbidden, err.Error()))
+			return
+		}
+		problem.Write(w, r, err)
+		return
+	}
+	// The size is recorded when the client completes the upload
+	metrics.UploadDuration(metrics.UploadPresigned, time.Since(start))
+
+	w.WriteHeader(http.StatusOK)
+}
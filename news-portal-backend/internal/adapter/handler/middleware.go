@@ -7,6 +7,9 @@ import (
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
+
+	"news-portal-backend/internal/adapter/logging"
+	"news-portal-backend/internal/adapter/problem"
 )
 
 func AuthMiddleware(secret string) func(http.Handler) http.Handler {
@@ -14,7 +17,7 @@ func AuthMiddleware(secret string) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			tokenString := r.Header.Get("Authorization")
 			if tokenString == "" {
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				problem.Write(w, r, errUnauthorized)
 				return
 			}
 
@@ -29,16 +32,19 @@ func AuthMiddleware(secret string) func(http.Handler) http.Handler {
 			})
 
 			if err != nil || !token.Valid {
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				problem.Write(w, r, errUnauthorized)
 				return
 			}
 
 			// Could extract claims and put in context
 			if claims, ok := token.Claims.(jwt.MapClaims); ok {
 				ctx := context.WithValue(r.Context(), "user_id", claims["sub"])
+				if sub, ok := claims["sub"].(string); ok {
+					ctx = logging.SetUserID(ctx, sub)
+				}
 				next.ServeHTTP(w, r.WithContext(ctx))
 			} else {
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				problem.Write(w, r, errUnauthorized)
 			}
 		})
 	}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"news-portal-backend/internal/adapter/problem"
+	"news-portal-backend/internal/core/port"
+)
+
+// Report Handler
+
+// ReportHandler manages the signed-in user's scheduled email reports
+type ReportHandler struct {
+	svc port.ReportService
+}
+
+func NewReportHandler(svc port.ReportService) *ReportHandler {
+	return &ReportHandler{svc: svc}
+}
+
+func (h *ReportHandler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := ownerIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	schedules, err := h.svc.ListSchedules(r.Context(), ownerID)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schedules)
+}
+
+func (h *ReportHandler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := ownerIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req port.ReportScheduleInput
+	if err := decodeJSON(w, r, &req); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	schedule, err := h.svc.CreateSchedule(r.Context(), ownerID, req)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(schedule)
+}
+
+func (h *ReportHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := ownerIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, r, invalidField("id", "Invalid ID"))
+		return
+	}
+
+	var req port.ReportScheduleInput
+	if err := decodeJSON(w, r, &req); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	schedule, err := h.svc.UpdateSchedule(r.Context(), ownerID, id, req)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schedule)
+}
+
+func (h *ReportHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := ownerIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, r, invalidField("id", "Invalid ID"))
+		return
+	}
+
+	if err := h.svc.DeleteSchedule(r.Context(), ownerID, id); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ReportHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := ownerIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, r, invalidField("id", "Invalid ID"))
+		return
+	}
+
+	runs, err := h.svc.ListRuns(r.Context(), ownerID, id)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(runs)
+}
+
+// SendNow sends a report immediately and returns the recorded run, which
+// may have failed
+func (h *ReportHandler) SendNow(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := ownerIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, r, invalidField("id", "Invalid ID"))
+		return
+	}
+
+	run, err := h.svc.SendNow(r.Context(), ownerID, id)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// ownerIDFromRequest returns the signed-in user's ID, answering 401 when
+// it is missing
+func ownerIDFromRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userIDStr, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		problem.Write(w, r, errUnauthorized)
+		return uuid.Nil, false
+	}
+	ownerID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		problem.Write(w, r, errUnauthorized)
+		return uuid.Nil, false
+	}
+	return ownerID, true
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"news-portal-backend/internal/adapter/validate"
+	"news-portal-backend/internal/core/domain"
+)
+
+// maxJSONBodyBytes caps every JSON request body
+const maxJSONBodyBytes = 1 << 20 // 1 MB
+
+// decodeJSON decodes the request body into dst and validates it with its
+// validate tags. Unknown fields, trailing data and bodies larger than
+// maxJSONBodyBytes are rejected.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return badRequest("Request body must contain a single JSON object")
+	}
+	return validate.Struct(dst)
+}
+
+// decodeError describes a JSON decoding failure without echoing the body
+func decodeError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return domain.NewError(domain.ErrTooLarge, domain.CodeTooLarge, fmt.Sprintf("Request body must not exceed %d bytes", maxBytesErr.Limit))
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return invalidField(typeErr.Field, fmt.Sprintf("%s must be a %s", typeErr.Field, jsonTypeName(typeErr.Type.Kind().String())))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return &domain.Error{
+			Kind:    domain.ErrBadRequest,
+			Code:    domain.CodeBadRequest,
+			Message: "Unknown field " + field,
+			Fields:  []domain.FieldError{{Field: field, Code: domain.FieldUnknown, Message: field + " is not a known field"}},
+		}
+	case errors.Is(err, io.EOF):
+		return badRequest("Request body is empty")
+	}
+	return badRequest("Request body is not valid JSON")
+}
+
+func jsonTypeName(kind string) string {
+	switch {
+	case strings.HasPrefix(kind, "int"), strings.HasPrefix(kind, "uint"), strings.HasPrefix(kind, "float"):
+		return "number"
+	case kind == "slice", kind == "array":
+		return "list"
+	case kind == "bool":
+		return "boolean"
+	case kind == "struct", kind == "map":
+		return "object"
+	}
+	return kind
+}
@@ -7,6 +7,8 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"news-portal-backend/internal/adapter/problem"
+	"news-portal-backend/internal/core/domain"
 	"news-portal-backend/internal/core/port"
 
 	"github.com/google/uuid"
@@ -21,11 +23,12 @@ func NewSeedHandler(svc port.NewsService) *SeedHandler {
 }
 
 type SeedNewsRequest struct {
-	CategoryID   string `json:"category_id"`
-	Title        string `json:"title"`
-	Excerpt      string `json:"excerpt"`
-	Content      string `json:"content"`
-	ThumbnailURL string `json:"thumbnail_url"`
+	CategoryID   string `json:"category_id" validate:"required,uuid"`
+	Title        string `json:"title" validate:"required,max=255"`
+	Excerpt      string `json:"excerpt" validate:"max=1000"`
+	Content      string `json:"content" validate:"required"`
+	ThumbnailURL string `json:"thumbnail_url" validate:"url"`
+	ThumbnailAlt string `json:"thumbnail_alt" validate:"max=500"`
 	IsFeatured   bool   `json:"is_featured"`
 }
 
@@ -33,37 +36,27 @@ type SeedNewsRequest struct {
 // DELETE THIS ENDPOINT BEFORE PRODUCTION
 func (h *SeedHandler) SEED_CreateNews(w http.ResponseWriter, r *http.Request) {
 	var req SeedNewsRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	if req.Title == "" || req.Content == "" {
-		http.Error(w, "Title and Content are required", http.StatusBadRequest)
-		return
-	}
-
-	categoryID, err := uuid.Parse(req.CategoryID)
-	if err != nil {
-		http.Error(w, "Invalid Category ID", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		problem.Write(w, r, err)
 		return
 	}
+	categoryID := uuid.MustParse(req.CategoryID)
 
 	// Get author_id from context
 	userIDStr, ok := r.Context().Value("user_id").(string)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		problem.Write(w, r, errUnauthorized)
 		return
 	}
 	authorID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		http.Error(w, "Invalid author ID", http.StatusUnauthorized)
+		problem.Write(w, r, errUnauthorized)
 		return
 	}
 
-	news, err := h.svc.CreateNews(r.Context(), authorID, categoryID, req.Title, req.Excerpt, req.Content, req.ThumbnailURL, req.IsFeatured)
+	news, err := h.svc.CreateNews(r.Context(), authorID, categoryID, req.Title, req.Excerpt, req.Content, req.ThumbnailURL, nil, domain.ThumbnailMeta{Alt: req.ThumbnailAlt}, req.IsFeatured, true)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -0,0 +1,143 @@
+// Package logging writes structured access logs and keeps credentials out
+// of every log line.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"news-portal-backend/internal/core/port"
+)
+
+// Config controls what the access log records
+type Config struct {
+	// HealthSampleRate is the fraction (0 to 1) of successful /healthz and
+	// /readyz requests that are logged. Failed probes are always logged.
+	HealthSampleRate float64
+	// Headers adds the request headers to every line, with credentials
+	// redacted
+	Headers bool
+}
+
+// healthRoutes are polled every few seconds by orchestrators and load
+// balancers and would drown out real traffic
+var healthRoutes = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+}
+
+// request is filled in while the request passes through the router so the
+// access log can report what inner middleware learned about it
+type request struct {
+	userID string
+}
+
+type requestKey struct{}
+
+// Middleware logs one line per request with its method, route pattern,
+// status, size, duration, request ID, user ID and client IP. It also
+// stores a request-scoped logger in the context for handlers and services
+// (see port.Logger). It must run after middleware.RequestID and RealIP.
+func Middleware(cfg Config) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			requestID := middleware.GetReqID(r.Context())
+			clientIP := clientIP(r)
+
+			req := &request{}
+			logger := port.Logger(r.Context()).With("request_id", requestID)
+			ctx := context.WithValue(r.Context(), requestKey{}, req)
+			ctx = port.WithLogger(ctx, logger)
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			route := ""
+			if rctx := chi.RouteContext(ctx); rctx != nil {
+				route = rctx.RoutePattern()
+			}
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if healthRoutes[route] && status < http.StatusBadRequest && rand.Float64() >= cfg.HealthSampleRate {
+				return
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("route", route),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.String("client_ip", clientIP),
+				slog.String("user_agent", r.UserAgent()),
+			}
+			if r.URL.RawQuery != "" {
+				attrs = append(attrs, slog.String("query", redactQuery(r.URL.Query())))
+			}
+			if req.userID != "" {
+				attrs = append(attrs, slog.String("user_id", req.userID))
+			}
+			if cfg.Headers {
+				attrs = append(attrs, slog.Any("headers", redactHeaders(r.Header)))
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			logger.LogAttrs(ctx, level, "Request", attrs...)
+		})
+	}
+}
+
+// SetUserID records the authenticated user for the access log and returns
+// a context whose request-scoped logger carries the user ID
+func SetUserID(ctx context.Context, userID string) context.Context {
+	if req, ok := ctx.Value(requestKey{}).(*request); ok {
+		req.userID = userID
+	}
+	return port.WithLogger(ctx, port.Logger(ctx).With("user_id", userID))
+}
+
+// clientIP strips the port from the remote address, which RealIP has
+// already replaced with the address forwarded by a trusted proxy
+func clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+func redactQuery(query url.Values) string {
+	for key := range query {
+		if sensitive(key) {
+			query[key] = []string{redacted}
+		}
+	}
+	return query.Encode()
+}
+
+func redactHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for key := range header {
+		if sensitive(key) {
+			headers[key] = redacted
+			continue
+		}
+		headers[key] = header.Get(key)
+	}
+	return headers
+}
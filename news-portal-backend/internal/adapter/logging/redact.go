@@ -0,0 +1,36 @@
+package logging
+
+import (
+	"log/slog"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are matched case-insensitively against attribute, header
+// and query parameter names. A key containing "password" always matches.
+var sensitiveKeys = map[string]bool{
+	"authorization": true,
+	"cookie":        true,
+	"set-cookie":    true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"secret":        true,
+	"signature":     true,
+}
+
+func sensitive(key string) bool {
+	key = strings.ToLower(key)
+	return sensitiveKeys[key] || strings.Contains(key, "password")
+}
+
+// ReplaceAttr redacts attributes named after credentials, such as
+// "authorization" or "new_password", wherever they are logged. Use it as
+// slog.HandlerOptions.ReplaceAttr.
+func ReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if sensitive(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	return a
+}
@@ -0,0 +1,162 @@
+// Package metrics exposes Prometheus metrics for HTTP traffic, the database
+// pool, rate limiting, uploads and editorial events.
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"news-portal-backend/internal/adapter/problem"
+	"news-portal-backend/internal/core/domain"
+)
+
+const namespace = "news_portal"
+
+// unmatchedRoute labels requests that never reached a route (404s,
+// preflights and rate-limited requests), so raw paths never become labels
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route pattern and status.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"method", "route", "status"})
+
+	httpInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests currently being served.",
+	})
+
+	rateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Requests rejected by the rate limiter by policy.",
+	}, []string{"policy"})
+
+	uploadSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upload_size_bytes",
+		Help:      "Size of uploaded files by upload path.",
+		Buckets:   prometheus.ExponentialBuckets(16<<10, 4, 8), // 16 KB to 256 MB
+	}, []string{"path"})
+
+	uploadDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upload_duration_seconds",
+		Help:      "Time spent receiving and storing uploaded files by upload path.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"path"})
+
+	articlesPublished = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "articles_published_total",
+		Help:      "Articles published.",
+	})
+
+	logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts by result: success, failure (wrong credentials), limited (too many attempts for the account) or error.",
+	}, []string{"result"})
+)
+
+// Upload paths
+const (
+	// UploadForm is a thumbnail sent with the article form and stored by the API
+	UploadForm = "form"
+	// UploadPresigned is a file the client sent to a presigned URL. Its
+	// duration is only known when the local storage driver receives it.
+	UploadPresigned = "presigned"
+)
+
+// Login results
+const (
+	LoginSuccess = "success"
+	LoginFailure = "failure"
+	LoginLimited = "limited"
+	LoginError   = "error"
+)
+
+// Middleware counts and times every request by its chi route pattern
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		httpInFlight.Inc()
+		defer httpInFlight.Dec()
+
+		next.ServeHTTP(ww, r)
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		labels := prometheus.Labels{"method": r.Method, "route": route, "status": strconv.Itoa(status)}
+		httpRequests.With(labels).Inc()
+		httpDuration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
+
+// Handler serves the metrics. With a token, scrapers must send it as
+// "Authorization: Bearer <token>".
+func Handler(token string) http.Handler {
+	h := promhttp.Handler()
+	if token == "" {
+		return h
+	}
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			problem.Write(w, r, domain.NewError(domain.ErrUnauthorized, domain.CodeUnauthorized, "A valid metrics token is required"))
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// RateLimited records a request rejected by the named rate limit policy
+func RateLimited(policy string) {
+	rateLimited.WithLabelValues(policy).Inc()
+}
+
+// UploadSize records the size of a stored file
+func UploadSize(path string, size int64) {
+	uploadSize.WithLabelValues(path).Observe(float64(size))
+}
+
+// UploadDuration records how long the API took to receive and store a file
+func UploadDuration(path string, d time.Duration) {
+	uploadDuration.WithLabelValues(path).Observe(d.Seconds())
+}
+
+// ArticlePublished records a newly published article
+func ArticlePublished() {
+	articlesPublished.Inc()
+}
+
+// Login records a login attempt
+func Login(result string) {
+	logins.WithLabelValues(result).Inc()
+}
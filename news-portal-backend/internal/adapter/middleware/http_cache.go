@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CachePolicy describes how browsers and shared caches may store a public
+// GET response
+type CachePolicy struct {
+	// MaxAge applies to browsers, SharedMaxAge to CDNs and proxies
+	MaxAge       time.Duration
+	SharedMaxAge time.Duration
+	// StaleWhileRevalidate lets caches serve an expired copy while they
+	// fetch a new one in the background
+	StaleWhileRevalidate time.Duration
+	// SurrogateKeys tag every response of the route for CDN purges
+	SurrogateKeys []string
+	// Private keeps responses out of shared caches, for routes where every
+	// request has to reach the API. Browsers may still revalidate with the
+	// ETag. SharedMaxAge and SurrogateKeys are ignored.
+	Private bool
+}
+
+func (p CachePolicy) cacheControl() string {
+	if p.Private {
+		parts := []string{"private", fmt.Sprintf("max-age=%d", int(p.MaxAge.Seconds()))}
+		if p.MaxAge == 0 {
+			parts = append(parts, "must-revalidate")
+		}
+		return strings.Join(parts, ", ")
+	}
+
+	parts := []string{"public", fmt.Sprintf("max-age=%d", int(p.MaxAge.Seconds()))}
+	if p.SharedMaxAge > 0 {
+		parts = append(parts, fmt.Sprintf("s-maxage=%d", int(p.SharedMaxAge.Seconds())))
+	}
+	if p.StaleWhileRevalidate > 0 {
+		parts = append(parts, fmt.Sprintf("stale-while-revalidate=%d", int(p.StaleWhileRevalidate.Seconds())))
+	}
+	if p.MaxAge == 0 {
+		parts = append(parts, "must-revalidate")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// HTTPCache adds a strong ETag computed from the response body to
+// successful GET and HEAD responses, answers conditional requests with 304
+// Not Modified, and sets Cache-Control and Surrogate-Key from the policy.
+// Handlers can set Last-Modified and add their own surrogate keys with
+// AddSurrogateKeys.
+//
+// Requests carrying an Authorization header get a private response so no
+// shared cache ever stores them.
+func HTTPCache(policy CachePolicy) func(http.Handler) http.Handler {
+	cacheControl := policy.cacheControl()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			buf := &bufferedResponse{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(buf, r)
+
+			h := w.Header()
+			if buf.status != http.StatusOK {
+				w.WriteHeader(buf.status)
+				w.Write(buf.body.Bytes())
+				return
+			}
+
+			etag := strongETag(buf.body.Bytes())
+			h.Set("ETag", etag)
+			if r.Header.Get("Authorization") != "" {
+				h.Set("Cache-Control", "private, no-cache")
+				h.Del("Surrogate-Key")
+			} else if policy.Private {
+				h.Set("Cache-Control", cacheControl)
+				h.Del("Surrogate-Key")
+			} else {
+				h.Set("Cache-Control", cacheControl)
+				AddSurrogateKeys(w, policy.SurrogateKeys...)
+			}
+
+			if notModified(r, etag, h.Get("Last-Modified")) {
+				h.Del("Content-Type")
+				h.Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			h.Set("Content-Length", strconv.Itoa(buf.body.Len()))
+			w.WriteHeader(http.StatusOK)
+			if r.Method != http.MethodHead {
+				w.Write(buf.body.Bytes())
+			}
+		})
+	}
+}
+
+// NoStore marks every response as private and uncacheable. It is meant for
+// authenticated route groups.
+func NoStore(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "private, no-store")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AddSurrogateKeys appends keys to the response's Surrogate-Key header,
+// skipping duplicates
+func AddSurrogateKeys(w http.ResponseWriter, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	existing := strings.Fields(w.Header().Get("Surrogate-Key"))
+	for _, key := range keys {
+		dup := false
+		for _, e := range existing {
+			if e == key {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			existing = append(existing, key)
+		}
+	}
+	w.Header().Set("Surrogate-Key", strings.Join(existing, " "))
+}
+
+// SetLastModified sets Last-Modified to the latest of the given times
+func SetLastModified(w http.ResponseWriter, times ...time.Time) {
+	var latest time.Time
+	for _, t := range times {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	if !latest.IsZero() {
+		w.Header().Set("Last-Modified", latest.UTC().Format(http.TimeFormat))
+	}
+}
+
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when no entity
+// tag was sent, as RFC 9110 orders them
+func notModified(r *http.Request, etag, lastModified string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
+
+// bufferedResponse holds the body until the ETag can be computed
+type bufferedResponse struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if !b.wroteHeader {
+		b.status = status
+		b.wroteHeader = true
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.wroteHeader = true
+	return b.body.Write(p)
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPCacheControl(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        CachePolicy
+		authorization string
+		want          string
+		surrogateKeys string
+	}{
+		{
+			"public",
+			CachePolicy{MaxAge: 30 * time.Second, SharedMaxAge: time.Minute, StaleWhileRevalidate: 5 * time.Minute, SurrogateKeys: []string{"list"}},
+			"",
+			"public, max-age=30, s-maxage=60, stale-while-revalidate=300",
+			"handler list",
+		},
+		{
+			"edge only",
+			CachePolicy{SharedMaxAge: time.Minute},
+			"",
+			"public, max-age=0, s-maxage=60, must-revalidate",
+			"handler",
+		},
+		{
+			"private",
+			CachePolicy{Private: true, SharedMaxAge: time.Minute, SurrogateKeys: []string{"list"}},
+			"",
+			"private, max-age=0, must-revalidate",
+			"",
+		},
+		{
+			"authenticated",
+			CachePolicy{MaxAge: 30 * time.Second, SharedMaxAge: time.Minute, SurrogateKeys: []string{"list"}},
+			"Bearer token",
+			"private, no-cache",
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := HTTPCache(tt.policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				AddSurrogateKeys(w, "handler")
+				w.Write([]byte("body"))
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.want)
+			}
+			if got := rec.Header().Get("Surrogate-Key"); got != tt.surrogateKeys {
+				t.Errorf("Surrogate-Key = %q, want %q", got, tt.surrogateKeys)
+			}
+		})
+	}
+}
+
+func TestHTTPCacheConditional(t *testing.T) {
+	modified := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	h := HTTPCache(CachePolicy{Private: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetLastModified(w, modified)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1}`))
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("status = %d, ETag = %q", rec.Code, etag)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{"matching ETag", "If-None-Match", etag, http.StatusNotModified},
+		{"weak form of the ETag", "If-None-Match", "W/" + etag, http.StatusNotModified},
+		{"one of several ETags", "If-None-Match", `"other", ` + etag, http.StatusNotModified},
+		{"other ETag", "If-None-Match", `"other"`, http.StatusOK},
+		{"not modified since", "If-Modified-Since", modified.Format(http.TimeFormat), http.StatusNotModified},
+		{"modified since", "If-Modified-Since", modified.Add(-time.Hour).Format(http.TimeFormat), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(tt.header, tt.value)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Errorf("304 has a body: %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+// Error responses are passed through without validators, so a client never
+// revalidates against a failure
+func TestHTTPCacheSkipsErrors(t *testing.T) {
+	h := HTTPCache(CachePolicy{MaxAge: time.Minute})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("missing"))
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusNotFound || rec.Header().Get("ETag") != "" || rec.Header().Get("Cache-Control") != "" {
+		t.Errorf("status = %d, ETag = %q, Cache-Control = %q", rec.Code, rec.Header().Get("ETag"), rec.Header().Get("Cache-Control"))
+	}
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"news-portal-backend/internal/core/port"
+)
+
+// recordingStore allows the first limit requests per key and records the
+// keys it was asked about
+type recordingStore struct {
+	port.RateLimitStore
+	keys   []string
+	counts map[string]int
+	err    error
+}
+
+func (s *recordingStore) TakeRateLimit(ctx context.Context, key string, limit int, window time.Duration) (*port.RateLimitResult, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.counts == nil {
+		s.counts = make(map[string]int)
+	}
+	s.keys = append(s.keys, key)
+	if s.counts[key] >= limit {
+		return &port.RateLimitResult{Allowed: false, Reset: window, RetryAfter: 1500 * time.Millisecond}, nil
+	}
+	s.counts[key]++
+	return &port.RateLimitResult{Allowed: true, Remaining: limit - s.counts[key], Reset: window}, nil
+}
+
+func newTestLimiter(t *testing.T, store port.RateLimitStore, trusted string) http.Handler {
+	t.Helper()
+	allowlist, err := ParseNetworks("172.18.0.0/16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxies, err := ParseNetworks(trusted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rl := NewRateLimiter(store, RateLimitConfig{
+		Policies:  []RateLimitPolicy{{Name: RateLimitLogin, Rate: 1, Burst: 2}},
+		Allowlist: allowlist,
+	})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	return RealIP(proxies)(rl.Limit(RateLimitLogin)(ok))
+}
+
+func send(h http.Handler, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil)
+	r.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		r.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestRateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	store := &recordingStore{}
+	h := newTestLimiter(t, store, "10.0.0.0/8")
+
+	// A new address on every request, then an allowlisted SSR address
+	for _, spoofed := range []string{"1.1.1.1", "2.2.2.2", "3.3.3.3", "172.18.0.5"} {
+		send(h, "203.0.113.7:4000", spoofed)
+	}
+	for _, key := range store.keys {
+		if key != "login:ip:203.0.113.7" {
+			t.Fatalf("key = %q, want the peer address", key)
+		}
+	}
+	if len(store.keys) != 4 {
+		t.Fatalf("store saw %d requests, want 4: the allowlist must not apply", len(store.keys))
+	}
+	if w := send(h, "203.0.113.7:4000", "172.18.0.5"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want 429", w.Code)
+	}
+}
+
+func TestRateLimitTrustedProxy(t *testing.T) {
+	store := &recordingStore{}
+	h := newTestLimiter(t, store, "10.0.0.0/8")
+
+	send(h, "10.0.0.2:4000", "198.51.100.4")
+	if len(store.keys) != 1 || store.keys[0] != "login:ip:198.51.100.4" {
+		t.Errorf("keys = %v, want the forwarded client", store.keys)
+	}
+
+	// SSR servers behind the proxy are exempt
+	send(h, "10.0.0.2:4000", "172.18.0.5")
+	send(h, "172.18.0.5:4000", "")
+	if len(store.keys) != 1 {
+		t.Errorf("allowlisted requests reached the store: %v", store.keys)
+	}
+}
+
+func TestRateLimitPerUser(t *testing.T) {
+	store := &recordingStore{}
+	h := newTestLimiter(t, store, "")
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "203.0.113.7:4000"
+	r = r.WithContext(context.WithValue(r.Context(), "user_id", "u1"))
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if len(store.keys) != 1 || store.keys[0] != "login:user:u1" {
+		t.Errorf("keys = %v, want the user", store.keys)
+	}
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	h := newTestLimiter(t, &recordingStore{}, "")
+
+	w := send(h, "203.0.113.7:4000", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	want := map[string]string{
+		"RateLimit-Policy":    "2;w=2",
+		"RateLimit-Limit":     "2",
+		"RateLimit-Remaining": "1",
+		"RateLimit-Reset":     "2",
+	}
+	for name, value := range want {
+		if got := w.Header().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+
+	send(h, "203.0.113.7:4000", "")
+	w = send(h, "203.0.113.7:4000", "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+}
+
+func TestRateLimitAllowsWhenStoreFails(t *testing.T) {
+	h := newTestLimiter(t, &recordingStore{err: errors.New("store down")}, "")
+	if w := send(h, "203.0.113.7:4000", ""); w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+}
+
+func TestRateLimitUnknownPolicyPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	NewRateLimiter(&recordingStore{}, RateLimitConfig{}).Limit("missing")
+}
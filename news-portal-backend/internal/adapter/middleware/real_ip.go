@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// RealIP replaces r.RemoteAddr with the client address reported by a
+// reverse proxy, but only when the direct peer is one of the trusted
+// networks: anyone else can put any address in forwarding headers.
+// X-Forwarded-For is read right to left, skipping trusted proxies, so
+// addresses a client prepended itself are never used. With no trusted
+// networks the peer address is always kept.
+func RealIP(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := forwardedIP(r, trusted); ip.IsValid() {
+				r.RemoteAddr = ip.String()
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func forwardedIP(r *http.Request, trusted []netip.Prefix) netip.Addr {
+	peer, err := netip.ParseAddr(clientIP(r))
+	if err != nil || !containsAddr(trusted, peer.Unmap()) {
+		return netip.Addr{}
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		var addr netip.Addr
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, err = netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				// Nothing left of a malformed hop can be trusted
+				return netip.Addr{}
+			}
+			addr = addr.Unmap()
+			if !containsAddr(trusted, addr) {
+				return addr
+			}
+		}
+		// Every hop is a trusted proxy; the first one made the request
+		return addr
+	}
+
+	if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return addr.Unmap()
+	}
+	return netip.Addr{}
+}
+
+// clientIP strips the port from the remote address, which RealIP has
+// already replaced with the address forwarded by a trusted proxy
+func clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+func containsAddr(networks []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range networks {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseNetworks parses a comma-separated list of networks in CIDR notation
+// or single IPs
+func ParseNetworks(list string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
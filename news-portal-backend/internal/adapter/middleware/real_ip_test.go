@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	trusted, err := ParseNetworks("10.0.0.0/8, 192.168.1.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		want       string
+	}{
+		{"untrusted peer keeps its address", "203.0.113.7:5000", []string{"10.1.1.1"}, "10.2.2.2", "203.0.113.7:5000"},
+		{"trusted peer without headers", "10.0.0.2:5000", nil, "", "10.0.0.2:5000"},
+		{"trusted peer forwards the client", "10.0.0.2:5000", []string{"198.51.100.4"}, "", "198.51.100.4"},
+		{"client-prepended hops are ignored", "10.0.0.2:5000", []string{"1.2.3.4, 198.51.100.4"}, "", "198.51.100.4"},
+		{"trusted hops are skipped", "10.0.0.2:5000", []string{"198.51.100.4, 10.0.0.9", "192.168.1.5"}, "", "198.51.100.4"},
+		{"only trusted hops", "10.0.0.2:5000", []string{"10.0.0.9"}, "", "10.0.0.9"},
+		{"malformed hop", "10.0.0.2:5000", []string{"198.51.100.4, nonsense"}, "", "10.0.0.2:5000"},
+		{"X-Real-IP from a trusted peer", "10.0.0.2:5000", nil, "198.51.100.4", "198.51.100.4"},
+		{"IPv4-mapped peer", "[::ffff:10.0.0.2]:5000", []string{"198.51.100.4"}, "", "198.51.100.4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := RealIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), r)
+			if got != tt.want {
+				t.Errorf("RemoteAddr = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRealIPWithoutTrustedProxies(t *testing.T) {
+	var got string
+	h := RealIP(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.RemoteAddr
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.2:5000"
+	r.Header.Set("X-Forwarded-For", "198.51.100.4")
+	r.Header.Set("X-Real-IP", "198.51.100.4")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if got != "10.0.0.2:5000" {
+		t.Errorf("RemoteAddr = %q, want the peer address", got)
+	}
+}
+
+func TestParseNetworks(t *testing.T) {
+	networks, err := ParseNetworks(" 10.1.2.3/8 ,, 2001:db8::1 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(networks) != 2 || networks[0].String() != "10.0.0.0/8" || networks[1].String() != "2001:db8::1/128" {
+		t.Errorf("networks = %v", networks)
+	}
+	if _, err := ParseNetworks("10.0.0.0/33"); err == nil {
+		t.Error("expected an error for an invalid prefix")
+	}
+	if _, err := ParseNetworks("example.com"); err == nil {
+		t.Error("expected an error for a host name")
+	}
+}
@@ -0,0 +1,213 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// ValidateResponses checks every response against the operation the
+// document declares for its route, status and content type, and logs each
+// mismatch as a contract violation. Responses are passed through
+// unchanged. It buffers a copy of every body, so it is meant for
+// development, staging and CI rather than production traffic.
+func ValidateResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body bytes.Buffer
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ww.Tee(&body)
+
+		next.ServeHTTP(ww, r)
+
+		rctx := chi.RouteContext(r.Context())
+		if rctx == nil || !strings.HasPrefix(rctx.RoutePattern(), "/api/") {
+			return
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		violations := CheckResponse(r.Method, rctx.RoutePattern(), status, ww.Header().Get("Content-Type"), body.Bytes())
+		if len(violations) > 0 {
+			slog.Warn("Response does not match the OpenAPI spec",
+				"method", r.Method,
+				"route", rctx.RoutePattern(),
+				"status", status,
+				"violations", violations,
+			)
+		}
+	})
+}
+
+// CheckResponse validates one response against the document and returns
+// a description of every mismatch. Non-JSON bodies are only checked for a
+// declared status and content type.
+func CheckResponse(method, pattern string, status int, contentType string, body []byte) []string {
+	op := operation(method, pattern)
+	if op == nil {
+		return []string{"operation is not documented"}
+	}
+	responses, _ := op["responses"].(map[string]any)
+	declared, ok := responses[strconv.Itoa(status)].(map[string]any)
+	if !ok {
+		return []string{fmt.Sprintf("status %d is not documented", status)}
+	}
+	declared = resolve(declared)
+
+	content, _ := declared["content"].(map[string]any)
+	if len(content) == 0 {
+		if len(body) > 0 && status != http.StatusNotModified {
+			return []string{"body is not documented"}
+		}
+		return nil
+	}
+
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+	media, ok := content[mediaType].(map[string]any)
+	if !ok {
+		return []string{fmt.Sprintf("content type %q is not documented", mediaType)}
+	}
+	if mediaType != "application/json" && mediaType != "application/problem+json" {
+		return nil
+	}
+	schema, _ := media["schema"].(map[string]any)
+	if schema == nil {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []string{"body is not valid JSON: " + err.Error()}
+	}
+	return checkSchema(schema, value, "$", nil)
+}
+
+// checkSchema supports the JSON Schema keywords the document uses: type
+// (including type lists for nullable fields), enum, properties, required,
+// additionalProperties, items and $ref. oneOf passes when any branch does.
+func checkSchema(schema map[string]any, value any, at string, violations []string) []string {
+	schema = resolve(schema)
+
+	if branches, ok := schema["oneOf"].([]any); ok {
+		for _, b := range branches {
+			branch, _ := b.(map[string]any)
+			if len(checkSchema(branch, value, at, nil)) == 0 {
+				return violations
+			}
+		}
+		return append(violations, at+": matches none of the allowed schemas")
+	}
+
+	if types := schemaTypes(schema); len(types) > 0 && !hasType(types, value) {
+		return append(violations, fmt.Sprintf("%s: expected %s, got %s", at, strings.Join(types, " or "), jsonType(value)))
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		allowed := false
+		for _, e := range enum {
+			if e == value {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			violations = append(violations, fmt.Sprintf("%s: %v is not one of %v", at, value, enum))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := v[name.(string)]; !ok {
+				violations = append(violations, fmt.Sprintf("%s: missing required property %q", at, name))
+			}
+		}
+
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if prop, ok := properties[name].(map[string]any); ok {
+				violations = checkSchema(prop, v[name], at+"."+name, violations)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					violations = append(violations, fmt.Sprintf("%s: undocumented property %q", at, name))
+				}
+			case map[string]any:
+				violations = checkSchema(extra, v[name], at+"."+name, violations)
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				violations = checkSchema(items, item, fmt.Sprintf("%s[%d]", at, i), violations)
+			}
+		}
+	}
+	return violations
+}
+
+func schemaTypes(schema map[string]any) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, s := range t {
+			if s, ok := s.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func hasType(types []string, value any) bool {
+	actual := jsonType(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType names a decoded JSON value's type, telling integers apart from
+// other numbers
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
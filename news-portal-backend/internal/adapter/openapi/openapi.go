@@ -0,0 +1,87 @@
+// Package openapi serves the API's OpenAPI 3.1 document and a docs page,
+// and checks the router and live responses against the document so the
+// two can't drift apart unnoticed.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+//go:embed openapi.json
+var specJSON []byte
+
+//go:embed docs.html
+var docsHTML []byte
+
+// document is the parsed spec the checks walk
+var document = mustParse(specJSON)
+
+func mustParse(data []byte) map[string]any {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		panic("openapi: embedded openapi.json is invalid: " + err.Error())
+	}
+	return doc
+}
+
+// Spec serves the OpenAPI document
+func Spec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(specJSON)
+}
+
+// Docs serves an interactive reference page that renders the document
+func Docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(docsHTML)
+}
+
+// UndocumentedRoutes returns the API routes of the router ("GET /api/v1/news")
+// that have no operation in the document. Routes outside /api, such as
+// the local upload file server, are not part of the API and are skipped.
+func UndocumentedRoutes(routes chi.Routes) []string {
+	var missing []string
+	chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if !strings.HasPrefix(route, "/api/") || operation(method, route) != nil {
+			return nil
+		}
+		missing = append(missing, method+" "+route)
+		return nil
+	})
+	sort.Strings(missing)
+	return missing
+}
+
+// operation finds the document's operation for a chi route pattern
+func operation(method, pattern string) map[string]any {
+	paths, _ := document["paths"].(map[string]any)
+	item, _ := paths[strings.TrimSuffix(pattern, "/")].(map[string]any)
+	op, _ := item[strings.ToLower(method)].(map[string]any)
+	return op
+}
+
+// resolve follows a local "#/components/..." reference
+func resolve(node map[string]any) map[string]any {
+	for {
+		ref, ok := node["$ref"].(string)
+		if !ok {
+			return node
+		}
+		next := any(document)
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			m, _ := next.(map[string]any)
+			next = m[part]
+		}
+		target, ok := next.(map[string]any)
+		if !ok {
+			return map[string]any{}
+		}
+		node = target
+	}
+}
@@ -0,0 +1,82 @@
+package problem
+
+import (
+	"net/http"
+	"strings"
+
+	"news-portal-backend/internal/core/domain"
+)
+
+// Languages the error titles are translated into
+const (
+	langEnglish = "en"
+	langBangla  = "bn"
+)
+
+// titles holds the English and Bangla title of every error code
+var titles = map[string][2]string{
+	domain.CodeBadRequest:         {"Malformed request", "অনুরোধটি সঠিক নয়"},
+	domain.CodeInvalidInput:       {"Invalid input", "অবৈধ তথ্য"},
+	domain.CodeValidationFailed:   {"Some fields are invalid", "কিছু তথ্য সঠিক নয়"},
+	domain.CodeUnauthorized:       {"Authentication required", "লগইন প্রয়োজন"},
+	domain.CodeForbidden:          {"Not allowed", "অনুমতি নেই"},
+	domain.CodeNotFound:           {"Not found", "পাওয়া যায়নি"},
+	domain.CodeConflict:           {"Conflict", "বর্তমান অবস্থার সাথে অনুরোধটি মেলে না"},
+	domain.CodeInternal:           {"Something went wrong", "কিছু একটা ভুল হয়েছে"},
+	domain.CodeInvalidCredentials: {"Invalid email or password", "ইমেইল বা পাসওয়ার্ড সঠিক নয়"},
+	domain.CodeInvalidOldPassword: {"Current password is incorrect", "বর্তমান পাসওয়ার্ড সঠিক নয়"},
+	domain.CodeRateLimited:        {"Too many requests", "অনেক বেশি অনুরোধ"},
+	domain.CodeMethodNotAllowed:   {"Method not allowed", "এই পদ্ধতি অনুমোদিত নয়"},
+	domain.CodeTooLarge:           {"Request body too large", "অনুরোধটি অনেক বড়"},
+}
+
+// fieldMessages translates field error codes. English keeps the message the
+// validator wrote, which is usually more specific.
+var fieldMessages = map[string]string{
+	domain.FieldRequired:     "এই তথ্যটি আবশ্যক",
+	domain.FieldInvalid:      "এই তথ্যটি সঠিক নয়",
+	domain.FieldTaken:        "এটি ইতিমধ্যে ব্যবহৃত হচ্ছে",
+	domain.FieldNotFound:     "এটি খুঁজে পাওয়া যায়নি",
+	domain.FieldTooShort:     "এটি খুব ছোট",
+	domain.FieldTooLong:      "এটি খুব বড়",
+	domain.FieldOutOfRange:   "এই মান গ্রহণযোগ্য সীমার বাইরে",
+	domain.FieldNotAllowed:   "এই মান অনুমোদিত নয়",
+	domain.FieldWeakPassword: "পাসওয়ার্ডে অন্তত ৮টি অক্ষর, একটি বর্ণ ও একটি সংখ্যা থাকতে হবে",
+	domain.FieldUnknown:      "এই তথ্যটি গ্রহণযোগ্য নয়",
+}
+
+// languageFromRequest picks Bangla when ?lang=bn is set or Bangla is the
+// client's first choice in Accept-Language, and English otherwise
+func languageFromRequest(r *http.Request) string {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		if lang == langBangla {
+			return langBangla
+		}
+		return langEnglish
+	}
+	accept := strings.ToLower(strings.TrimSpace(r.Header.Get("Accept-Language")))
+	if strings.HasPrefix(accept, langBangla) {
+		return langBangla
+	}
+	return langEnglish
+}
+
+func title(code string, status int, lang string) string {
+	t, ok := titles[code]
+	if !ok {
+		return http.StatusText(status)
+	}
+	if lang == langBangla {
+		return t[1]
+	}
+	return t[0]
+}
+
+func fieldMessage(f domain.FieldError, lang string) string {
+	if lang == langBangla {
+		if msg, ok := fieldMessages[f.Code]; ok {
+			return msg
+		}
+	}
+	return f.Message
+}
@@ -0,0 +1,112 @@
+// Package problem writes every API error as an RFC 9457 problem document
+// (application/problem+json) with a stable code and the request ID.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"news-portal-backend/internal/core/domain"
+	"news-portal-backend/internal/core/port"
+)
+
+const ContentType = "application/problem+json"
+
+// Problem is the error body of every API response. Title is localized from
+// the code, Detail is the error message and only set for client errors.
+type Problem struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail,omitempty"`
+	Instance  string              `json:"instance,omitempty"`
+	Code      string              `json:"code"`
+	RequestID string              `json:"request_id,omitempty"`
+	Errors    []domain.FieldError `json:"errors,omitempty"`
+}
+
+// kinds maps each domain error kind to its status and default code
+var kinds = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{domain.ErrBadRequest, http.StatusBadRequest, domain.CodeBadRequest},
+	{domain.ErrInvalidInput, http.StatusUnprocessableEntity, domain.CodeInvalidInput},
+	{domain.ErrUnauthorized, http.StatusUnauthorized, domain.CodeUnauthorized},
+	{domain.ErrForbidden, http.StatusForbidden, domain.CodeForbidden},
+	{domain.ErrNotFound, http.StatusNotFound, domain.CodeNotFound},
+	{domain.ErrConflict, http.StatusConflict, domain.CodeConflict},
+	{domain.ErrTooLarge, http.StatusRequestEntityTooLarge, domain.CodeTooLarge},
+	{domain.ErrRateLimited, http.StatusTooManyRequests, domain.CodeRateLimited},
+}
+
+// Write maps err to a problem response. Errors that match no domain error
+// kind are logged and reported as a bare 500, so database messages and
+// other internals never reach the client.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	p := Problem{
+		Type:      "about:blank",
+		Status:    http.StatusInternalServerError,
+		Code:      domain.CodeInternal,
+		Instance:  r.URL.Path,
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+
+	for _, k := range kinds {
+		if errors.Is(err, k.err) {
+			p.Status = k.status
+			p.Code = k.code
+			p.Detail = err.Error()
+			break
+		}
+	}
+
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) && p.Status != http.StatusInternalServerError {
+		p.Code = domainErr.Code
+		p.Errors = domainErr.Fields
+	}
+
+	if p.Status == http.StatusInternalServerError {
+		port.Logger(r.Context()).ErrorContext(r.Context(), "Request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+	}
+
+	send(w, r, p)
+}
+
+// New writes a problem for errors raised outside the domain, such as rate
+// limiting or unknown routes
+func New(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	send(w, r, Problem{
+		Type:      "about:blank",
+		Status:    status,
+		Code:      code,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: middleware.GetReqID(r.Context()),
+	})
+}
+
+func send(w http.ResponseWriter, r *http.Request, p Problem) {
+	lang := languageFromRequest(r)
+	p.Title = title(p.Code, p.Status, lang)
+	// The fields belong to the error, which may be shared between requests
+	if p.Errors != nil {
+		errs := make([]domain.FieldError, len(p.Errors))
+		for i, f := range p.Errors {
+			f.Message = fieldMessage(f, lang)
+			errs[i] = f
+		}
+		p.Errors = errs
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
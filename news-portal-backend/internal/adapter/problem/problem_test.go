@@ -0,0 +1,167 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"news-portal-backend/internal/core/domain"
+)
+
+func writeProblem(t *testing.T, r *http.Request, err error) (*httptest.ResponseRecorder, Problem) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Write(rec, r, err)
+
+	var p Problem
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	return rec, p
+}
+
+func TestWriteMapsKinds(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		detail string
+	}{
+		{"bad request", fmt.Errorf("%w: invalid JSON", domain.ErrBadRequest), http.StatusBadRequest, domain.CodeBadRequest, "malformed request: invalid JSON"},
+		{"invalid input", fmt.Errorf("%w: unsupported content type", domain.ErrInvalidInput), http.StatusUnprocessableEntity, domain.CodeInvalidInput, "invalid input: unsupported content type"},
+		{"unauthorized", domain.ErrUnauthorized, http.StatusUnauthorized, domain.CodeUnauthorized, "unauthorized"},
+		{"forbidden", domain.ErrForbidden, http.StatusForbidden, domain.CodeForbidden, "forbidden"},
+		{"not found", domain.NewError(domain.ErrNotFound, domain.CodeNotFound, "News not found"), http.StatusNotFound, domain.CodeNotFound, "News not found"},
+		{"conflict", domain.NewError(domain.ErrConflict, domain.CodeConflict, "still in use"), http.StatusConflict, domain.CodeConflict, "still in use"},
+		{"too large", domain.ErrTooLarge, http.StatusRequestEntityTooLarge, domain.CodeTooLarge, "request body too large"},
+		{"rate limited", domain.ErrTooManyLogins, http.StatusTooManyRequests, domain.CodeRateLimited, domain.ErrTooManyLogins.Message},
+		{"specific code", domain.ErrInvalidCredentials, http.StatusUnauthorized, domain.CodeInvalidCredentials, "invalid credentials"},
+		{"wrong current password", domain.ErrInvalidOldPassword, http.StatusUnprocessableEntity, domain.CodeInvalidOldPassword, "invalid old password"},
+		{"wrapped domain error", fmt.Errorf("login: %w", domain.ErrInvalidCredentials), http.StatusUnauthorized, domain.CodeInvalidCredentials, "login: invalid credentials"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, p := writeProblem(t, httptest.NewRequest(http.MethodGet, "/api/v1/news", nil), tt.err)
+
+			if rec.Code != tt.status || p.Status != tt.status {
+				t.Errorf("status = %d (body %d), want %d", rec.Code, p.Status, tt.status)
+			}
+			if p.Code != tt.code {
+				t.Errorf("code = %q, want %q", p.Code, tt.code)
+			}
+			if p.Detail != tt.detail {
+				t.Errorf("detail = %q, want %q", p.Detail, tt.detail)
+			}
+			if p.Instance != "/api/v1/news" || p.Type != "about:blank" || p.Title == "" {
+				t.Errorf("problem = %+v", p)
+			}
+			if got := rec.Header().Get("Content-Type"); got != ContentType {
+				t.Errorf("Content-Type = %q, want %q", got, ContentType)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+				t.Errorf("Cache-Control = %q, want no-store", got)
+			}
+		})
+	}
+}
+
+// Errors outside the domain may carry SQL or driver messages, so clients
+// only get a generic 500
+func TestWriteHidesInternalErrors(t *testing.T) {
+	tests := []error{
+		errors.New(`pq: relation "news" does not exist`),
+		fmt.Errorf("query: %w", errors.New("dial tcp 10.0.0.5:5432: connection refused")),
+		// A domain error of an unknown kind is still internal
+		domain.NewError(domain.ErrInternal, domain.CodeInternal, "storage bucket misconfigured"),
+	}
+	for _, err := range tests {
+		rec, p := writeProblem(t, httptest.NewRequest(http.MethodGet, "/", nil), err)
+		if rec.Code != http.StatusInternalServerError || p.Code != domain.CodeInternal {
+			t.Errorf("%v: status %d, code %q", err, rec.Code, p.Code)
+		}
+		if p.Detail != "" || p.Errors != nil {
+			t.Errorf("%v: leaked detail %q, errors %v", err, p.Detail, p.Errors)
+		}
+	}
+}
+
+func TestWriteValidationError(t *testing.T) {
+	err := domain.NewValidationError(
+		domain.FieldError{Field: "title", Code: domain.FieldRequired, Message: "title is required"},
+		domain.FieldError{Field: "category_id", Code: domain.FieldNotFound, Message: "category_id does not exist"},
+	)
+
+	rec, p := writeProblem(t, httptest.NewRequest(http.MethodPost, "/api/v1/news", nil), err)
+	if rec.Code != http.StatusUnprocessableEntity || p.Code != domain.CodeValidationFailed {
+		t.Fatalf("status %d, code %q", rec.Code, p.Code)
+	}
+	if p.Title != "Some fields are invalid" {
+		t.Errorf("title = %q", p.Title)
+	}
+	if !reflect.DeepEqual(p.Errors, err.Fields) {
+		t.Errorf("errors = %+v, want %+v", p.Errors, err.Fields)
+	}
+}
+
+func TestWriteLocalizes(t *testing.T) {
+	field := domain.FieldError{Field: "title", Code: domain.FieldRequired, Message: "title is required"}
+	err := domain.NewValidationError(field)
+
+	tests := []struct {
+		name           string
+		query, accept  string
+		title, message string
+	}{
+		{"default", "", "", "Some fields are invalid", "title is required"},
+		{"Accept-Language", "", "bn-BD,bn;q=0.9,en;q=0.8", "কিছু তথ্য সঠিক নয়", "এই তথ্যটি আবশ্যক"},
+		{"English preferred", "", "en-US,bn;q=0.5", "Some fields are invalid", "title is required"},
+		{"query overrides header", "?lang=en", "bn", "Some fields are invalid", "title is required"},
+		{"query", "?lang=bn", "", "কিছু তথ্য সঠিক নয়", "এই তথ্যটি আবশ্যক"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/news"+tt.query, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept-Language", tt.accept)
+			}
+			_, p := writeProblem(t, r, err)
+			if p.Title != tt.title {
+				t.Errorf("title = %q, want %q", p.Title, tt.title)
+			}
+			if len(p.Errors) != 1 || p.Errors[0].Message != tt.message {
+				t.Errorf("errors = %+v, want message %q", p.Errors, tt.message)
+			}
+		})
+	}
+
+	// Localizing a response must not rewrite the error itself
+	if err.Fields[0] != field {
+		t.Errorf("error fields were modified: %+v", err.Fields[0])
+	}
+}
+
+func TestNew(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/categories", nil), http.StatusMethodNotAllowed, domain.CodeMethodNotAllowed, "DELETE is not allowed here")
+
+	var p Problem
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	want := Problem{
+		Type:     "about:blank",
+		Title:    "Method not allowed",
+		Status:   http.StatusMethodNotAllowed,
+		Detail:   "DELETE is not allowed here",
+		Instance: "/api/v1/categories",
+		Code:     domain.CodeMethodNotAllowed,
+	}
+	if rec.Code != http.StatusMethodNotAllowed || !reflect.DeepEqual(p, want) {
+		t.Errorf("got %d %+v, want %+v", rec.Code, p, want)
+	}
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -36,7 +37,7 @@ func (a *Adapter) CreateOwner(ctx context.Context, name, email, passwordHash str
 		PasswordHash: passwordHash,
 	})
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	return &domain.Owner{
 		ID:        owner.ID,
@@ -110,7 +111,7 @@ func (a *Adapter) UpdateOwnerPassword(ctx context.Context, id uuid.UUID, passwor
 	query := `UPDATE owners SET password_hash = $2, updated_at = NOW() WHERE id = $1`
 	tag, err := a.db.Exec(ctx, query, id, passwordHash)
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 	if tag.RowsAffected() == 0 {
 		return domain.ErrNotFound
@@ -124,7 +125,7 @@ func (a *Adapter) CreateCategory(ctx context.Context, category *domain.Category)
 	query := `INSERT INTO categories (name, name_bn, slug, description) VALUES ($1, $2, $3, $4) RETURNING id, created_at`
 	err := a.db.QueryRow(ctx, query, category.Name, category.NameBN, category.Slug, category.Description).Scan(&category.ID, &category.CreatedAt)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	return category, nil
 }
@@ -152,7 +153,7 @@ func (a *Adapter) UpdateCategory(ctx context.Context, category *domain.Category)
 	query := `UPDATE categories SET name = $2, name_bn = $3, slug = $4, description = $5 WHERE id = $1`
 	tag, err := a.db.Exec(ctx, query, category.ID, category.Name, category.NameBN, category.Slug, category.Description)
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 	if tag.RowsAffected() == 0 {
 		return domain.ErrNotFound
@@ -163,7 +164,7 @@ func (a *Adapter) UpdateCategory(ctx context.Context, category *domain.Category)
 func (a *Adapter) DeleteCategory(ctx context.Context, id uuid.UUID) error {
 	tag, err := a.db.Exec(ctx, "DELETE FROM categories WHERE id = $1", id)
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 	if tag.RowsAffected() == 0 {
 		return domain.ErrNotFound
@@ -202,7 +203,7 @@ func (a *Adapter) GetCategoryBySlug(ctx context.Context, slug string) (*domain.C
 func (a *Adapter) CreateNews(ctx context.Context, news *domain.News) (*domain.News, error) {
 	tx, err := a.pool.Begin(ctx)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	defer tx.Rollback(ctx)
 
@@ -210,20 +211,26 @@ func (a *Adapter) CreateNews(ctx context.Context, news *domain.News) (*domain.Ne
 		// Demote others
 		_, err = tx.Exec(ctx, "UPDATE news SET is_featured = FALSE WHERE is_featured = TRUE")
 		if err != nil {
-			return nil, err
+			return nil, translateError(err)
 		}
 	}
 
-	query := `INSERT INTO news (author_id, category_id, title, excerpt, content, thumbnail, slug, is_featured, published_at, status, meta_title, meta_description)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), 'published', $9, $10) RETURNING id, published_at, created_at, updated_at`
-	err = tx.QueryRow(ctx, query, news.AuthorID, news.CategoryID, news.Title, news.Excerpt, news.Content, news.Thumbnail, news.Slug, news.IsFeatured, news.MetaTitle, news.MetaDescription).
+	thumb := newThumbnailColumns(news.ThumbnailInfo)
+	meta := thumbnailMetaOrEmpty(news.ThumbnailMeta)
+	query := `INSERT INTO news (author_id, category_id, title, excerpt, content, thumbnail, slug, is_featured, published_at, status, meta_title, meta_description,
+	                            thumbnail_width, thumbnail_height, thumbnail_blurhash, thumbnail_color,
+	                            thumbnail_alt, thumbnail_caption, thumbnail_credit, thumbnail_source_url)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), 'published', $9, $10, $11, $12, $13, $14, $15, $16, $17, $18) RETURNING id, published_at, created_at, updated_at`
+	err = tx.QueryRow(ctx, query, news.AuthorID, news.CategoryID, news.Title, news.Excerpt, news.Content, news.Thumbnail, news.Slug, news.IsFeatured, news.MetaTitle, news.MetaDescription,
+		thumb.width, thumb.height, thumb.blurHash, thumb.color,
+		meta.Alt, meta.Caption, meta.Credit, meta.SourceURL).
 		Scan(&news.ID, &news.PublishedAt, &news.CreatedAt, &news.UpdatedAt)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	return news, nil
@@ -232,7 +239,7 @@ func (a *Adapter) CreateNews(ctx context.Context, news *domain.News) (*domain.Ne
 func (a *Adapter) UpdateNews(ctx context.Context, news *domain.News) error {
 	tx, err := a.pool.Begin(ctx)
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 	defer tx.Rollback(ctx)
 
@@ -240,14 +247,26 @@ func (a *Adapter) UpdateNews(ctx context.Context, news *domain.News) error {
 		// Demote others
 		_, err = tx.Exec(ctx, "UPDATE news SET is_featured = FALSE WHERE is_featured = TRUE AND id != $1", news.ID)
 		if err != nil {
-			return err
+			return translateError(err)
 		}
 	}
 
-	query := `UPDATE news SET category_id = $2, title = $3, excerpt = $4, content = $5, thumbnail = $6, is_featured = $7, updated_at = NOW() WHERE id = $1`
-	tag, err := tx.Exec(ctx, query, news.ID, news.CategoryID, news.Title, news.Excerpt, news.Content, news.Thumbnail, news.IsFeatured)
+	// Thumbnail info is only replaced when a new image was analyzed or the
+	// thumbnail itself changed; otherwise the stored values still apply.
+	thumb := newThumbnailColumns(news.ThumbnailInfo)
+	meta := thumbnailMetaOrEmpty(news.ThumbnailMeta)
+	query := `UPDATE news SET category_id = $2, title = $3, excerpt = $4, content = $5, thumbnail = $6, is_featured = $7,
+	          thumbnail_alt = $12, thumbnail_caption = $13, thumbnail_credit = $14, thumbnail_source_url = $15,
+	          thumbnail_width = CASE WHEN $8::int IS NOT NULL OR thumbnail <> $6 THEN $8 ELSE thumbnail_width END,
+	          thumbnail_height = CASE WHEN $8::int IS NOT NULL OR thumbnail <> $6 THEN $9 ELSE thumbnail_height END,
+	          thumbnail_blurhash = CASE WHEN $8::int IS NOT NULL OR thumbnail <> $6 THEN $10 ELSE thumbnail_blurhash END,
+	          thumbnail_color = CASE WHEN $8::int IS NOT NULL OR thumbnail <> $6 THEN $11 ELSE thumbnail_color END,
+	          updated_at = NOW() WHERE id = $1`
+	tag, err := tx.Exec(ctx, query, news.ID, news.CategoryID, news.Title, news.Excerpt, news.Content, news.Thumbnail, news.IsFeatured,
+		thumb.width, thumb.height, thumb.blurHash, thumb.color,
+		meta.Alt, meta.Caption, meta.Credit, meta.SourceURL)
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 	if tag.RowsAffected() == 0 {
 		return domain.ErrNotFound
@@ -259,7 +278,7 @@ func (a *Adapter) UpdateNews(ctx context.Context, news *domain.News) error {
 func (a *Adapter) DeleteNews(ctx context.Context, id uuid.UUID) error {
 	tag, err := a.q.DeleteNews(ctx, id)
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 	if tag.RowsAffected() == 0 {
 		return domain.ErrNotFound
@@ -268,17 +287,41 @@ func (a *Adapter) DeleteNews(ctx context.Context, id uuid.UUID) error {
 }
 
 func (a *Adapter) GetNewsBySlug(ctx context.Context, slug string) (*domain.News, error) {
-	query := `SELECT n.id, n.author_id, n.category_id, n.title, n.excerpt, n.content, n.thumbnail, n.slug, n.status, n.is_featured, n.meta_title, n.meta_description, n.views_count, n.published_at, n.created_at, n.updated_at,
+	return a.getNews(ctx, "n.slug = $1", slug)
+}
+
+func (a *Adapter) GetNewsByID(ctx context.Context, id uuid.UUID) (*domain.News, error) {
+	return a.getNews(ctx, "n.id = $1", id)
+}
+
+func (a *Adapter) GetNewsViewsCount(ctx context.Context, id uuid.UUID) (int64, error) {
+	var views int64
+	err := a.db.QueryRow(ctx, "SELECT views_count FROM news WHERE id = $1", id).Scan(&views)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return views, err
+}
+
+// getNews loads one article with its joined fields; where filters on $1
+func (a *Adapter) getNews(ctx context.Context, where string, arg any) (*domain.News, error) {
+	query := `SELECT n.id, n.author_id, n.category_id, n.title, n.excerpt, n.content, n.thumbnail, n.slug, n.status, n.is_featured, n.meta_title, n.meta_description, n.views_count, n.raw_views_count, n.published_at, n.created_at, n.updated_at,
+	                 n.thumbnail_width, n.thumbnail_height, n.thumbnail_blurhash, n.thumbnail_color,
+	                 n.thumbnail_alt, n.thumbnail_caption, n.thumbnail_credit, n.thumbnail_source_url,
 	                 c.name as category_name, c.slug as category_slug, o.name as author_name
 	          FROM news n
 	          LEFT JOIN categories c ON n.category_id = c.id
 	          LEFT JOIN owners o ON n.author_id = o.id
-	          WHERE n.slug = $1 LIMIT 1`
+	          WHERE ` + where + ` LIMIT 1`
 
 	n := &domain.News{}
 	var authorID, categoryID uuid.UUID
-	err := a.db.QueryRow(ctx, query, slug).Scan(
-		&n.ID, &authorID, &categoryID, &n.Title, &n.Excerpt, &n.Content, &n.Thumbnail, &n.Slug, &n.Status, &n.IsFeatured, &n.MetaTitle, &n.MetaDescription, &n.ViewsCount, &n.PublishedAt, &n.CreatedAt, &n.UpdatedAt,
+	var thumb thumbnailColumns
+	meta := &domain.ThumbnailMeta{}
+	err := a.db.QueryRow(ctx, query, arg).Scan(
+		&n.ID, &authorID, &categoryID, &n.Title, &n.Excerpt, &n.Content, &n.Thumbnail, &n.Slug, &n.Status, &n.IsFeatured, &n.MetaTitle, &n.MetaDescription, &n.ViewsCount, &n.RawViewsCount, &n.PublishedAt, &n.CreatedAt, &n.UpdatedAt,
+		&thumb.width, &thumb.height, &thumb.blurHash, &thumb.color,
+		&meta.Alt, &meta.Caption, &meta.Credit, &meta.SourceURL,
 		&n.CategoryName, &n.CategorySlug, &n.AuthorName,
 	)
 	if err != nil {
@@ -289,16 +332,40 @@ func (a *Adapter) GetNewsBySlug(ctx context.Context, slug string) (*domain.News,
 	}
 	n.AuthorID = authorID
 	n.CategoryID = categoryID
+	n.ThumbnailInfo = thumb.imageInfo()
+	n.ThumbnailMeta = meta
 	return n, nil
 }
 
 func (a *Adapter) ListNews(ctx context.Context, limit, offset int32, categoryID *uuid.UUID, authorID *uuid.UUID, sortBy string, isFeatured *bool, search string) ([]*domain.News, error) {
+	newsList := []*domain.News{}
+	err := a.queryNewsList(ctx, &limit, offset, categoryID, authorID, sortBy, isFeatured, search, func(n *domain.News) error {
+		newsList = append(newsList, n)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newsList, nil
+}
+
+// StreamNews passes every article matching the ListNews filters to fn as it
+// is read, without holding the result set in memory
+func (a *Adapter) StreamNews(ctx context.Context, categoryID *uuid.UUID, authorID *uuid.UUID, sortBy string, isFeatured *bool, search string, fn func(*domain.News) error) error {
+	return a.queryNewsList(ctx, nil, 0, categoryID, authorID, sortBy, isFeatured, search, fn)
+}
+
+// queryNewsList runs the published-article listing query. A nil limit
+// returns every matching row.
+func (a *Adapter) queryNewsList(ctx context.Context, limit *int32, offset int32, categoryID *uuid.UUID, authorID *uuid.UUID, sortBy string, isFeatured *bool, search string, fn func(*domain.News) error) error {
 	orderBy := "n.published_at DESC"
 	switch sortBy {
 	case "popular", "views_desc":
 		orderBy = "n.views_count DESC, n.published_at DESC"
 	case "views_asc":
 		orderBy = "n.views_count ASC, n.published_at DESC"
+	case "trending":
+		orderBy = "n.trending_score DESC, n.published_at DESC"
 	case "oldest":
 		orderBy = "n.published_at ASC"
 	case "latest":
@@ -311,7 +378,9 @@ func (a *Adapter) ListNews(ctx context.Context, limit, offset int32, categoryID
 		searchPtr = &pattern
 	}
 
-	query := `SELECT n.id, n.title, n.thumbnail, n.slug, n.status, n.is_featured, n.views_count, n.published_at, n.created_at, n.updated_at,
+	query := `SELECT n.id, n.title, n.thumbnail, n.slug, n.status, n.is_featured, n.views_count, n.raw_views_count, n.published_at, n.created_at, n.updated_at,
+	                 n.thumbnail_width, n.thumbnail_height, n.thumbnail_blurhash, n.thumbnail_color,
+	                 n.thumbnail_alt, n.thumbnail_caption, n.thumbnail_credit, n.thumbnail_source_url,
 	                 c.name as category_name, c.slug as category_slug, o.name as author_name
 	          FROM news n
 	          LEFT JOIN categories c ON n.category_id = c.id
@@ -325,30 +394,63 @@ func (a *Adapter) ListNews(ctx context.Context, limit, offset int32, categoryID
 
 	rows, err := a.db.Query(ctx, query, limit, offset, categoryID, isFeatured, searchPtr, authorID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	newsList := []*domain.News{}
 	for rows.Next() {
 		n := &domain.News{}
+		var thumb thumbnailColumns
+		meta := &domain.ThumbnailMeta{}
 		if err := rows.Scan(
-			&n.ID, &n.Title, &n.Thumbnail, &n.Slug, &n.Status, &n.IsFeatured, &n.ViewsCount, &n.PublishedAt, &n.CreatedAt, &n.UpdatedAt,
+			&n.ID, &n.Title, &n.Thumbnail, &n.Slug, &n.Status, &n.IsFeatured, &n.ViewsCount, &n.RawViewsCount, &n.PublishedAt, &n.CreatedAt, &n.UpdatedAt,
+			&thumb.width, &thumb.height, &thumb.blurHash, &thumb.color,
+			&meta.Alt, &meta.Caption, &meta.Credit, &meta.SourceURL,
 			&n.CategoryName, &n.CategorySlug, &n.AuthorName,
 		); err != nil {
-			return nil, err
+			return err
+		}
+		n.ThumbnailInfo = thumb.imageInfo()
+		n.ThumbnailMeta = meta
+		if err := fn(n); err != nil {
+			return err
 		}
-		newsList = append(newsList, n)
 	}
-	return newsList, nil
+	return rows.Err()
 }
 
 func (a *Adapter) CheckSlugExists(ctx context.Context, slug string) (bool, error) {
 	return a.q.CheckSlugExists(ctx, slug)
 }
 
-func (a *Adapter) IncrementNewsViews(ctx context.Context, slug string) error {
-	return a.q.IncrementNewsViews(ctx, slug)
+func (a *Adapter) AddNewsViews(ctx context.Context, counts map[port.ViewKey]port.ViewDelta) error {
+	ids := make([]uuid.UUID, 0, len(counts))
+	hours := make([]time.Time, 0, len(counts))
+	raw := make([]int64, 0, len(counts))
+	unique := make([]int64, 0, len(counts))
+	for key, delta := range counts {
+		ids = append(ids, key.NewsID)
+		hours = append(hours, key.Hour)
+		raw = append(raw, delta.Raw)
+		unique = append(unique, delta.Unique)
+	}
+
+	// One statement updates the lifetime totals and the hourly buckets
+	query := `WITH v AS (
+	              SELECT UNNEST($1::uuid[]) AS id, UNNEST($2::timestamptz[]) AS bucket,
+	                     UNNEST($3::bigint[]) AS raw_views, UNNEST($4::bigint[]) AS unique_views
+	          ), totals AS (
+	              UPDATE news SET views_count = views_count + t.unique_views, raw_views_count = raw_views_count + t.raw_views
+	              FROM (SELECT id, SUM(raw_views) AS raw_views, SUM(unique_views) AS unique_views FROM v GROUP BY id) t
+	              WHERE news.id = t.id
+	              RETURNING news.id
+	          )
+	          INSERT INTO news_views_hourly (news_id, bucket, views, raw_views)
+	          SELECT v.id, v.bucket, v.unique_views, v.raw_views FROM v JOIN totals ON totals.id = v.id
+	          ON CONFLICT (news_id, bucket) DO UPDATE
+	          SET views = news_views_hourly.views + EXCLUDED.views, raw_views = news_views_hourly.raw_views + EXCLUDED.raw_views`
+	_, err := a.db.Exec(ctx, query, ids, hours, raw, unique)
+	return err
 }
 
 // Ensure interface implementation
@@ -0,0 +1,249 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"news-portal-backend/internal/core/port"
+)
+
+// AnalyticsRepository implementation
+
+// periodViewsCTE sums each article's views between $1 and $2 from hourly
+// buckets and from days that have already been rolled up. Rolled-up days
+// count when they start within the period.
+const periodViewsCTE = `period_views AS (
+	SELECT news_id, SUM(views) AS views, SUM(raw_views) AS raw_views FROM (
+		SELECT news_id, views, raw_views FROM news_views_hourly
+		WHERE bucket >= $1 AND bucket < $2
+		UNION ALL
+		SELECT news_id, views, raw_views FROM news_views_daily
+		WHERE day::timestamp AT TIME ZONE 'UTC' >= $1 AND day::timestamp AT TIME ZONE 'UTC' < $2
+	) v
+	GROUP BY news_id
+)`
+
+// GetViewTimeSeries returns one point per bucket between From and To, with
+// empty buckets filled with zeros. Daily series combine rolled-up daily rows
+// with hourly rows that haven't been compacted yet. Buckets are in UTC.
+func (a *Adapter) GetViewTimeSeries(ctx context.Context, filter port.TimeSeriesFilter) ([]port.TimeSeriesPoint, error) {
+	step := "1 hour"
+	if filter.Granularity == port.GranularityDay {
+		step = "1 day"
+	}
+
+	query := `WITH series AS (
+	              SELECT generate_series(date_trunc($1, $2::timestamptz, 'UTC'), $3::timestamptz - INTERVAL '1 microsecond', $4::interval) AS bucket
+	          ), data AS (
+	              SELECT date_trunc($1, h.bucket, 'UTC') AS bucket, h.views, h.raw_views
+	              FROM news_views_hourly h
+	              JOIN news n ON n.id = h.news_id
+	              WHERE h.bucket >= $2 AND h.bucket < $3
+	              AND ($5::uuid IS NULL OR h.news_id = $5)
+	              AND ($6::uuid IS NULL OR n.category_id = $6)
+	              UNION ALL
+	              SELECT d.day::timestamp AT TIME ZONE 'UTC', d.views, d.raw_views
+	              FROM news_views_daily d
+	              JOIN news n ON n.id = d.news_id
+	              WHERE $1 = 'day'
+	              AND d.day >= ($2::timestamptz AT TIME ZONE 'UTC')::date AND d.day < ($3::timestamptz AT TIME ZONE 'UTC')::date
+	              AND ($5::uuid IS NULL OR d.news_id = $5)
+	              AND ($6::uuid IS NULL OR n.category_id = $6)
+	          )
+	          SELECT s.bucket, COALESCE(SUM(d.views), 0), COALESCE(SUM(d.raw_views), 0)
+	          FROM series s
+	          LEFT JOIN data d ON d.bucket = s.bucket
+	          GROUP BY s.bucket
+	          ORDER BY s.bucket`
+
+	rows, err := a.db.Query(ctx, query, filter.Granularity, filter.From, filter.To, step, filter.NewsID, filter.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []port.TimeSeriesPoint{}
+	for rows.Next() {
+		var p port.TimeSeriesPoint
+		if err := rows.Scan(&p.Bucket, &p.Views, &p.RawViews); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// RollupHourlyViews moves hourly buckets older than before into daily rows.
+// before is truncated to a UTC day so a day never spans both tables. The
+// rows are deleted and summed in one statement, so when replicas roll up
+// together each bucket is counted by whichever run deleted it.
+func (a *Adapter) RollupHourlyViews(ctx context.Context, before time.Time) (int64, error) {
+	cutoff := before.UTC().Truncate(24 * time.Hour)
+
+	query := `WITH moved AS (
+	              DELETE FROM news_views_hourly
+	              WHERE bucket < $1
+	              RETURNING news_id, bucket, views, raw_views
+	          ), rolled AS (
+	              INSERT INTO news_views_daily (news_id, day, views, raw_views)
+	              SELECT news_id, (bucket AT TIME ZONE 'UTC')::date, SUM(views), SUM(raw_views)
+	              FROM moved
+	              GROUP BY news_id, (bucket AT TIME ZONE 'UTC')::date
+	              ON CONFLICT (news_id, day) DO UPDATE
+	              SET views = news_views_daily.views + EXCLUDED.views, raw_views = news_views_daily.raw_views + EXCLUDED.raw_views
+	          )
+	          SELECT COUNT(*) FROM moved`
+
+	var moved int64
+	if err := a.db.QueryRow(ctx, query, cutoff).Scan(&moved); err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+func (a *Adapter) RefreshTrendingScores(ctx context.Context, halfLife, window time.Duration) error {
+	// Articles that dropped out of the window are reset to zero; untouched
+	// rows are skipped so the statement stays cheap on a large table.
+	query := `WITH scores AS (
+	              SELECT news_id, SUM(views * POWER(2, -EXTRACT(EPOCH FROM (NOW() - bucket)) / $1)) AS score
+	              FROM news_views_hourly
+	              WHERE bucket >= NOW() - $2 * INTERVAL '1 second'
+	              GROUP BY news_id
+	          ), targets AS (
+	              SELECT id FROM news WHERE trending_score > 0
+	              UNION
+	              SELECT news_id FROM scores
+	          )
+	          UPDATE news n SET trending_score = COALESCE(s.score, 0)
+	          FROM targets t
+	          LEFT JOIN scores s ON s.news_id = t.id
+	          WHERE n.id = t.id`
+	_, err := a.db.Exec(ctx, query, halfLife.Seconds(), window.Seconds())
+	return err
+}
+
+// GetPeriodStats counts the articles published and the views recorded in
+// the period in a single round trip
+func (a *Adapter) GetPeriodStats(ctx context.Context, filter port.StatsFilter) (*port.PeriodStats, error) {
+	query := `WITH ` + periodViewsCTE + `
+	          SELECT
+	              (SELECT COUNT(*) FROM news n
+	               WHERE n.status = 'published' AND n.published_at >= $1 AND n.published_at < $2
+	               AND ($3::uuid IS NULL OR n.category_id = $3)
+	               AND ($4::uuid IS NULL OR n.author_id = $4)),
+	              COALESCE(SUM(pv.views), 0), COALESCE(SUM(pv.raw_views), 0)
+	          FROM period_views pv
+	          JOIN news n ON n.id = pv.news_id
+	          WHERE ($3::uuid IS NULL OR n.category_id = $3)
+	          AND ($4::uuid IS NULL OR n.author_id = $4)`
+
+	stats := port.PeriodStats{From: filter.From, To: filter.To}
+	err := a.db.QueryRow(ctx, query, filter.From, filter.To, filter.CategoryID, filter.AuthorID).
+		Scan(&stats.ArticlesPublished, &stats.Views, &stats.RawViews)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetAuthorStats returns, per author, the articles published in the period
+// and the views those articles received within it, most productive first.
+// Authors who published nothing in the period are left out.
+func (a *Adapter) GetAuthorStats(ctx context.Context, filter port.StatsFilter) ([]port.AuthorStat, error) {
+	query := `WITH ` + periodViewsCTE + `
+	          SELECT o.id, o.name, COUNT(n.id), COALESCE(SUM(pv.views), 0)
+	          FROM news n
+	          JOIN owners o ON o.id = n.author_id
+	          LEFT JOIN period_views pv ON pv.news_id = n.id
+	          WHERE n.status = 'published' AND n.published_at >= $1 AND n.published_at < $2
+	          AND ($3::uuid IS NULL OR n.category_id = $3)
+	          AND ($4::uuid IS NULL OR n.author_id = $4)
+	          GROUP BY o.id, o.name
+	          ORDER BY COUNT(n.id) DESC, COALESCE(SUM(pv.views), 0) DESC`
+
+	rows, err := a.db.Query(ctx, query, filter.From, filter.To, filter.CategoryID, filter.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []port.AuthorStat{}
+	for rows.Next() {
+		var s port.AuthorStat
+		if err := rows.Scan(&s.ID, &s.Name, &s.ArticlesPublished, &s.Views); err != nil {
+			return nil, err
+		}
+		if s.ArticlesPublished > 0 {
+			s.AvgViewsPerArticle = float64(s.Views) / float64(s.ArticlesPublished)
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// AddTrafficCounts adds a batch of daily traffic counters. Counters for
+// articles deleted since the view are skipped.
+func (a *Adapter) AddTrafficCounts(ctx context.Context, counts map[port.TrafficKey]int64) error {
+	ids := make([]uuid.UUID, 0, len(counts))
+	days := make([]time.Time, 0, len(counts))
+	dimensions := make([]string, 0, len(counts))
+	values := make([]string, 0, len(counts))
+	n := make([]int64, 0, len(counts))
+	for key, count := range counts {
+		ids = append(ids, key.NewsID)
+		days = append(days, key.Day)
+		dimensions = append(dimensions, key.Dimension)
+		values = append(values, key.Value)
+		n = append(n, count)
+	}
+
+	query := `INSERT INTO news_traffic_daily (news_id, day, dimension, value, count)
+	          SELECT t.id, (t.day AT TIME ZONE 'UTC')::date, t.dimension, t.value, t.count
+	          FROM UNNEST($1::uuid[], $2::timestamptz[], $3::text[], $4::text[], $5::bigint[]) AS t(id, day, dimension, value, count)
+	          JOIN news ON news.id = t.id
+	          ON CONFLICT (news_id, day, dimension, value) DO UPDATE
+	          SET count = news_traffic_daily.count + EXCLUDED.count`
+	_, err := a.db.Exec(ctx, query, ids, days, dimensions, values, n)
+	return err
+}
+
+// GetTrafficBreakdown returns the top values of every traffic dimension
+// on the UTC days that overlap From and To, keyed by dimension. An article,
+// category or author narrows it to the matching articles' traffic.
+func (a *Adapter) GetTrafficBreakdown(ctx context.Context, filter port.TrafficFilter) (map[string][]port.TrafficStat, error) {
+	query := `SELECT dimension, value, total FROM (
+	              SELECT d.dimension, d.value, SUM(d.count) AS total,
+	                     ROW_NUMBER() OVER (PARTITION BY d.dimension ORDER BY SUM(d.count) DESC, d.value) AS rank
+	              FROM news_traffic_daily d
+	              JOIN news n ON n.id = d.news_id
+	              WHERE d.day >= ($1::timestamptz AT TIME ZONE 'UTC')::date
+	              AND d.day <= (($2::timestamptz - INTERVAL '1 microsecond') AT TIME ZONE 'UTC')::date
+	              AND ($3::uuid IS NULL OR d.news_id = $3)
+	              AND ($5::uuid IS NULL OR n.category_id = $5)
+	              AND ($6::uuid IS NULL OR n.author_id = $6)
+	              GROUP BY d.dimension, d.value
+	          ) t
+	          WHERE rank <= $4
+	          ORDER BY dimension, total DESC, value`
+
+	rows, err := a.db.Query(ctx, query, filter.From, filter.To, filter.NewsID, filter.Limit, filter.CategoryID, filter.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	breakdown := make(map[string][]port.TrafficStat)
+	for rows.Next() {
+		var dimension string
+		var stat port.TrafficStat
+		if err := rows.Scan(&dimension, &stat.Value, &stat.Count); err != nil {
+			return nil, err
+		}
+		breakdown[dimension] = append(breakdown[dimension], stat)
+	}
+	return breakdown, rows.Err()
+}
+
+var _ port.AnalyticsRepository = (*Adapter)(nil)
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testNews inserts an article to hang view rows off, skipping the test when
+// the schema hasn't been migrated
+func testNews(t *testing.T, a *Adapter) string {
+	t.Helper()
+	ctx := context.Background()
+
+	var exists bool
+	if err := a.db.QueryRow(ctx, `SELECT to_regclass('news_views_hourly') IS NOT NULL`).Scan(&exists); err != nil {
+		t.Fatal(err)
+	}
+	if !exists {
+		t.Skip("schema is not migrated")
+	}
+
+	slug := fmt.Sprintf("rollup-test-%d", time.Now().UnixNano())
+	var id string
+	err := a.db.QueryRow(ctx, `INSERT INTO news (title, slug, content, thumbnail) VALUES ($1, $1, '', '') RETURNING id`, slug).Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.db.Exec(ctx, `DELETE FROM news WHERE id = $1`, id) })
+	return id
+}
+
+func TestRollupHourlyViewsCountsOnce(t *testing.T) {
+	a := testAdapter(t)
+	ctx := context.Background()
+	newsID := testNews(t, a)
+
+	// Two buckets on one day and one on the next, all long past any retention
+	day := time.Date(2001, 3, 10, 0, 0, 0, 0, time.UTC)
+	for _, b := range []struct {
+		bucket     time.Time
+		views, raw int64
+	}{
+		{day.Add(1 * time.Hour), 3, 5},
+		{day.Add(23 * time.Hour), 4, 4},
+		{day.Add(25 * time.Hour), 2, 6},
+	} {
+		_, err := a.db.Exec(ctx, `INSERT INTO news_views_hourly (news_id, bucket, views, raw_views) VALUES ($1, $2, $3, $4)`,
+			newsID, b.bucket, b.views, b.raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Replicas rolling up together, then a later run over the same window
+	before := day.Add(72 * time.Hour)
+	var mu sync.Mutex
+	var moved int64
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := a.RollupHourlyViews(ctx, before)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			moved += n
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	n, err := a.RollupHourlyViews(ctx, before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved += n
+
+	if moved != 3 {
+		t.Errorf("moved %d rows, want 3", moved)
+	}
+
+	rows, err := a.db.Query(ctx, `SELECT day, views, raw_views FROM news_views_daily WHERE news_id = $1 ORDER BY day`, newsID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	type dayViews struct {
+		day        time.Time
+		views, raw int64
+	}
+	var got []dayViews
+	for rows.Next() {
+		var d dayViews
+		if err := rows.Scan(&d.day, &d.views, &d.raw); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, d)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []dayViews{{day, 7, 9}, {day.AddDate(0, 0, 1), 2, 6}}
+	if len(got) != len(want) {
+		t.Fatalf("got %d daily rows, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].day.Equal(want[i].day) || got[i].views != want[i].views || got[i].raw != want[i].raw {
+			t.Errorf("day %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
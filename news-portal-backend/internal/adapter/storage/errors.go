@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"news-portal-backend/internal/core/domain"
+)
+
+// Postgres error codes we translate (class 23, integrity constraint
+// violation, and the one class 22 error input length can cause)
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgNotNullViolation    = "23502"
+	pgCheckViolation      = "23514"
+	pgStringTooLong       = "22001"
+)
+
+// constraintFields names the input field behind constraints whose column
+// isn't what the client sent, such as slugs derived from a title or name
+var constraintFields = map[string]string{
+	"categories_slug_key": "name",
+	"news_slug_key":       "title",
+}
+
+// referencingNouns names the tables that can block a delete the way clients
+// know them, so conflict messages don't expose the schema
+var referencingNouns = map[string]string{
+	"news":             "articles",
+	"media":            "media files",
+	"report_schedules": "report schedules",
+}
+
+// translateError turns constraint violations into domain errors that name
+// the offending field. Every other error is returned unchanged.
+func translateError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	field := constraintField(pgErr)
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		return &domain.Error{
+			Kind:    domain.ErrConflict,
+			Code:    domain.CodeConflict,
+			Message: fmt.Sprintf("%s is already in use", field),
+			Fields:  []domain.FieldError{{Field: field, Code: domain.FieldTaken, Message: fmt.Sprintf("%s is already in use", field)}},
+		}
+	case pgForeignKeyViolation:
+		// Deleting a row that others still point at, rather than pointing
+		// at a row that doesn't exist
+		if strings.HasPrefix(pgErr.Message, "update or delete") {
+			if noun, ok := referencingNouns[pgErr.TableName]; ok {
+				return domain.NewError(domain.ErrConflict, domain.CodeConflict, fmt.Sprintf("still referenced by %s", noun))
+			}
+			return domain.NewError(domain.ErrConflict, domain.CodeConflict, "still in use")
+		}
+		return domain.NewValidationError(domain.FieldError{Field: field, Code: domain.FieldNotFound, Message: fmt.Sprintf("%s does not exist", field)})
+	case pgNotNullViolation:
+		return domain.NewValidationError(domain.FieldError{Field: field, Code: domain.FieldRequired, Message: fmt.Sprintf("%s is required", field)})
+	case pgCheckViolation:
+		return domain.NewValidationError(domain.FieldError{Field: field, Code: domain.FieldInvalid, Message: fmt.Sprintf("%s is invalid", field)})
+	case pgStringTooLong:
+		// Postgres doesn't say which column overflowed
+		return fmt.Errorf("%w: a value is too long", domain.ErrInvalidInput)
+	}
+	return err
+}
+
+// constraintField works out the field from the constraint name Postgres
+// generated, e.g. owners_email_key or news_category_id_fkey
+func constraintField(pgErr *pgconn.PgError) string {
+	if field, ok := constraintFields[pgErr.ConstraintName]; ok {
+		return field
+	}
+	if pgErr.ColumnName != "" {
+		return pgErr.ColumnName
+	}
+	name := strings.TrimPrefix(pgErr.ConstraintName, pgErr.TableName+"_")
+	for _, suffix := range []string{"_key", "_fkey", "_check", "_not_null"} {
+		if trimmed, ok := strings.CutSuffix(name, suffix); ok {
+			return trimmed
+		}
+	}
+	if name == "" {
+		return "value"
+	}
+	return name
+}
+
+// unavailable reports whether err means the database couldn't serve the
+// query at all: no connection, a timeout, or a server that is shutting down
+// or out of resources. Errors about the query itself are not.
+func unavailable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return true
+	}
+	switch pgErr.Code[:2] {
+	case "08", "53", "57": // connection exception, insufficient resources, operator intervention
+		return true
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"news-portal-backend/internal/core/domain"
+)
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     *pgconn.PgError
+		kind    error
+		message string
+		fields  []domain.FieldError
+	}{
+		{
+			"unique email",
+			&pgconn.PgError{Code: pgUniqueViolation, TableName: "owners", ConstraintName: "owners_email_key"},
+			domain.ErrConflict,
+			"email is already in use",
+			[]domain.FieldError{{Field: "email", Code: domain.FieldTaken, Message: "email is already in use"}},
+		},
+		{
+			"unique slug reported on the title",
+			&pgconn.PgError{Code: pgUniqueViolation, TableName: "news", ConstraintName: "news_slug_key"},
+			domain.ErrConflict,
+			"title is already in use",
+			[]domain.FieldError{{Field: "title", Code: domain.FieldTaken, Message: "title is already in use"}},
+		},
+		{
+			"missing referenced row",
+			&pgconn.PgError{Code: pgForeignKeyViolation, Message: `insert or update on table "news" violates foreign key constraint "news_category_id_fkey"`, TableName: "news", ConstraintName: "news_category_id_fkey"},
+			domain.ErrInvalidInput,
+			"validation failed",
+			[]domain.FieldError{{Field: "category_id", Code: domain.FieldNotFound, Message: "category_id does not exist"}},
+		},
+		{
+			"delete of a referenced row",
+			&pgconn.PgError{Code: pgForeignKeyViolation, Message: `update or delete on table "categories" violates foreign key constraint "news_category_id_fkey" on table "news"`, TableName: "news", ConstraintName: "news_category_id_fkey"},
+			domain.ErrConflict,
+			"still referenced by articles",
+			nil,
+		},
+		{
+			"delete referenced by an unnamed table",
+			&pgconn.PgError{Code: pgForeignKeyViolation, Message: `update or delete on table "owners" violates foreign key constraint "audit_log_owner_id_fkey" on table "audit_log"`, TableName: "audit_log", ConstraintName: "audit_log_owner_id_fkey"},
+			domain.ErrConflict,
+			"still in use",
+			nil,
+		},
+		{
+			"not null column",
+			&pgconn.PgError{Code: pgNotNullViolation, TableName: "news", ColumnName: "title"},
+			domain.ErrInvalidInput,
+			"validation failed",
+			[]domain.FieldError{{Field: "title", Code: domain.FieldRequired, Message: "title is required"}},
+		},
+		{
+			"check constraint",
+			&pgconn.PgError{Code: pgCheckViolation, TableName: "media", ConstraintName: "media_size_bytes_check"},
+			domain.ErrInvalidInput,
+			"validation failed",
+			[]domain.FieldError{{Field: "size_bytes", Code: domain.FieldInvalid, Message: "size_bytes is invalid"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateError(fmt.Errorf("query failed: %w", tt.err))
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("err = %v, want kind %v", err, tt.kind)
+			}
+			var domainErr *domain.Error
+			if !errors.As(err, &domainErr) {
+				t.Fatalf("err = %T, want *domain.Error", err)
+			}
+			if domainErr.Message != tt.message {
+				t.Errorf("Message = %q, want %q", domainErr.Message, tt.message)
+			}
+			if !reflect.DeepEqual(domainErr.Fields, tt.fields) {
+				t.Errorf("Fields = %+v, want %+v", domainErr.Fields, tt.fields)
+			}
+		})
+	}
+}
+
+// Messages reach clients, so they never name the table behind a conflict
+func TestTranslateErrorHidesReferencingTable(t *testing.T) {
+	for _, table := range []string{"news", "media", "report_schedules", "audit_log"} {
+		err := translateError(&pgconn.PgError{
+			Code:      pgForeignKeyViolation,
+			Message:   fmt.Sprintf(`update or delete on table "owners" violates foreign key constraint "%s_owner_id_fkey" on table "%s"`, table, table),
+			TableName: table,
+		})
+		if strings.HasSuffix(err.Error(), " "+table) {
+			t.Errorf("message for %s names the table: %q", table, err)
+		}
+	}
+}
+
+func TestTranslateErrorPassesOthersThrough(t *testing.T) {
+	tooLong := translateError(&pgconn.PgError{Code: pgStringTooLong})
+	if !errors.Is(tooLong, domain.ErrInvalidInput) {
+		t.Errorf("string too long = %v, want ErrInvalidInput", tooLong)
+	}
+
+	other := errors.New("connection refused")
+	if got := translateError(other); got != other {
+		t.Errorf("got %v, want the error unchanged", got)
+	}
+	deadlock := &pgconn.PgError{Code: "40P01"}
+	if got := translateError(deadlock); got != error(deadlock) {
+		t.Errorf("got %v, want the error unchanged", got)
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("dial tcp: connection refused"), true},
+		{&pgconn.PgError{Code: "08006"}, true},
+		{&pgconn.PgError{Code: "53300"}, true},
+		{&pgconn.PgError{Code: "57P01"}, true},
+		{&pgconn.PgError{Code: pgStringTooLong}, false},
+		{&pgconn.PgError{Code: "40P01"}, false},
+	}
+	for _, tt := range tests {
+		if got := unavailable(tt.err); got != tt.want {
+			t.Errorf("unavailable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
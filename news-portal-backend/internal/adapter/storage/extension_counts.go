@@ -27,10 +27,18 @@ func (a *Adapter) CountNews(ctx context.Context, categoryID *uuid.UUID, authorID
 	return count, err
 }
 
-func (a *Adapter) CountTotalViews(ctx context.Context) (int64, error) {
+func (a *Adapter) CountTotalViews(ctx context.Context, categoryID *uuid.UUID, authorID *uuid.UUID) (int64, error) {
 	var totalViews int64
 	// Handle NULL sum by COALESCE just in case, though views_count is int not null default 0
-	err := a.db.QueryRow(ctx, "SELECT COALESCE(SUM(views_count), 0) FROM news").Scan(&totalViews)
+	err := a.db.QueryRow(ctx, `SELECT COALESCE(SUM(views_count), 0) FROM news
+	          WHERE ($1::uuid IS NULL OR category_id = $1) AND ($2::uuid IS NULL OR author_id = $2)`, categoryID, authorID).Scan(&totalViews)
+	return totalViews, err
+}
+
+func (a *Adapter) CountTotalRawViews(ctx context.Context, categoryID *uuid.UUID, authorID *uuid.UUID) (int64, error) {
+	var totalViews int64
+	err := a.db.QueryRow(ctx, `SELECT COALESCE(SUM(raw_views_count), 0) FROM news
+	          WHERE ($1::uuid IS NULL OR category_id = $1) AND ($2::uuid IS NULL OR author_id = $2)`, categoryID, authorID).Scan(&totalViews)
 	return totalViews, err
 }
 
@@ -40,22 +48,25 @@ func (a *Adapter) CountCategories(ctx context.Context) (int64, error) {
 	return count, err
 }
 
-func (a *Adapter) GetCategoryViewStats(ctx context.Context) ([]port.CategoryViewStat, error) {
-	query := `
-		SELECT c.name, COALESCE(SUM(n.views_count), 0) as value
-		FROM categories c
-		LEFT JOIN news n ON c.id = n.category_id
+func (a *Adapter) GetCategoryViewStats(ctx context.Context, filter port.StatsFilter) ([]port.CategoryViewStat, error) {
+	query := `WITH ` + periodViewsCTE + `
+		SELECT c.name, SUM(pv.views) as value
+		FROM period_views pv
+		JOIN news n ON n.id = pv.news_id
+		JOIN categories c ON c.id = n.category_id
+		WHERE ($3::uuid IS NULL OR n.category_id = $3)
+		AND ($4::uuid IS NULL OR n.author_id = $4)
 		GROUP BY c.name
-		HAVING COALESCE(SUM(n.views_count), 0) > 0
+		HAVING SUM(pv.views) > 0
 		ORDER BY value DESC
 	`
-	rows, err := a.db.Query(ctx, query)
+	rows, err := a.db.Query(ctx, query, filter.From, filter.To, filter.CategoryID, filter.AuthorID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var stats []port.CategoryViewStat
+	stats := []port.CategoryViewStat{}
 	for rows.Next() {
 		var s port.CategoryViewStat
 		if err := rows.Scan(&s.Name, &s.Value); err != nil {
@@ -63,24 +74,30 @@ func (a *Adapter) GetCategoryViewStats(ctx context.Context) ([]port.CategoryView
 		}
 		stats = append(stats, s)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 	return stats, nil
 }
 
-func (a *Adapter) GetMonthlyTopNews(ctx context.Context, limit int) ([]port.NewsViewStat, error) {
-	query := `
-		SELECT id, title, views_count
-		FROM news
-		WHERE published_at >= NOW() - INTERVAL '30 days'
-		ORDER BY views_count DESC
-		LIMIT $1
+func (a *Adapter) GetTopNews(ctx context.Context, filter port.StatsFilter, limit int) ([]port.NewsViewStat, error) {
+	query := `WITH ` + periodViewsCTE + `
+		SELECT n.id, n.title, pv.views
+		FROM period_views pv
+		JOIN news n ON n.id = pv.news_id
+		WHERE pv.views > 0
+		AND ($3::uuid IS NULL OR n.category_id = $3)
+		AND ($4::uuid IS NULL OR n.author_id = $4)
+		ORDER BY pv.views DESC
+		LIMIT $5
 	`
-	rows, err := a.db.Query(ctx, query, limit)
+	rows, err := a.db.Query(ctx, query, filter.From, filter.To, filter.CategoryID, filter.AuthorID, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var stats []port.NewsViewStat
+	stats := []port.NewsViewStat{}
 	for rows.Next() {
 		var s port.NewsViewStat
 		if err := rows.Scan(&s.ID, &s.Title, &s.Views); err != nil {
@@ -88,5 +105,8 @@ func (a *Adapter) GetMonthlyTopNews(ctx context.Context, limit int) ([]port.News
 		}
 		stats = append(stats, s)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 	return stats, nil
 }
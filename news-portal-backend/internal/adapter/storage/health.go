@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Ping checks that a pooled connection can reach the database
+func (a *Adapter) Ping(ctx context.Context) error {
+	return a.pool.Ping(ctx)
+}
+
+// SchemaVersion returns the last migration applied by golang-migrate and
+// whether it failed halfway. A database that was never migrated is at
+// version 0.
+func (a *Adapter) SchemaVersion(ctx context.Context) (version int64, dirty bool, err error) {
+	err = a.db.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"news-portal-backend/internal/core/domain"
+	"news-portal-backend/internal/core/port"
+)
+
+// MediaRepository implementation
+
+const mediaColumns = `id, COALESCE(owner_id, '00000000-0000-0000-0000-000000000000'), object_key, url, filename, content_type, size_bytes,
+	COALESCE(sha256, ''), status, reuse_count, created_at, completed_at, width, height, blurhash, dominant_color`
+
+func (a *Adapter) CreateMedia(ctx context.Context, media *domain.Media) (*domain.Media, error) {
+	var ownerID *uuid.UUID
+	if media.OwnerID != uuid.Nil {
+		ownerID = &media.OwnerID
+	}
+	var sum *string
+	if media.SHA256 != "" {
+		sum = &media.SHA256
+	}
+	image := newThumbnailColumns(media.Image)
+
+	query := `INSERT INTO media (owner_id, object_key, url, filename, content_type, size_bytes, sha256, status, completed_at,
+	                             width, height, blurhash, dominant_color)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CASE WHEN $8 = 'ready' THEN NOW() END, $9, $10, $11, $12)
+	          RETURNING id, created_at, completed_at`
+	err := a.db.QueryRow(ctx, query, ownerID, media.ObjectKey, media.URL, media.Filename, media.ContentType, media.SizeBytes, sum, media.Status,
+		image.width, image.height, image.blurHash, image.color).
+		Scan(&media.ID, &media.CreatedAt, &media.CompletedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return media, nil
+}
+
+func (a *Adapter) GetMediaByID(ctx context.Context, id uuid.UUID) (*domain.Media, error) {
+	query := `SELECT ` + mediaColumns + ` FROM media WHERE id = $1 LIMIT 1`
+	return scanMedia(a.db.QueryRow(ctx, query, id))
+}
+
+// FindReadyMediaByHash returns the oldest finished upload with identical
+// content, only looking at ownerID's uploads when it is set
+func (a *Adapter) FindReadyMediaByHash(ctx context.Context, sha256 string, size int64, ownerID *uuid.UUID) (*domain.Media, error) {
+	query := `SELECT ` + mediaColumns + ` FROM media
+	          WHERE sha256 = $1 AND size_bytes = $2 AND status = 'ready'
+	            AND ($3::uuid IS NULL OR owner_id = $3)
+	          ORDER BY created_at ASC LIMIT 1`
+	return scanMedia(a.db.QueryRow(ctx, query, sha256, size, ownerID))
+}
+
+func (a *Adapter) SetMediaHash(ctx context.Context, id uuid.UUID, sha256 string) error {
+	tag, err := a.db.Exec(ctx, "UPDATE media SET sha256 = $2 WHERE id = $1", id, sha256)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (a *Adapter) DeleteMedia(ctx context.Context, id uuid.UUID) error {
+	tag, err := a.db.Exec(ctx, "DELETE FROM media WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (a *Adapter) UpdateMediaStatus(ctx context.Context, id uuid.UUID, status string) error {
+	query := `UPDATE media SET status = $2,
+	          completed_at = CASE WHEN $2 = 'ready' THEN NOW() ELSE completed_at END
+	          WHERE id = $1`
+	tag, err := a.db.Exec(ctx, query, id, status)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (a *Adapter) IncrementMediaReuse(ctx context.Context, id uuid.UUID) error {
+	tag, err := a.db.Exec(ctx, "UPDATE media SET reuse_count = reuse_count + 1 WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (a *Adapter) GetMediaDedupStats(ctx context.Context) (*port.MediaDedupStat, error) {
+	query := `SELECT COALESCE(SUM(reuse_count), 0), COALESCE(SUM(reuse_count * size_bytes), 0)
+	          FROM media WHERE status = 'ready'`
+	stat := &port.MediaDedupStat{}
+	err := a.db.QueryRow(ctx, query).Scan(&stat.DuplicateUploads, &stat.BytesSaved)
+	if err != nil {
+		return nil, err
+	}
+	return stat, nil
+}
+
+func scanMedia(row pgx.Row) (*domain.Media, error) {
+	m := &domain.Media{}
+	var image thumbnailColumns
+	err := row.Scan(
+		&m.ID, &m.OwnerID, &m.ObjectKey, &m.URL, &m.Filename, &m.ContentType, &m.SizeBytes,
+		&m.SHA256, &m.Status, &m.ReuseCount, &m.CreatedAt, &m.CompletedAt,
+		&image.width, &image.height, &image.blurHash, &image.color,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	m.Image = image.imageInfo()
+	return m, nil
+}
+
+var _ port.MediaRepository = (*Adapter)(nil)
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"news-portal-backend/internal/core/port"
+)
+
+// RateLimitStore implementation: a sliding window counter. Requests are
+// counted in fixed windows, and the previous window's count is weighted by
+// how much of it still overlaps the sliding window.
+
+// takeRateLimitQuery counts the request in the current window unless the
+// weighted total would exceed the limit, and returns the previous count, the
+// new count when it was counted and the current count otherwise.
+//
+// $1 key, $2 current window start, $3 previous window start, $4 expiry,
+// $5 weight of the previous window, $6 limit
+const takeRateLimitQuery = `
+WITH prev AS (
+    SELECT COALESCE(SUM(count), 0) AS count FROM rate_limits WHERE key = $1 AND window_start = $3
+), hit AS (
+    INSERT INTO rate_limits AS r (key, window_start, count, expires_at)
+    SELECT $1, $2::timestamptz, 1, $4::timestamptz FROM prev WHERE prev.count * $5::float8 + 1 <= $6::int
+    ON CONFLICT (key, window_start) DO UPDATE SET count = r.count + 1
+    WHERE (SELECT count FROM prev) * $5::float8 + r.count + 1 <= $6::int
+    RETURNING r.count
+)
+SELECT (SELECT count FROM prev),
+       (SELECT count FROM hit),
+       COALESCE((SELECT count FROM rate_limits WHERE key = $1 AND window_start = $2), 0)`
+
+func (a *Adapter) TakeRateLimit(ctx context.Context, key string, limit int, window time.Duration) (*port.RateLimitResult, error) {
+	now := time.Now()
+	start := now.Truncate(window)
+	elapsed := now.Sub(start)
+	weight := 1 - elapsed.Seconds()/window.Seconds()
+
+	var previous, current int64
+	var counted *int64
+	err := a.db.QueryRow(ctx, takeRateLimitQuery, key, start, start.Add(-window), start.Add(2*window), weight, limit).
+		Scan(&previous, &counted, &current)
+	if err != nil {
+		return nil, rateLimitError(err)
+	}
+	if counted != nil {
+		current = *counted
+	}
+	return slidingWindowResult(previous, current, counted != nil, limit, window, elapsed), nil
+}
+
+// checkRateLimitQuery returns the previous and current window's counts
+//
+// $1 key, $2 current window start, $3 previous window start
+const checkRateLimitQuery = `
+SELECT COALESCE(SUM(count) FILTER (WHERE window_start = $3), 0),
+       COALESCE(SUM(count) FILTER (WHERE window_start = $2), 0)
+FROM rate_limits WHERE key = $1 AND window_start IN ($2, $3)`
+
+func (a *Adapter) CheckRateLimit(ctx context.Context, key string, limit int, window time.Duration) (*port.RateLimitResult, error) {
+	now := time.Now()
+	start := now.Truncate(window)
+	elapsed := now.Sub(start)
+	weight := 1 - elapsed.Seconds()/window.Seconds()
+
+	var previous, current int64
+	err := a.db.QueryRow(ctx, checkRateLimitQuery, key, start, start.Add(-window)).Scan(&previous, &current)
+	if err != nil {
+		return nil, rateLimitError(err)
+	}
+	allowed := float64(previous)*weight+float64(current)+1 <= float64(limit)
+	return slidingWindowResult(previous, current, allowed, limit, window, elapsed), nil
+}
+
+// rateLimitError marks errors from not reaching the database, so a
+// fallback store only takes over during an outage
+func rateLimitError(err error) error {
+	if unavailable(err) {
+		return fmt.Errorf("%w: %w", port.ErrRateLimitStoreUnavailable, err)
+	}
+	return translateError(err)
+}
+
+// slidingWindowResult reports a limit from the previous and current
+// window's counts
+func slidingWindowResult(previous, current int64, allowed bool, limit int, window, elapsed time.Duration) *port.RateLimitResult {
+	weight := 1 - elapsed.Seconds()/window.Seconds()
+	used := float64(previous)*weight + float64(current)
+	result := &port.RateLimitResult{
+		Allowed:   allowed,
+		Remaining: max(int(math.Floor(float64(limit)-used)), 0),
+		// Requests in the current window stop counting once the window
+		// after it has fully passed
+		Reset: 2*window - elapsed,
+	}
+	if current == 0 {
+		result.Reset = window - elapsed
+	}
+	if !result.Allowed {
+		result.RetryAfter = slidingWindowRetryAfter(previous, current, limit, window, elapsed)
+	}
+	return result
+}
+
+// slidingWindowRetryAfter returns how long until one more request fits:
+// within the current window once enough of the previous one has slid out,
+// otherwise in the next window once enough of this one has.
+func slidingWindowRetryAfter(previous, current int64, limit int, window, elapsed time.Duration) time.Duration {
+	free := float64(limit) - float64(current) - 1
+	if free >= 0 && previous > 0 {
+		wait := time.Duration((1-free/float64(previous))*float64(window)) - elapsed
+		return max(wait, 0)
+	}
+	untilNext := window - elapsed
+	if current == 0 {
+		return untilNext
+	}
+	return untilNext + time.Duration(max(1-float64(limit-1)/float64(current), 0)*float64(window))
+}
+
+// DeleteExpiredRateLimits removes counters that no longer affect any limit
+func (a *Adapter) DeleteExpiredRateLimits(ctx context.Context) error {
+	_, err := a.db.Exec(ctx, `DELETE FROM rate_limits WHERE expires_at < NOW()`)
+	return err
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestSlidingWindowRetryAfter(t *testing.T) {
+	tests := []struct {
+		name              string
+		previous, current int64
+		limit             int
+		elapsed           time.Duration
+		want              time.Duration
+	}{
+		// 10 * (1 - e/60) + 5 + 1 <= 10 once e >= 36s
+		{"previous window slides out", 10, 5, 10, 10 * time.Second, 26 * time.Second},
+		{"already fits", 10, 2, 10, 30 * time.Second, 0},
+		// Next window in 50s, then 10 * (1 - e/60) + 1 <= 10 once e >= 6s
+		{"current window is full", 0, 10, 10, 10 * time.Second, 56 * time.Second},
+		{"nothing counted", 0, 0, 10, 10 * time.Second, 50 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slidingWindowRetryAfter(tt.previous, tt.current, tt.limit, time.Minute, tt.elapsed)
+			if got.Round(time.Millisecond) != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// testAdapter connects to TEST_DATABASE_URL, skipping the test without it
+func testAdapter(t *testing.T) *Adapter {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	migration, err := os.ReadFile("../../../migrations/013_rate_limits.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Exec(context.Background(), string(migration)); err != nil {
+		t.Fatal(err)
+	}
+	return NewAdapter(pool)
+}
+
+func TestTakeRateLimit(t *testing.T) {
+	a := testAdapter(t)
+	ctx := context.Background()
+	key := fmt.Sprintf("test:%d", time.Now().UnixNano())
+	t.Cleanup(func() { a.db.Exec(ctx, `DELETE FROM rate_limits WHERE key = $1`, key) })
+
+	// A long window keeps the test inside one fixed window
+	for i := 0; i < 3; i++ {
+		r, err := a.TakeRateLimit(ctx, key, 3, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !r.Allowed || r.Remaining != 2-i {
+			t.Fatalf("request %d: %+v", i, r)
+		}
+	}
+	r, err := a.TakeRateLimit(ctx, key, 3, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Allowed || r.RetryAfter <= 0 {
+		t.Fatalf("fourth request: %+v", r)
+	}
+
+	if r, err := a.CheckRateLimit(ctx, key, 3, time.Hour); err != nil || r.Allowed || r.Remaining != 0 {
+		t.Fatalf("check: %+v, %v", r, err)
+	}
+
+	// Rejected requests and checks are not counted
+	var count int
+	if err := a.db.QueryRow(ctx, `SELECT SUM(count) FROM rate_limits WHERE key = $1`, key).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("count = %d, want 3", count)
+	}
+}
+
+func TestTakeRateLimitConcurrent(t *testing.T) {
+	a := testAdapter(t)
+	ctx := context.Background()
+	key := fmt.Sprintf("test:%d", time.Now().UnixNano())
+	t.Cleanup(func() { a.db.Exec(ctx, `DELETE FROM rate_limits WHERE key = $1`, key) })
+
+	// Replicas racing on one key must not exceed the limit together
+	var mu sync.Mutex
+	allowed := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := a.TakeRateLimit(ctx, key, 5, time.Hour)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if r.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != 5 {
+		t.Errorf("allowed %d requests, want 5", allowed)
+	}
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"news-portal-backend/internal/core/domain"
+	"news-portal-backend/internal/core/port"
+)
+
+// ReportRepository implementation
+
+const reportScheduleColumns = `id, owner_id, name, recipients, frequency, weekday, send_hour, send_minute, timezone, enabled,
+	last_scheduled_at, created_at, updated_at`
+
+const reportRunColumns = `id, schedule_id, status, period_from, period_to, recipients, error, started_at, finished_at`
+
+func (a *Adapter) CreateReportSchedule(ctx context.Context, schedule *domain.ReportSchedule) (*domain.ReportSchedule, error) {
+	query := `INSERT INTO report_schedules (owner_id, name, recipients, frequency, weekday, send_hour, send_minute, timezone, enabled)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	          RETURNING id, created_at, updated_at`
+	err := a.db.QueryRow(ctx, query, schedule.OwnerID, schedule.Name, schedule.Recipients, schedule.Frequency, schedule.Weekday,
+		schedule.SendHour, schedule.SendMinute, schedule.Timezone, schedule.Enabled).
+		Scan(&schedule.ID, &schedule.CreatedAt, &schedule.UpdatedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return schedule, nil
+}
+
+func (a *Adapter) GetReportSchedule(ctx context.Context, id uuid.UUID) (*domain.ReportSchedule, error) {
+	query := `SELECT ` + reportScheduleColumns + ` FROM report_schedules WHERE id = $1 LIMIT 1`
+	schedule, err := scanReportSchedule(a.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return schedule, err
+}
+
+func (a *Adapter) ListReportSchedules(ctx context.Context, ownerID uuid.UUID) ([]*domain.ReportSchedule, error) {
+	query := `SELECT ` + reportScheduleColumns + ` FROM report_schedules WHERE owner_id = $1 ORDER BY created_at`
+	return a.queryReportSchedules(ctx, query, ownerID)
+}
+
+func (a *Adapter) ListEnabledReportSchedules(ctx context.Context) ([]*domain.ReportSchedule, error) {
+	query := `SELECT ` + reportScheduleColumns + ` FROM report_schedules WHERE enabled ORDER BY created_at`
+	return a.queryReportSchedules(ctx, query)
+}
+
+func (a *Adapter) UpdateReportSchedule(ctx context.Context, schedule *domain.ReportSchedule) error {
+	query := `UPDATE report_schedules
+	          SET name = $2, recipients = $3, frequency = $4, weekday = $5, send_hour = $6, send_minute = $7, timezone = $8, enabled = $9,
+	              updated_at = NOW()
+	          WHERE id = $1
+	          RETURNING updated_at`
+	err := a.db.QueryRow(ctx, query, schedule.ID, schedule.Name, schedule.Recipients, schedule.Frequency, schedule.Weekday,
+		schedule.SendHour, schedule.SendMinute, schedule.Timezone, schedule.Enabled).Scan(&schedule.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrNotFound
+	}
+	return translateError(err)
+}
+
+func (a *Adapter) DeleteReportSchedule(ctx context.Context, id uuid.UUID) error {
+	tag, err := a.db.Exec(ctx, "DELETE FROM report_schedules WHERE id = $1", id)
+	if err != nil {
+		return translateError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (a *Adapter) ClaimReportSchedule(ctx context.Context, id uuid.UUID, scheduledFor time.Time) (bool, error) {
+	tag, err := a.db.Exec(ctx, `UPDATE report_schedules SET last_scheduled_at = $2
+	          WHERE id = $1 AND (last_scheduled_at IS NULL OR last_scheduled_at < $2)`, id, scheduledFor)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (a *Adapter) CreateReportRun(ctx context.Context, run *domain.ReportRun) (*domain.ReportRun, error) {
+	query := `INSERT INTO report_runs (schedule_id, status, period_from, period_to, recipients)
+	          VALUES ($1, $2, $3, $4, $5)
+	          RETURNING id, started_at`
+	err := a.db.QueryRow(ctx, query, run.ScheduleID, run.Status, run.PeriodFrom, run.PeriodTo, run.Recipients).
+		Scan(&run.ID, &run.StartedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return run, nil
+}
+
+func (a *Adapter) FinishReportRun(ctx context.Context, id uuid.UUID, status string, errMsg *string) error {
+	_, err := a.db.Exec(ctx, `UPDATE report_runs SET status = $2, error = $3, finished_at = NOW() WHERE id = $1`, id, status, errMsg)
+	return err
+}
+
+func (a *Adapter) ListReportRuns(ctx context.Context, scheduleID uuid.UUID, limit int) ([]*domain.ReportRun, error) {
+	query := `SELECT ` + reportRunColumns + ` FROM report_runs WHERE schedule_id = $1 ORDER BY started_at DESC LIMIT $2`
+	rows, err := a.db.Query(ctx, query, scheduleID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []*domain.ReportRun{}
+	for rows.Next() {
+		run := &domain.ReportRun{}
+		if err := rows.Scan(&run.ID, &run.ScheduleID, &run.Status, &run.PeriodFrom, &run.PeriodTo, &run.Recipients,
+			&run.Error, &run.StartedAt, &run.FinishedAt); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+func (a *Adapter) queryReportSchedules(ctx context.Context, query string, args ...any) ([]*domain.ReportSchedule, error) {
+	rows, err := a.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []*domain.ReportSchedule{}
+	for rows.Next() {
+		schedule, err := scanReportSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, rows.Err()
+}
+
+func scanReportSchedule(row pgx.Row) (*domain.ReportSchedule, error) {
+	s := &domain.ReportSchedule{}
+	err := row.Scan(&s.ID, &s.OwnerID, &s.Name, &s.Recipients, &s.Frequency, &s.Weekday, &s.SendHour, &s.SendMinute, &s.Timezone, &s.Enabled,
+		&s.LastScheduledAt, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+var _ port.ReportRepository = (*Adapter)(nil)
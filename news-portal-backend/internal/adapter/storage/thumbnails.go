@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"news-portal-backend/internal/core/domain"
+)
+
+// thumbnailColumns mirrors the nullable thumbnail_* columns on news
+type thumbnailColumns struct {
+	width    *int32
+	height   *int32
+	blurHash *string
+	color    *string
+}
+
+func newThumbnailColumns(info *domain.ImageInfo) thumbnailColumns {
+	if info == nil {
+		return thumbnailColumns{}
+	}
+	width, height := int32(info.Width), int32(info.Height)
+	c := thumbnailColumns{width: &width, height: &height}
+	if info.BlurHash != "" {
+		c.blurHash = &info.BlurHash
+	}
+	if info.DominantColor != "" {
+		c.color = &info.DominantColor
+	}
+	return c
+}
+
+func (c thumbnailColumns) imageInfo() *domain.ImageInfo {
+	if c.width == nil || c.height == nil {
+		return nil
+	}
+	info := &domain.ImageInfo{Width: int(*c.width), Height: int(*c.height)}
+	if c.blurHash != nil {
+		info.BlurHash = *c.blurHash
+	}
+	if c.color != nil {
+		info.DominantColor = *c.color
+	}
+	return info
+}
+
+func thumbnailMetaOrEmpty(meta *domain.ThumbnailMeta) domain.ThumbnailMeta {
+	if meta == nil {
+		return domain.ThumbnailMeta{}
+	}
+	return *meta
+}
+
+// ThumbnailRef identifies an article whose thumbnail hasn't been analyzed yet
+type ThumbnailRef struct {
+	ID        uuid.UUID
+	Thumbnail string
+}
+
+// ListNewsMissingThumbnailInfo returns articles with a thumbnail but no
+// placeholder data, oldest first. Used by the backfill command.
+func (a *Adapter) ListNewsMissingThumbnailInfo(ctx context.Context, limit int32, after uuid.UUID) ([]ThumbnailRef, error) {
+	query := `SELECT id, thumbnail FROM news
+	          WHERE thumbnail <> '' AND thumbnail_width IS NULL AND id > $2
+	          ORDER BY id LIMIT $1`
+	rows, err := a.db.Query(ctx, query, limit, after)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refs []ThumbnailRef
+	for rows.Next() {
+		var ref ThumbnailRef
+		if err := rows.Scan(&ref.ID, &ref.Thumbnail); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+func (a *Adapter) UpdateNewsThumbnailInfo(ctx context.Context, id uuid.UUID, info *domain.ImageInfo) error {
+	thumb := newThumbnailColumns(info)
+	query := `UPDATE news SET thumbnail_width = $2, thumbnail_height = $3, thumbnail_blurhash = $4, thumbnail_color = $5 WHERE id = $1`
+	tag, err := a.db.Exec(ctx, query, id, thumb.width, thumb.height, thumb.blurHash, thumb.color)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
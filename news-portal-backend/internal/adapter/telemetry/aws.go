@@ -0,0 +1,31 @@
+package telemetry
+
+import (
+	"context"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	smithymiddleware "github.com/aws/smithy-go/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AWSMiddleware adds a client span around every AWS SDK operation, such as
+// S3.PutObject. Pass it to the client config's APIOptions.
+func AWSMiddleware(stack *smithymiddleware.Stack) error {
+	return stack.Initialize.Add(smithymiddleware.InitializeMiddlewareFunc("OTelSpan", func(
+		ctx context.Context, in smithymiddleware.InitializeInput, next smithymiddleware.InitializeHandler,
+	) (smithymiddleware.InitializeOutput, smithymiddleware.Metadata, error) {
+		service, operation := awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx)
+		ctx, span := tracer.Start(ctx, service+"."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("rpc.system", "aws-api"),
+				attribute.String("rpc.service", service),
+				attribute.String("rpc.method", operation),
+			),
+		)
+		out, metadata, err := next.HandleInitialize(ctx, in)
+		end(span, err)
+		return out, metadata, err
+	}), smithymiddleware.After)
+}
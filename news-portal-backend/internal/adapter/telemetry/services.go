@@ -0,0 +1,134 @@
+package telemetry
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"news-portal-backend/internal/core/domain"
+	"news-portal-backend/internal/core/port"
+)
+
+// newsService wraps a port.NewsService with a span per call
+type newsService struct {
+	next port.NewsService
+}
+
+// TraceNewsService records a span around every NewsService call
+func TraceNewsService(next port.NewsService) port.NewsService {
+	return newsService{next: next}
+}
+
+func (s newsService) CreateNews(ctx context.Context, authorID, categoryID uuid.UUID, title, excerpt, content, thumbnail string, thumbnailInfo *domain.ImageInfo, thumbnailMeta domain.ThumbnailMeta, isFeatured, allowMissingAlt bool) (*domain.News, error) {
+	ctx, span := startSpan(ctx, "NewsService.CreateNews", attribute.String("news.category_id", categoryID.String()))
+	news, err := s.next.CreateNews(ctx, authorID, categoryID, title, excerpt, content, thumbnail, thumbnailInfo, thumbnailMeta, isFeatured, allowMissingAlt)
+	end(span, err)
+	return news, err
+}
+
+func (s newsService) UpdateNews(ctx context.Context, id uuid.UUID, categoryID uuid.UUID, title, excerpt, content, thumbnail string, thumbnailInfo *domain.ImageInfo, thumbnailMeta domain.ThumbnailMeta, isFeatured, allowMissingAlt bool) error {
+	ctx, span := startSpan(ctx, "NewsService.UpdateNews", attribute.String("news.id", id.String()))
+	err := s.next.UpdateNews(ctx, id, categoryID, title, excerpt, content, thumbnail, thumbnailInfo, thumbnailMeta, isFeatured, allowMissingAlt)
+	end(span, err)
+	return err
+}
+
+func (s newsService) DeleteNews(ctx context.Context, id uuid.UUID) error {
+	ctx, span := startSpan(ctx, "NewsService.DeleteNews", attribute.String("news.id", id.String()))
+	err := s.next.DeleteNews(ctx, id)
+	end(span, err)
+	return err
+}
+
+func (s newsService) GetNewsBySlug(ctx context.Context, slug string, viewer port.Viewer) (*domain.News, error) {
+	ctx, span := startSpan(ctx, "NewsService.GetNewsBySlug", attribute.String("news.slug", slug))
+	news, err := s.next.GetNewsBySlug(ctx, slug, viewer)
+	end(span, err)
+	return news, err
+}
+
+func (s newsService) RecordShare(ctx context.Context, id uuid.UUID, network string, viewer port.Viewer) error {
+	ctx, span := startSpan(ctx, "NewsService.RecordShare", attribute.String("news.id", id.String()))
+	err := s.next.RecordShare(ctx, id, network, viewer)
+	end(span, err)
+	return err
+}
+
+func (s newsService) ListNews(ctx context.Context, page, limit int32, categorySlug string, authorID *uuid.UUID, sortBy string, isFeatured *bool, search string) ([]*domain.News, int64, error) {
+	ctx, span := startSpan(ctx, "NewsService.ListNews",
+		attribute.Int("news.page", int(page)),
+		attribute.String("news.category", categorySlug),
+		attribute.String("news.sort", sortBy),
+	)
+	news, total, err := s.next.ListNews(ctx, page, limit, categorySlug, authorID, sortBy, isFeatured, search)
+	end(span, err)
+	return news, total, err
+}
+
+func (s newsService) ExportNews(ctx context.Context, categorySlug string, authorID *uuid.UUID, sortBy string, isFeatured *bool, search string, fn func(*domain.News) error) error {
+	ctx, span := startSpan(ctx, "NewsService.ExportNews", attribute.String("news.category", categorySlug))
+	err := s.next.ExportNews(ctx, categorySlug, authorID, sortBy, isFeatured, search, fn)
+	end(span, err)
+	return err
+}
+
+func (s newsService) CheckSlug(ctx context.Context, slug string) (bool, error) {
+	ctx, span := startSpan(ctx, "NewsService.CheckSlug")
+	exists, err := s.next.CheckSlug(ctx, slug)
+	end(span, err)
+	return exists, err
+}
+
+func (s newsService) GetHomepageData(ctx context.Context) (*port.HomepageData, error) {
+	ctx, span := startSpan(ctx, "NewsService.GetHomepageData")
+	data, err := s.next.GetHomepageData(ctx)
+	end(span, err)
+	return data, err
+}
+
+// authService wraps a port.AuthService with a span per call. Emails and
+// passwords are never recorded.
+type authService struct {
+	next port.AuthService
+}
+
+// TraceAuthService records a span around every AuthService call
+func TraceAuthService(next port.AuthService) port.AuthService {
+	return authService{next: next}
+}
+
+func (s authService) Login(ctx context.Context, email, password string) (string, error) {
+	ctx, span := startSpan(ctx, "AuthService.Login")
+	token, err := s.next.Login(ctx, email, password)
+	end(span, err)
+	return token, err
+}
+
+func (s authService) Register(ctx context.Context, name, email, password string) (*domain.Owner, error) {
+	ctx, span := startSpan(ctx, "AuthService.Register")
+	owner, err := s.next.Register(ctx, name, email, password)
+	end(span, err)
+	return owner, err
+}
+
+func (s authService) ChangePassword(ctx context.Context, id uuid.UUID, oldPassword, newPassword string) error {
+	ctx, span := startSpan(ctx, "AuthService.ChangePassword", attribute.String("user.id", id.String()))
+	err := s.next.ChangePassword(ctx, id, oldPassword, newPassword)
+	end(span, err)
+	return err
+}
+
+func (s authService) ListUsers(ctx context.Context) ([]*domain.Owner, error) {
+	ctx, span := startSpan(ctx, "AuthService.ListUsers")
+	users, err := s.next.ListUsers(ctx)
+	end(span, err)
+	return users, err
+}
+
+func (s authService) GetMe(ctx context.Context, id uuid.UUID) (*domain.Owner, error) {
+	ctx, span := startSpan(ctx, "AuthService.GetMe", attribute.String("user.id", id.String()))
+	owner, err := s.next.GetMe(ctx, id)
+	end(span, err)
+	return owner, err
+}
@@ -0,0 +1,276 @@
+// Package validate checks request DTOs against rules declared in struct
+// tags and reports every failing field at once.
+//
+//	type request struct {
+//		Email string `json:"email" validate:"required,max=255,email"`
+//		Tags  []string `json:"tags" validate:"max=10,dive,max=50"`
+//	}
+//
+// Rules run left to right and stop at the first failure of a field. Empty
+// values only fail "required"; every other rule skips them. Rules after
+// "dive" apply to each element of a slice.
+//
+//	required      not empty (strings are trimmed first)
+//	min=N, max=N  length in characters, number of elements, or numeric value
+//	len=N         exact length in characters
+//	email         a bare address such as name@example.com
+//	uuid          a UUID in canonical form
+//	url           an absolute http or https URL
+//	hex           hexadecimal digits only
+//	oneof=a b c   one of the listed values
+//	password      8 to 72 bytes with at least one letter and one digit
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"news-portal-backend/internal/core/domain"
+)
+
+// Struct validates v, a struct or pointer to one. It returns nil or a
+// *domain.Error listing each invalid field by its json, form or query name.
+func Struct(v any) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validate: %T is not a struct", v))
+	}
+
+	var fields []domain.FieldError
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get("validate")
+		if tag == "" || !sf.IsExported() {
+			continue
+		}
+		if fe := check(fieldName(sf), rv.Field(i), strings.Split(tag, ",")); fe != nil {
+			fields = append(fields, *fe)
+		}
+	}
+
+	if fields == nil {
+		return nil
+	}
+	return domain.NewValidationError(fields...)
+}
+
+// Value validates a single value, e.g. a query parameter, under name
+func Value(name string, v any, rules string) error {
+	if fe := check(name, reflect.ValueOf(v), strings.Split(rules, ",")); fe != nil {
+		return domain.NewValidationError(*fe)
+	}
+	return nil
+}
+
+func fieldName(sf reflect.StructField) string {
+	for _, key := range []string{"json", "form", "query"} {
+		if name, _, _ := strings.Cut(sf.Tag.Get(key), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return strings.ToLower(sf.Name)
+}
+
+func check(name string, v reflect.Value, rules []string) *domain.FieldError {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			if len(rules) > 0 && rules[0] == "required" {
+				return &domain.FieldError{Field: name, Code: domain.FieldRequired, Message: name + " is required"}
+			}
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	for i, rule := range rules {
+		if rule == "dive" {
+			if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+				panic("validate: dive on " + v.Kind().String())
+			}
+			for j := 0; j < v.Len(); j++ {
+				if fe := check(fmt.Sprintf("%s[%d]", name, j), v.Index(j), rules[i+1:]); fe != nil {
+					return fe
+				}
+			}
+			return nil
+		}
+
+		if rule == "required" {
+			if isEmpty(v) {
+				return &domain.FieldError{Field: name, Code: domain.FieldRequired, Message: name + " is required"}
+			}
+			continue
+		}
+		if isEmpty(v) {
+			continue
+		}
+
+		rule, param, _ := strings.Cut(rule, "=")
+		fn, ok := rulesByName[rule]
+		if !ok {
+			panic("validate: unknown rule " + rule)
+		}
+		if code, msg := fn(v, param); code != "" {
+			return &domain.FieldError{Field: name, Code: code, Message: name + " " + msg}
+		}
+	}
+	return nil
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// A rule returns a field error code and message, or "" when v passes
+type rule func(v reflect.Value, param string) (string, string)
+
+var rulesByName = map[string]rule{
+	"min":      ruleMin,
+	"max":      ruleMax,
+	"len":      ruleLen,
+	"email":    ruleEmail,
+	"uuid":     ruleUUID,
+	"url":      ruleURL,
+	"hex":      ruleHex,
+	"oneof":    ruleOneOf,
+	"password": rulePassword,
+}
+
+// size is the length of a string in characters, of a slice in elements,
+// or a number's value
+func size(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String()))
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	panic("validate: no size for " + v.Kind().String())
+}
+
+func unit(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
+	}
+	return ""
+}
+
+func ruleMin(v reflect.Value, param string) (string, string) {
+	n := mustNumber(param)
+	if size(v) < n {
+		if v.Kind() == reflect.String || v.Kind() == reflect.Slice {
+			return domain.FieldTooShort, "must be at least " + param + unit(v)
+		}
+		return domain.FieldOutOfRange, "must be at least " + param
+	}
+	return "", ""
+}
+
+func ruleMax(v reflect.Value, param string) (string, string) {
+	n := mustNumber(param)
+	if size(v) > n {
+		if v.Kind() == reflect.String || v.Kind() == reflect.Slice {
+			return domain.FieldTooLong, "must be at most " + param + unit(v)
+		}
+		return domain.FieldOutOfRange, "must be at most " + param
+	}
+	return "", ""
+}
+
+func ruleLen(v reflect.Value, param string) (string, string) {
+	if size(v) != mustNumber(param) {
+		return domain.FieldInvalid, "must be exactly " + param + unit(v)
+	}
+	return "", ""
+}
+
+func ruleEmail(v reflect.Value, _ string) (string, string) {
+	addr, err := mail.ParseAddress(v.String())
+	if err != nil || addr.Name != "" || addr.Address != v.String() || !strings.Contains(addr.Address[strings.LastIndex(addr.Address, "@"):], ".") {
+		return domain.FieldInvalid, "must be a valid email address"
+	}
+	return "", ""
+}
+
+func ruleUUID(v reflect.Value, _ string) (string, string) {
+	if _, err := uuid.Parse(v.String()); err != nil {
+		return domain.FieldInvalid, "must be a valid UUID"
+	}
+	return "", ""
+}
+
+func ruleURL(v reflect.Value, _ string) (string, string) {
+	u, err := url.Parse(v.String())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return domain.FieldInvalid, "must be an http or https URL"
+	}
+	return "", ""
+}
+
+func ruleHex(v reflect.Value, _ string) (string, string) {
+	for _, r := range v.String() {
+		if !unicode.Is(unicode.ASCII_Hex_Digit, r) {
+			return domain.FieldInvalid, "must be hexadecimal"
+		}
+	}
+	return "", ""
+}
+
+func ruleOneOf(v reflect.Value, param string) (string, string) {
+	value := fmt.Sprint(v.Interface())
+	for _, allowed := range strings.Fields(param) {
+		if value == allowed {
+			return "", ""
+		}
+	}
+	return domain.FieldNotAllowed, "must be one of " + strings.Join(strings.Fields(param), ", ")
+}
+
+// rulePassword caps the length at 72 bytes, the most bcrypt uses
+func rulePassword(v reflect.Value, _ string) (string, string) {
+	s := v.String()
+	if len(s) < 8 || len(s) > 72 {
+		return domain.FieldWeakPassword, "must be between 8 and 72 characters"
+	}
+	var letter, digit bool
+	for _, r := range s {
+		letter = letter || unicode.IsLetter(r)
+		digit = digit || unicode.IsDigit(r)
+	}
+	if !letter || !digit {
+		return domain.FieldWeakPassword, "must contain at least one letter and one digit"
+	}
+	return "", ""
+}
+
+func mustNumber(param string) float64 {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic("validate: bad rule parameter " + param)
+	}
+	return n
+}
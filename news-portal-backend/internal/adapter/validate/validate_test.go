@@ -0,0 +1,172 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"news-portal-backend/internal/core/domain"
+)
+
+// fieldErrors returns the field errors of a validation error
+func fieldErrors(t *testing.T, err error) []domain.FieldError {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) || !errors.Is(err, domain.ErrInvalidInput) || domainErr.Code != domain.CodeValidationFailed {
+		t.Fatalf("err = %v, want a validation error", err)
+	}
+	return domainErr.Fields
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		rules string
+		code  string
+	}{
+		{"required", "x", "required", ""},
+		{"required empty", "", "required", domain.FieldRequired},
+		{"required blank", " \t", "required", domain.FieldRequired},
+		{"required nil pointer", (*string)(nil), "required", domain.FieldRequired},
+		{"required empty slice", []string{}, "required", domain.FieldRequired},
+
+		{"empty skips rules", "", "min=3,email,uuid", ""},
+		{"nil pointer skips rules", (*string)(nil), "min=3", ""},
+
+		{"min characters", "ab", "min=3", domain.FieldTooShort},
+		{"min counts runes", "ঢাকা", "min=4", ""},
+		{"max characters", "abcd", "max=3", domain.FieldTooLong},
+		{"max counts runes", "বাংলা", "max=5", ""},
+		{"max items", []string{"a", "b", "c"}, "max=2", domain.FieldTooLong},
+		{"min number", 2, "min=5", domain.FieldOutOfRange},
+		{"max number", 101, "max=100", domain.FieldOutOfRange},
+		{"max float", 1.5, "max=1", domain.FieldOutOfRange},
+		{"number in range", int32(50), "min=1,max=100", ""},
+		{"pointer to number", ptr(7), "max=5", domain.FieldOutOfRange},
+		{"len", "abc", "len=4", domain.FieldInvalid},
+		{"len exact", "abcd", "len=4", ""},
+
+		{"email", "name@example.com", "email", ""},
+		{"email with name", "Name <name@example.com>", "email", domain.FieldInvalid},
+		{"email without domain dot", "name@localhost", "email", domain.FieldInvalid},
+		{"email without at", "example.com", "email", domain.FieldInvalid},
+
+		{"uuid", "8f2b9a2e-0c4e-4d7a-9d52-3f3c1e2b7a10", "uuid", ""},
+		{"uuid malformed", "8f2b9a2e", "uuid", domain.FieldInvalid},
+
+		{"url https", "https://example.com/a?b=c", "url", ""},
+		{"url http", "http://example.com", "url", ""},
+		{"url javascript", "javascript:alert(1)", "url", domain.FieldInvalid},
+		{"url relative", "/path", "url", domain.FieldInvalid},
+		{"url without host", "https://", "url", domain.FieldInvalid},
+
+		{"hex", "deadBEEF09", "hex", ""},
+		{"hex invalid", "xyz", "hex", domain.FieldInvalid},
+
+		{"oneof", "popular", "oneof=latest popular", ""},
+		{"oneof other", "random", "oneof=latest popular", domain.FieldNotAllowed},
+		{"oneof number", 2, "oneof=1 2 3", ""},
+
+		{"password", "secret123", "password", ""},
+		{"password short", "abc1", "password", domain.FieldWeakPassword},
+		{"password without digit", "password", "password", domain.FieldWeakPassword},
+		{"password without letter", "12345678", "password", domain.FieldWeakPassword},
+		{"password over bcrypt limit", strings.Repeat("a1", 37), "password", domain.FieldWeakPassword},
+
+		{"first failure wins", "a", "min=3,email", domain.FieldTooShort},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := fieldErrors(t, Value("field", tt.value, tt.rules))
+			if tt.code == "" {
+				if fields != nil {
+					t.Errorf("unexpected errors %+v", fields)
+				}
+				return
+			}
+			if len(fields) != 1 || fields[0].Field != "field" || fields[0].Code != tt.code {
+				t.Errorf("errors = %+v, want one %q error", fields, tt.code)
+			}
+			if !strings.HasPrefix(fields[0].Message, "field ") {
+				t.Errorf("message %q doesn't name the field", fields[0].Message)
+			}
+		})
+	}
+}
+
+func TestStruct(t *testing.T) {
+	type request struct {
+		Name     string   `json:"name" validate:"required,max=10"`
+		Email    string   `json:"email,omitempty" validate:"required,email"`
+		Category string   `form:"category_id" validate:"uuid"`
+		Sort     string   `query:"sort" validate:"oneof=latest oldest"`
+		Tags     []string `json:"tags" validate:"max=3,dive,required,max=5"`
+		Note     string   `validate:"max=2"`
+		Ignored  string
+		hidden   string `validate:"required"`
+	}
+
+	valid := request{Name: "Rahim", Email: "rahim@example.com", Tags: []string{"news"}}
+	if err := Struct(valid); err != nil {
+		t.Fatalf("valid request: %v", err)
+	}
+	if err := Struct(&valid); err != nil {
+		t.Fatalf("pointer to valid request: %v", err)
+	}
+
+	invalid := &request{
+		Email:    "not an email",
+		Category: "42",
+		Sort:     "random",
+		Tags:     []string{"ok", "", "toolong"},
+		Note:     "abc",
+	}
+	got := fieldErrors(t, Struct(invalid))
+	want := []domain.FieldError{
+		{Field: "name", Code: domain.FieldRequired, Message: "name is required"},
+		{Field: "email", Code: domain.FieldInvalid, Message: "email must be a valid email address"},
+		{Field: "category_id", Code: domain.FieldInvalid, Message: "category_id must be a valid UUID"},
+		{Field: "sort", Code: domain.FieldNotAllowed, Message: "sort must be one of latest, oldest"},
+		{Field: "tags[1]", Code: domain.FieldRequired, Message: "tags[1] is required"},
+		{Field: "note", Code: domain.FieldTooLong, Message: "note must be at most 2 characters"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("errors:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestDiveReportsElement(t *testing.T) {
+	fields := fieldErrors(t, Value("tags", []string{"a", "toolong"}, "dive,max=3"))
+	if len(fields) != 1 || fields[0].Field != "tags[1]" || fields[0].Code != domain.FieldTooLong {
+		t.Errorf("errors = %+v, want tags[1] too long", fields)
+	}
+}
+
+func TestMisuse(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"not a struct", func() { Struct("x") }},
+		{"unknown rule", func() { Value("f", "x", "nonsense") }},
+		{"bad parameter", func() { Value("f", "x", "max=ten") }},
+		{"dive on a string", func() { Value("f", "x", "dive,max=1") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -25,25 +25,111 @@ type Category struct {
 }
 
 type News struct {
-	ID              uuid.UUID `json:"id"`
-	AuthorID        uuid.UUID `json:"author_id"`
-	CategoryID      uuid.UUID `json:"category_id"`
-	Title           string    `json:"title"`
-	Excerpt         *string   `json:"excerpt"`
-	Content         string    `json:"content,omitempty"`
-	Thumbnail       string    `json:"thumbnail"`
-	Slug            string    `json:"slug"`
-	Status          string    `json:"status"`
-	IsFeatured      bool      `json:"is_featured"`
-	MetaTitle       *string   `json:"meta_title"`
-	MetaDescription *string   `json:"meta_description"`
-	ViewsCount      int64     `json:"views_count"`
-	PublishedAt     time.Time `json:"published_at"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              uuid.UUID      `json:"id"`
+	AuthorID        uuid.UUID      `json:"author_id"`
+	CategoryID      uuid.UUID      `json:"category_id"`
+	Title           string         `json:"title"`
+	Excerpt         *string        `json:"excerpt"`
+	Content         string         `json:"content,omitempty"`
+	Thumbnail       string         `json:"thumbnail"`
+	ThumbnailInfo   *ImageInfo     `json:"thumbnail_info"`
+	ThumbnailMeta   *ThumbnailMeta `json:"thumbnail_meta"`
+	Slug            string         `json:"slug"`
+	Status          string         `json:"status"`
+	IsFeatured      bool           `json:"is_featured"`
+	MetaTitle       *string        `json:"meta_title"`
+	MetaDescription *string        `json:"meta_description"`
+	ViewsCount      int64          `json:"views_count"`
+	RawViewsCount   int64          `json:"raw_views_count"`
+	PublishedAt     time.Time      `json:"published_at"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
 
 	// Joined fields for easier frontend rendering
 	AuthorName   *string `json:"author_name,omitempty"`
 	CategoryName *string `json:"category_name,omitempty"`
 	CategorySlug *string `json:"category_slug,omitempty"`
 }
+
+// ImageInfo describes an uploaded image so clients can reserve space and
+// paint a placeholder before the real file arrives.
+type ImageInfo struct {
+	Width         int    `json:"width"`
+	Height        int    `json:"height"`
+	BlurHash      string `json:"blurhash,omitempty"`
+	DominantColor string `json:"dominant_color,omitempty"`
+}
+
+// ThumbnailMeta holds the alt text, caption and attribution for a thumbnail
+type ThumbnailMeta struct {
+	Alt       string `json:"alt"`
+	Caption   string `json:"caption"`
+	Credit    string `json:"credit"`
+	SourceURL string `json:"source_url"`
+}
+
+// Media status values
+const (
+	MediaStatusPending  = "pending"
+	MediaStatusReady    = "ready"
+	MediaStatusRejected = "rejected"
+)
+
+type Media struct {
+	ID          uuid.UUID  `json:"id"`
+	OwnerID     uuid.UUID  `json:"owner_id"`
+	ObjectKey   string     `json:"object_key"`
+	URL         string     `json:"url"`
+	Filename    string     `json:"filename"`
+	ContentType string     `json:"content_type"`
+	SizeBytes   int64      `json:"size_bytes"`
+	SHA256      string     `json:"sha256,omitempty"`
+	Image       *ImageInfo `json:"image,omitempty"`
+	Status      string     `json:"status"`
+	ReuseCount  int64      `json:"reuse_count"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Report frequencies
+const (
+	ReportDaily  = "daily"
+	ReportWeekly = "weekly"
+)
+
+// Report run statuses
+const (
+	ReportRunRunning = "running"
+	ReportRunSent    = "sent"
+	ReportRunFailed  = "failed"
+)
+
+// ReportSchedule sends an editorial digest to a list of recipients every day,
+// or every week on Weekday, at SendHour:SendMinute in Timezone.
+type ReportSchedule struct {
+	ID              uuid.UUID  `json:"id"`
+	OwnerID         uuid.UUID  `json:"owner_id"`
+	Name            string     `json:"name"`
+	Recipients      []string   `json:"recipients"`
+	Frequency       string     `json:"frequency"`
+	Weekday         int        `json:"weekday"`
+	SendHour        int        `json:"send_hour"`
+	SendMinute      int        `json:"send_minute"`
+	Timezone        string     `json:"timezone"`
+	Enabled         bool       `json:"enabled"`
+	LastScheduledAt *time.Time `json:"last_scheduled_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type ReportRun struct {
+	ID         uuid.UUID  `json:"id"`
+	ScheduleID uuid.UUID  `json:"schedule_id"`
+	Status     string     `json:"status"`
+	PeriodFrom time.Time  `json:"period_from"`
+	PeriodTo   time.Time  `json:"period_to"`
+	Recipients []string   `json:"recipients"`
+	Error      *string    `json:"error"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
@@ -2,8 +2,101 @@ package domain
 
 import "errors"
 
+// Error kinds. Every error a service returns to a handler should match one
+// of these with errors.Is; anything else is reported as an internal error.
 var (
-	ErrNotFound = errors.New("resource not found")
-	ErrConflict = errors.New("resource already exists")
-	ErrInternal = errors.New("internal server error")
+	ErrNotFound     = errors.New("resource not found")
+	ErrConflict     = errors.New("resource already exists")
+	ErrInternal     = errors.New("internal server error")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrBadRequest   = errors.New("malformed request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrTooLarge     = errors.New("request body too large")
+	ErrRateLimited  = errors.New("too many requests")
+)
+
+// Error codes are stable identifiers clients can switch on. They never
+// change once published, unlike messages.
+const (
+	CodeBadRequest         = "bad_request"
+	CodeInvalidInput       = "invalid_input"
+	CodeValidationFailed   = "validation_failed"
+	CodeUnauthorized       = "unauthorized"
+	CodeForbidden          = "forbidden"
+	CodeNotFound           = "not_found"
+	CodeConflict           = "conflict"
+	CodeInternal           = "internal_error"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeInvalidOldPassword = "invalid_old_password"
+	CodeRateLimited        = "rate_limited"
+	CodeMethodNotAllowed   = "method_not_allowed"
+	CodeTooLarge           = "payload_too_large"
+)
+
+// Field error codes
+const (
+	FieldRequired     = "required"
+	FieldInvalid      = "invalid"
+	FieldTaken        = "taken"
+	FieldNotFound     = "not_found"
+	FieldTooShort     = "too_short"
+	FieldTooLong      = "too_long"
+	FieldOutOfRange   = "out_of_range"
+	FieldNotAllowed   = "not_allowed"
+	FieldWeakPassword = "weak_password"
+	FieldUnknown      = "unknown"
+)
+
+// Error is a domain error with a stable code and a message that is safe to
+// show to clients. Kind is one of the error kinds above, so errors.Is(err,
+// ErrNotFound) and the like keep working.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	Fields  []FieldError
+}
+
+// FieldError describes one invalid input field
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func NewError(kind error, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// NewValidationError reports invalid input fields
+func NewValidationError(fields ...FieldError) *Error {
+	return &Error{
+		Kind:    ErrInvalidInput,
+		Code:    CodeValidationFailed,
+		Message: "validation failed",
+		Fields:  fields,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+var (
+	ErrInvalidCredentials = NewError(ErrUnauthorized, CodeInvalidCredentials, "invalid credentials")
+	ErrTooManyLogins      = NewError(ErrRateLimited, CodeRateLimited, "too many login attempts for this account, try again later")
+
+	// A wrong current password is a field error rather than a 401, which
+	// clients take to mean the session has expired
+	ErrInvalidOldPassword = &Error{
+		Kind:    ErrInvalidInput,
+		Code:    CodeInvalidOldPassword,
+		Message: "invalid old password",
+		Fields:  []FieldError{{Field: "old_password", Code: FieldInvalid, Message: "current password is incorrect"}},
+	}
 )
@@ -2,7 +2,11 @@ package port
 
 import (
 	"context"
+	"errors"
+	"io"
+	"log/slog"
 	"mime/multipart"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -33,13 +37,187 @@ type NewsRepository interface {
 	UpdateNews(ctx context.Context, news *domain.News) error
 	DeleteNews(ctx context.Context, id uuid.UUID) error
 	GetNewsBySlug(ctx context.Context, slug string) (*domain.News, error)
+	GetNewsByID(ctx context.Context, id uuid.UUID) (*domain.News, error)
+	// GetNewsViewsCount reads an article's current unique view total
+	GetNewsViewsCount(ctx context.Context, id uuid.UUID) (int64, error)
 	ListNews(ctx context.Context, limit, offset int32, categoryID *uuid.UUID, authorID *uuid.UUID, sortBy string, isFeatured *bool, search string) ([]*domain.News, error)
-	IncrementNewsViews(ctx context.Context, slug string) error
+	StreamNews(ctx context.Context, categoryID *uuid.UUID, authorID *uuid.UUID, sortBy string, isFeatured *bool, search string, fn func(*domain.News) error) error
+	// AddNewsViews applies buffered view increments to the article totals
+	// and their hourly buckets
+	AddNewsViews(ctx context.Context, counts map[ViewKey]ViewDelta) error
 	CheckSlugExists(ctx context.Context, slug string) (bool, error)
 	CountNews(ctx context.Context, categoryID *uuid.UUID, authorID *uuid.UUID, isFeatured *bool, search string) (int64, error)
-	CountTotalViews(ctx context.Context) (int64, error)
-	GetCategoryViewStats(ctx context.Context) ([]CategoryViewStat, error)
-	GetMonthlyTopNews(ctx context.Context, limit int) ([]NewsViewStat, error)
+	CountTotalViews(ctx context.Context, categoryID *uuid.UUID, authorID *uuid.UUID) (int64, error)
+	CountTotalRawViews(ctx context.Context, categoryID *uuid.UUID, authorID *uuid.UUID) (int64, error)
+	// GetCategoryViewStats and GetTopNews rank by views recorded within the
+	// filter's period
+	GetCategoryViewStats(ctx context.Context, filter StatsFilter) ([]CategoryViewStat, error)
+	GetTopNews(ctx context.Context, filter StatsFilter, limit int) ([]NewsViewStat, error)
+}
+
+type MediaRepository interface {
+	CreateMedia(ctx context.Context, media *domain.Media) (*domain.Media, error)
+	GetMediaByID(ctx context.Context, id uuid.UUID) (*domain.Media, error)
+	UpdateMediaStatus(ctx context.Context, id uuid.UUID, status string) error
+	// FindReadyMediaByHash returns finished media with identical content,
+	// limited to one owner's uploads when ownerID is set
+	FindReadyMediaByHash(ctx context.Context, sha256 string, size int64, ownerID *uuid.UUID) (*domain.Media, error)
+	SetMediaHash(ctx context.Context, id uuid.UUID, sha256 string) error
+	DeleteMedia(ctx context.Context, id uuid.UUID) error
+	IncrementMediaReuse(ctx context.Context, id uuid.UUID) error
+	GetMediaDedupStats(ctx context.Context) (*MediaDedupStat, error)
+}
+
+type ViewCounter interface {
+	RecordView(newsID uuid.UUID, viewer Viewer)
+	RecordShare(newsID uuid.UUID, network string, viewer Viewer)
+}
+
+// Viewer describes the request behind an article view
+type Viewer struct {
+	IP        string
+	UserAgent string
+	// Prefetch is set for speculative loads that the user never saw
+	Prefetch bool
+	// Referrer is the page the visitor came from, as reported by the client
+	Referrer    string
+	UTMSource   string
+	UTMMedium   string
+	UTMCampaign string
+}
+
+// ViewKey identifies the hourly bucket a view is counted in
+type ViewKey struct {
+	NewsID uuid.UUID
+	Hour   time.Time
+}
+
+// ViewDelta is a pending increment for an article's view counters
+type ViewDelta struct {
+	Raw    int64
+	Unique int64
+}
+
+func (d ViewDelta) Add(o ViewDelta) ViewDelta {
+	return ViewDelta{Raw: d.Raw + o.Raw, Unique: d.Unique + o.Unique}
+}
+
+type AnalyticsRepository interface {
+	GetViewTimeSeries(ctx context.Context, filter TimeSeriesFilter) ([]TimeSeriesPoint, error)
+	// RollupHourlyViews compacts hourly buckets older than before into daily rows
+	RollupHourlyViews(ctx context.Context, before time.Time) (int64, error)
+	// RefreshTrendingScores recomputes news.trending_score from views in the
+	// last window, decaying each hour's views with the given half-life
+	RefreshTrendingScores(ctx context.Context, halfLife, window time.Duration) error
+	GetPeriodStats(ctx context.Context, filter StatsFilter) (*PeriodStats, error)
+	GetAuthorStats(ctx context.Context, filter StatsFilter) ([]AuthorStat, error)
+	AddTrafficCounts(ctx context.Context, counts map[TrafficKey]int64) error
+	GetTrafficBreakdown(ctx context.Context, filter TrafficFilter) (map[string][]TrafficStat, error)
+}
+
+// Traffic dimensions recorded per article and day
+const (
+	TrafficReferrer    = "referrer"
+	TrafficUTMSource   = "utm_source"
+	TrafficUTMMedium   = "utm_medium"
+	TrafficUTMCampaign = "utm_campaign"
+	TrafficShare       = "share"
+)
+
+// TrafficKey identifies one daily traffic counter for an article
+type TrafficKey struct {
+	NewsID    uuid.UUID
+	Day       time.Time
+	Dimension string
+	Value     string
+}
+
+type ReportRepository interface {
+	CreateReportSchedule(ctx context.Context, schedule *domain.ReportSchedule) (*domain.ReportSchedule, error)
+	GetReportSchedule(ctx context.Context, id uuid.UUID) (*domain.ReportSchedule, error)
+	ListReportSchedules(ctx context.Context, ownerID uuid.UUID) ([]*domain.ReportSchedule, error)
+	ListEnabledReportSchedules(ctx context.Context) ([]*domain.ReportSchedule, error)
+	UpdateReportSchedule(ctx context.Context, schedule *domain.ReportSchedule) error
+	DeleteReportSchedule(ctx context.Context, id uuid.UUID) error
+	// ClaimReportSchedule records that the run scheduled for the given time
+	// has started. It returns false if it was already claimed, so only one
+	// API instance sends each report.
+	ClaimReportSchedule(ctx context.Context, id uuid.UUID, scheduledFor time.Time) (bool, error)
+	CreateReportRun(ctx context.Context, run *domain.ReportRun) (*domain.ReportRun, error)
+	FinishReportRun(ctx context.Context, id uuid.UUID, status string, errMsg *string) error
+	ListReportRuns(ctx context.Context, scheduleID uuid.UUID, limit int) ([]*domain.ReportRun, error)
+}
+
+// Cache stores serialized values with an expiry. The in-memory
+// implementation can be swapped for a shared cache such as Redis.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// RateLimitStore counts requests per key. API replicas that share a store
+// share their limits; the in-memory implementation limits each replica on
+// its own.
+type RateLimitStore interface {
+	// TakeRateLimit counts one request for key against a limit of limit
+	// requests per window. Rejected requests are not counted.
+	TakeRateLimit(ctx context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error)
+	// CheckRateLimit reports what TakeRateLimit would return without
+	// counting a request
+	CheckRateLimit(ctx context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error)
+}
+
+// ErrRateLimitStoreUnavailable wraps RateLimitStore errors caused by not
+// reaching the store, as opposed to the store rejecting the request
+var ErrRateLimitStoreUnavailable = errors.New("rate limit store unavailable")
+
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// Reset is how long until the full limit is available again
+	Reset time.Duration
+	// RetryAfter is how long a rejected client should wait
+	RetryAfter time.Duration
+}
+
+// Surrogate keys tag public responses so a CDN can purge every cached page
+// that shows a given article or listing
+const (
+	SurrogateKeyHomepage   = "homepage"
+	SurrogateKeyNewsList   = "news-list"
+	SurrogateKeyCategories = "categories"
+)
+
+// SurrogateKeyForNews tags every response that includes the article
+func SurrogateKeyForNews(id uuid.UUID) string {
+	return "news-" + id.String()
+}
+
+// CachePurge names the CDN entries to evict. URLs are paths relative to the
+// API's public base URL, e.g. /api/v1/news/homepage.
+type CachePurge struct {
+	URLs          []string
+	SurrogateKeys []string
+}
+
+// CachePurger evicts responses from a CDN or reverse proxy in front of the
+// API
+type CachePurger interface {
+	Purge(ctx context.Context, purge CachePurge) error
+}
+
+// Mailer delivers email. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(ctx context.Context, msg MailMessage) error
+}
+
+type MailMessage struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
 }
 
 type AuthService interface {
@@ -51,11 +229,16 @@ type AuthService interface {
 }
 
 type NewsService interface {
-	CreateNews(ctx context.Context, authorID, categoryID uuid.UUID, title, excerpt, content, thumbnail string, isFeatured bool) (*domain.News, error)
-	UpdateNews(ctx context.Context, id uuid.UUID, categoryID uuid.UUID, title, excerpt, content, thumbnail string, isFeatured bool) error
+	// allowMissingAlt lets an editor publish a thumbnail without alt text
+	CreateNews(ctx context.Context, authorID, categoryID uuid.UUID, title, excerpt, content, thumbnail string, thumbnailInfo *domain.ImageInfo, thumbnailMeta domain.ThumbnailMeta, isFeatured, allowMissingAlt bool) (*domain.News, error)
+	UpdateNews(ctx context.Context, id uuid.UUID, categoryID uuid.UUID, title, excerpt, content, thumbnail string, thumbnailInfo *domain.ImageInfo, thumbnailMeta domain.ThumbnailMeta, isFeatured, allowMissingAlt bool) error
 	DeleteNews(ctx context.Context, id uuid.UUID) error
-	GetNewsBySlug(ctx context.Context, slug string) (*domain.News, error)
+	GetNewsBySlug(ctx context.Context, slug string, viewer Viewer) (*domain.News, error)
+	// RecordShare counts a share button click on an existing article
+	RecordShare(ctx context.Context, id uuid.UUID, network string, viewer Viewer) error
 	ListNews(ctx context.Context, page, limit int32, categorySlug string, authorID *uuid.UUID, sortBy string, isFeatured *bool, search string) ([]*domain.News, int64, error)
+	// ExportNews passes every article matching the ListNews filters to fn
+	ExportNews(ctx context.Context, categorySlug string, authorID *uuid.UUID, sortBy string, isFeatured *bool, search string, fn func(*domain.News) error) error
 	CheckSlug(ctx context.Context, slug string) (bool, error)
 	GetHomepageData(ctx context.Context) (*HomepageData, error)
 }
@@ -68,7 +251,60 @@ type CategoryService interface {
 }
 
 type FileService interface {
-	UploadFile(file multipart.File, header *multipart.FileHeader) (string, error)
+	// PutObject streams body, which must be exactly size bytes, to key
+	PutObject(ctx context.Context, key, contentType string, body io.Reader, size int64) error
+	// PresignUpload returns a URL the client can PUT the object to directly.
+	// When sha256 (hex) is set the storage backend rejects any other content.
+	PresignUpload(ctx context.Context, key, contentType string, size int64, sha256 string, ttl time.Duration) (*PresignedRequest, error)
+	// StatObject reports the object's size and the content type sniffed from
+	// its first bytes. The type the client uploaded it with is never trusted.
+	StatObject(ctx context.Context, key string) (*ObjectInfo, error)
+	OpenObject(ctx context.Context, key string) (io.ReadCloser, error)
+	DeleteObject(ctx context.Context, key string) error
+	ObjectURL(key string) string
+	// Ping checks that the storage backend is reachable
+	Ping(ctx context.Context) error
+}
+
+type MediaService interface {
+	// UploadFile stores a form upload whose sniffed content type starts
+	// with accept, such as "image/" for thumbnails
+	UploadFile(ctx context.Context, ownerID uuid.UUID, file multipart.File, header *multipart.FileHeader, accept string) (*UploadedFile, error)
+	RequestUpload(ctx context.Context, ownerID uuid.UUID, filename, contentType string, size int64, sha256 string) (*MediaUpload, error)
+	CompleteUpload(ctx context.Context, ownerID, id uuid.UUID) (*domain.Media, error)
+}
+
+type UploadedFile struct {
+	Key string
+	URL string
+	// Image is nil when the upload isn't a decodable image
+	Image *domain.ImageInfo
+}
+
+type PresignedRequest struct {
+	URL     string
+	Headers map[string]string
+}
+
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+}
+
+type MediaUpload struct {
+	Media *domain.Media `json:"media"`
+	// Duplicate is set when identical content is already stored; the
+	// existing media is returned and nothing needs to be uploaded.
+	Duplicate bool              `json:"duplicate"`
+	UploadURL string            `json:"upload_url,omitempty"`
+	Method    string            `json:"method,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	ExpiresAt *time.Time        `json:"expires_at,omitempty"`
+}
+
+type MediaDedupStat struct {
+	DuplicateUploads int64 `json:"duplicate_uploads"`
+	BytesSaved       int64 `json:"bytes_saved"`
 }
 
 type CategoryViewStat struct {
@@ -93,10 +329,178 @@ type DashboardStats struct {
 	TotalCategories int64              `json:"total_categories"`
 	TotalUsers      int64              `json:"total_users"`
 	TotalViews      int64              `json:"total_views"`
+	TotalRawViews   int64              `json:"total_raw_views"`
 	CategoryStats   []CategoryViewStat `json:"category_stats"`
 	TopNews         []NewsViewStat     `json:"top_news"`
+	MediaDedup      *MediaDedupStat    `json:"media_dedup"`
+	TopReferrers    []TrafficStat      `json:"top_referrers"`
+	Period          PeriodStats        `json:"period"`
+	PreviousPeriod  PeriodStats        `json:"previous_period"`
+	Deltas          PeriodDeltas       `json:"deltas"`
+	Authors         []AuthorStat       `json:"authors"`
+}
+
+// StatsFilter narrows dashboard stats to a period and optionally to one
+// category or author
+type StatsFilter struct {
+	From       time.Time
+	To         time.Time
+	CategoryID *uuid.UUID
+	AuthorID   *uuid.UUID
+}
+
+type PeriodStats struct {
+	From              time.Time `json:"from"`
+	To                time.Time `json:"to"`
+	ArticlesPublished int64     `json:"articles_published"`
+	Views             int64     `json:"views"`
+	RawViews          int64     `json:"raw_views"`
+}
+
+// StatDelta compares a period with the one before it. Percent is nil when
+// the previous period was zero.
+type StatDelta struct {
+	Change  int64    `json:"change"`
+	Percent *float64 `json:"percent"`
+}
+
+type PeriodDeltas struct {
+	ArticlesPublished StatDelta `json:"articles_published"`
+	Views             StatDelta `json:"views"`
+	RawViews          StatDelta `json:"raw_views"`
+}
+
+type AuthorStat struct {
+	ID                 string  `json:"id"`
+	Name               string  `json:"name"`
+	ArticlesPublished  int64   `json:"articles_published"`
+	Views              int64   `json:"views"`
+	AvgViewsPerArticle float64 `json:"avg_views_per_article"`
+}
+
+// Time series granularities
+const (
+	GranularityHour = "hour"
+	GranularityDay  = "day"
+)
+
+type TimeSeriesFilter struct {
+	NewsID      *uuid.UUID
+	CategoryID  *uuid.UUID
+	From        time.Time
+	To          time.Time
+	Granularity string
+}
+
+type TimeSeriesPoint struct {
+	Bucket   time.Time `json:"bucket"`
+	Views    int64     `json:"views"`
+	RawViews int64     `json:"raw_views"`
+}
+
+type TimeSeries struct {
+	From        time.Time         `json:"from"`
+	To          time.Time         `json:"to"`
+	Granularity string            `json:"granularity"`
+	Points      []TimeSeriesPoint `json:"points"`
+}
+
+type TrafficFilter struct {
+	NewsID     *uuid.UUID
+	CategoryID *uuid.UUID
+	AuthorID   *uuid.UUID
+	From       time.Time
+	To         time.Time
+	// Limit caps the number of values returned per dimension
+	Limit int
+}
+
+type TrafficStat struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type TrafficBreakdown struct {
+	From       time.Time     `json:"from"`
+	To         time.Time     `json:"to"`
+	Referrers  []TrafficStat `json:"referrers"`
+	UTMSources []TrafficStat `json:"utm_sources"`
+	UTMMediums []TrafficStat `json:"utm_mediums"`
+	Campaigns  []TrafficStat `json:"utm_campaigns"`
+	Shares     []TrafficStat `json:"shares"`
+}
+
+type ReportScheduleInput struct {
+	Name       string   `json:"name" validate:"required,max=255"`
+	Recipients []string `json:"recipients" validate:"required,max=50,dive,max=255,email"`
+	Frequency  string   `json:"frequency" validate:"oneof=daily weekly"`
+	Weekday    int      `json:"weekday" validate:"min=0,max=6"`
+	SendHour   int      `json:"send_hour" validate:"min=0,max=23"`
+	SendMinute int      `json:"send_minute" validate:"min=0,max=59"`
+	Timezone   string   `json:"timezone" validate:"max=64"`
+	Enabled    *bool    `json:"enabled"`
+}
+
+type ReportService interface {
+	CreateSchedule(ctx context.Context, ownerID uuid.UUID, input ReportScheduleInput) (*domain.ReportSchedule, error)
+	UpdateSchedule(ctx context.Context, ownerID, id uuid.UUID, input ReportScheduleInput) (*domain.ReportSchedule, error)
+	DeleteSchedule(ctx context.Context, ownerID, id uuid.UUID) error
+	ListSchedules(ctx context.Context, ownerID uuid.UUID) ([]*domain.ReportSchedule, error)
+	ListRuns(ctx context.Context, ownerID, scheduleID uuid.UUID) ([]*domain.ReportRun, error)
+	// SendNow sends a schedule's report for its most recent complete period
+	SendNow(ctx context.Context, ownerID, scheduleID uuid.UUID) (*domain.ReportRun, error)
 }
 
 type StatsService interface {
-	GetDashboardStats(ctx context.Context) (*DashboardStats, error)
+	GetDashboardStats(ctx context.Context, filter StatsFilter) (*DashboardStats, error)
+	GetViewTimeSeries(ctx context.Context, filter TimeSeriesFilter) (*TimeSeries, error)
+	GetTrafficBreakdown(ctx context.Context, filter TrafficFilter) (*TrafficBreakdown, error)
+}
+
+// Health statuses
+const (
+	HealthOK          = "ok"
+	HealthUnavailable = "unavailable"
+	HealthDraining    = "draining"
+)
+
+// HealthCheck is one dependency the readiness probe checks
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// DependencyHealth is one check's result. The probe is public, so failure
+// details are logged rather than returned.
+type DependencyHealth struct {
+	Status     string `json:"status"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+type HealthReport struct {
+	Status string                      `json:"status"`
+	Checks map[string]DependencyHealth `json:"checks,omitempty"`
+}
+
+type HealthService interface {
+	// Ready checks every dependency. It reports draining without checking
+	// anything once the server has started shutting down.
+	Ready(ctx context.Context) *HealthReport
+}
+
+type loggerKey struct{}
+
+// WithLogger returns a context carrying a request-scoped logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the request-scoped logger, which already carries the
+// request ID and, once authenticated, the user ID. Outside a request it
+// returns the default logger.
+func Logger(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
 }
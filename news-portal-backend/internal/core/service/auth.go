@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
-	"errors"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -16,27 +18,45 @@ import (
 type AuthService struct {
 	repo      port.OwnerRepository
 	jwtSecret string
+
+	// Failed logins per account, whatever IPs they come from
+	attempts      port.RateLimitStore
+	maxAttempts   int
+	attemptWindow time.Duration
 }
 
-func NewAuthService(repo port.OwnerRepository, jwtSecret string) *AuthService {
+// NewAuthService allows maxAttempts failed logins per account and window,
+// so a brute force spread over many IPs still hits a limit while the owner
+// can keep signing in. A nil store disables the per-account limit.
+func NewAuthService(repo port.OwnerRepository, jwtSecret string, attempts port.RateLimitStore, maxAttempts int, window time.Duration) *AuthService {
 	return &AuthService{
-		repo:      repo,
-		jwtSecret: jwtSecret,
+		repo:          repo,
+		jwtSecret:     jwtSecret,
+		attempts:      attempts,
+		maxAttempts:   maxAttempts,
+		attemptWindow: window,
 	}
 }
 
 func (s *AuthService) Login(ctx context.Context, email, password string) (string, error) {
+	key := loginAttemptKey(email)
+	if err := s.checkLoginAttempts(ctx, key); err != nil {
+		return "", err
+	}
+
 	owner, err := s.repo.GetOwnerByEmail(ctx, email)
 	if err != nil {
 		return "", err
 	}
 	if owner == nil {
-		return "", errors.New("invalid credentials")
+		s.countFailedLogin(ctx, key)
+		return "", domain.ErrInvalidCredentials
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(owner.Password), []byte(password))
 	if err != nil {
-		return "", errors.New("invalid credentials")
+		s.countFailedLogin(ctx, key)
+		return "", domain.ErrInvalidCredentials
 	}
 
 	// Generate JWT
@@ -48,6 +68,41 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (string
 	return token.SignedString([]byte(s.jwtSecret))
 }
 
+// loginAttemptKey names the account's limit by a hash of the email, so the
+// key has a fixed length whatever the client sends
+func loginAttemptKey(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return "login-account:" + hex.EncodeToString(sum[:])
+}
+
+// checkLoginAttempts rejects the login once the account has used up its
+// failed attempts. A failing store lets the attempt through; the per-IP
+// limit still applies.
+func (s *AuthService) checkLoginAttempts(ctx context.Context, key string) error {
+	if s.attempts == nil {
+		return nil
+	}
+	result, err := s.attempts.CheckRateLimit(ctx, key, s.maxAttempts, s.attemptWindow)
+	if err != nil {
+		port.Logger(ctx).Warn("Login attempt limit check failed", "error", err)
+		return nil
+	}
+	if !result.Allowed {
+		return domain.ErrTooManyLogins
+	}
+	return nil
+}
+
+// countFailedLogin counts a wrong email or password against the account
+func (s *AuthService) countFailedLogin(ctx context.Context, key string) {
+	if s.attempts == nil {
+		return
+	}
+	if _, err := s.attempts.TakeRateLimit(ctx, key, s.maxAttempts, s.attemptWindow); err != nil {
+		port.Logger(ctx).Warn("Counting failed login failed", "error", err)
+	}
+}
+
 func (s *AuthService) Register(ctx context.Context, name, email, password string) (*domain.Owner, error) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -63,13 +118,13 @@ func (s *AuthService) ChangePassword(ctx context.Context, id uuid.UUID, oldPassw
 		return err
 	}
 	if owner == nil {
-		return errors.New("user not found")
+		return domain.ErrNotFound
 	}
 
 	// Verify old password
 	err = bcrypt.CompareHashAndPassword([]byte(owner.Password), []byte(oldPassword))
 	if err != nil {
-		return errors.New("invalid old password")
+		return domain.ErrInvalidOldPassword
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"news-portal-backend/internal/core/domain"
+)
+
+// ownerRepo is an in-memory port.OwnerRepository
+type ownerRepo struct {
+	owners map[string]*domain.Owner
+}
+
+func (r *ownerRepo) CreateOwner(ctx context.Context, name, email, passwordHash string) (*domain.Owner, error) {
+	owner := &domain.Owner{ID: uuid.New(), Name: name, Email: email, Password: passwordHash}
+	r.owners[email] = owner
+	return owner, nil
+}
+
+func (r *ownerRepo) GetOwnerByEmail(ctx context.Context, email string) (*domain.Owner, error) {
+	return r.owners[email], nil
+}
+
+func (r *ownerRepo) GetOwnerByID(ctx context.Context, id uuid.UUID) (*domain.Owner, error) {
+	for _, o := range r.owners {
+		if o.ID == id {
+			return o, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *ownerRepo) CountOwners(ctx context.Context) (int64, error) { return int64(len(r.owners)), nil }
+
+func (r *ownerRepo) ListOwners(ctx context.Context) ([]*domain.Owner, error) { return nil, nil }
+
+func (r *ownerRepo) UpdateOwnerPassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	return nil
+}
+
+func newTestAuthService(t *testing.T, maxAttempts int) *AuthService {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &ownerRepo{owners: map[string]*domain.Owner{
+		"editor@news.com": {ID: uuid.New(), Email: "editor@news.com", Password: string(hash)},
+	}}
+	return NewAuthService(repo, "secret", NewMemoryRateLimitStore(), maxAttempts, time.Hour)
+}
+
+func TestLoginLimitsAttemptsPerAccount(t *testing.T) {
+	s := newTestAuthService(t, 3)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.Login(ctx, "editor@news.com", "wrong"); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: err = %v, want invalid credentials", i, err)
+		}
+	}
+	// Case and whitespace don't make a new account key, and the right
+	// password doesn't get past the limit either
+	if _, err := s.Login(ctx, " Editor@News.com", "correct horse"); !errors.Is(err, domain.ErrRateLimited) {
+		t.Fatalf("err = %v, want rate limited", err)
+	}
+}
+
+func TestLoginSucceedsWithinLimit(t *testing.T) {
+	s := newTestAuthService(t, 3)
+	token, err := s.Login(context.Background(), "editor@news.com", "correct horse")
+	if err != nil || token == "" {
+		t.Fatalf("token = %q, err = %v", token, err)
+	}
+}
+
+// Only failed logins count, so the owner signing in often doesn't lock the
+// account
+func TestLoginCountsOnlyFailures(t *testing.T) {
+	s := newTestAuthService(t, 2)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		if _, err := s.Login(ctx, "editor@news.com", "correct horse"); err != nil {
+			t.Fatalf("login %d: %v", i, err)
+		}
+	}
+	if _, err := s.Login(ctx, "editor@news.com", "wrong"); !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want invalid credentials", err)
+	}
+	if _, err := s.Login(ctx, "editor@news.com", "correct horse"); err != nil {
+		t.Fatalf("login after one failure: %v", err)
+	}
+}
+
+func TestLoginWithoutAttemptStore(t *testing.T) {
+	s := newTestAuthService(t, 1)
+	s.attempts = nil
+	for i := 0; i < 3; i++ {
+		if _, err := s.Login(context.Background(), "editor@news.com", "wrong"); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: err = %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"news-portal-backend/internal/core/port"
+)
+
+// HTTPCachePurger posts purge requests to a CDN purge endpoint or a small
+// adapter in front of one. The body is
+//
+//	{"urls": ["https://api.example.com/api/v1/news/homepage"], "surrogate_keys": ["homepage"]}
+//
+// and any non-2xx answer is treated as a failure.
+type HTTPCachePurger struct {
+	endpoint string
+	token    string
+	baseURL  string
+	client   *http.Client
+}
+
+// NewHTTPCachePurger creates a purger posting to endpoint. Paths are made
+// absolute with baseURL, and token, when set, is sent as a bearer token.
+func NewHTTPCachePurger(endpoint, token, baseURL string) *HTTPCachePurger {
+	return &HTTPCachePurger{
+		endpoint: endpoint,
+		token:    token,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *HTTPCachePurger) Purge(ctx context.Context, purge port.CachePurge) error {
+	urls := make([]string, 0, len(purge.URLs))
+	for _, u := range purge.URLs {
+		urls = append(urls, p.baseURL+u)
+	}
+	body, err := json.Marshal(map[string][]string{
+		"urls":           urls,
+		"surrogate_keys": purge.SurrogateKeys,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("purge endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// NoopCachePurger is used when no CDN is configured
+type NoopCachePurger struct{}
+
+func (NoopCachePurger) Purge(ctx context.Context, purge port.CachePurge) error {
+	return nil
+}
+
+// RecordingCachePurger keeps every purge it receives. It stands in for a
+// CDN in tests.
+type RecordingCachePurger struct {
+	mu     sync.Mutex
+	purges []port.CachePurge
+}
+
+func (p *RecordingCachePurger) Purge(ctx context.Context, purge port.CachePurge) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.purges = append(p.purges, purge)
+	return nil
+}
+
+// Purges returns a copy of the purges recorded so far
+func (p *RecordingCachePurger) Purges() []port.CachePurge {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]port.CachePurge(nil), p.purges...)
+}
+
+// PurgeQueue sends purges in the background and retries failures with
+// exponential backoff, so a CDN outage never fails a CMS save. Purges that
+// still fail after maxAttempts, or arrive while the queue is full, are
+// logged and dropped; the CDN TTLs bound how long content stays stale.
+type PurgeQueue struct {
+	purger      port.CachePurger
+	maxAttempts int
+	backoff     time.Duration
+
+	queue   chan purgeTask
+	stop    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+type purgeTask struct {
+	purge   port.CachePurge
+	attempt int
+}
+
+// NewPurgeQueue creates a queue holding up to size pending purges. The
+// first retry waits backoff, and each further retry twice as long.
+func NewPurgeQueue(purger port.CachePurger, size, maxAttempts int, backoff time.Duration) *PurgeQueue {
+	return &PurgeQueue{
+		purger:      purger,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		queue:       make(chan purgeTask, size),
+		stop:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+}
+
+// Purge queues the purge and returns immediately
+func (q *PurgeQueue) Purge(ctx context.Context, purge port.CachePurge) error {
+	q.enqueue(purgeTask{purge: purge})
+	return nil
+}
+
+func (q *PurgeQueue) enqueue(task purgeTask) {
+	select {
+	case q.queue <- task:
+	default:
+		slog.Warn("Cache purge queue full, dropping purge", "urls", task.purge.URLs, "keys", task.purge.SurrogateKeys)
+	}
+}
+
+// Run sends queued purges until Close is called. It should be started once
+// in its own goroutine.
+func (q *PurgeQueue) Run() {
+	defer close(q.stopped)
+
+	for {
+		select {
+		case task := <-q.queue:
+			q.send(context.Background(), task)
+		case <-q.stop:
+			return
+		}
+	}
+}
+
+func (q *PurgeQueue) send(ctx context.Context, task purgeTask) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	err := q.purger.Purge(ctx, task.purge)
+	if err == nil {
+		return
+	}
+
+	task.attempt++
+	if task.attempt >= q.maxAttempts {
+		slog.Error("Cache purge failed, giving up", "attempts", task.attempt, "urls", task.purge.URLs, "keys", task.purge.SurrogateKeys, "error", err)
+		return
+	}
+	delay := q.backoff << (task.attempt - 1)
+	slog.Warn("Cache purge failed, retrying", "attempt", task.attempt, "retry_in", delay, "error", err)
+	time.AfterFunc(delay, func() {
+		select {
+		case <-q.stop:
+		default:
+			q.enqueue(task)
+		}
+	})
+}
+
+// Close stops the queue after one last attempt at every purge still
+// waiting. Retries scheduled for later are abandoned.
+func (q *PurgeQueue) Close(ctx context.Context) error {
+	q.once.Do(func() { close(q.stop) })
+
+	select {
+	case <-q.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	for {
+		select {
+		case task := <-q.queue:
+			if err := q.purger.Purge(ctx, task.purge); err != nil {
+				slog.Error("Cache purge failed during shutdown", "urls", task.purge.URLs, "keys", task.purge.SurrogateKeys, "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			return nil
+		}
+	}
+}
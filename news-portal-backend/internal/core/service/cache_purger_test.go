@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"news-portal-backend/internal/core/port"
+)
+
+// flakyPurger fails the first failures calls, then records purges. block,
+// when set, holds the first call until it is closed.
+type flakyPurger struct {
+	RecordingCachePurger
+
+	mu       sync.Mutex
+	failures int
+	calls    []time.Time
+	block    chan struct{}
+	blocked  chan struct{}
+}
+
+func (p *flakyPurger) Purge(ctx context.Context, purge port.CachePurge) error {
+	p.mu.Lock()
+	p.calls = append(p.calls, time.Now())
+	first := len(p.calls) == 1
+	fail := p.failures > 0
+	if fail {
+		p.failures--
+	}
+	p.mu.Unlock()
+
+	if first && p.block != nil {
+		close(p.blocked)
+		<-p.block
+	}
+	if fail {
+		return errors.New("CDN unavailable")
+	}
+	return p.RecordingCachePurger.Purge(ctx, purge)
+}
+
+func (p *flakyPurger) Calls() []time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]time.Time(nil), p.calls...)
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func closeQueue(t *testing.T, q *PurgeQueue) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := q.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPurgeQueueRetriesWithBackoff(t *testing.T) {
+	purger := &flakyPurger{failures: 2}
+	backoff := 20 * time.Millisecond
+	q := NewPurgeQueue(purger, 10, 5, backoff)
+	go q.Run()
+	defer closeQueue(t, q)
+
+	q.Purge(context.Background(), port.CachePurge{URLs: []string{homepagePath}})
+	waitFor(t, func() bool { return len(purger.Purges()) == 1 })
+
+	// Each retry waits twice as long as the one before
+	calls := purger.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("got %d attempts, want 3", len(calls))
+	}
+	if gap := calls[1].Sub(calls[0]); gap < backoff {
+		t.Errorf("first retry after %v, want at least %v", gap, backoff)
+	}
+	if gap := calls[2].Sub(calls[1]); gap < 2*backoff {
+		t.Errorf("second retry after %v, want at least %v", gap, 2*backoff)
+	}
+}
+
+func TestPurgeQueueGivesUpAfterMaxAttempts(t *testing.T) {
+	purger := &flakyPurger{failures: 100}
+	q := NewPurgeQueue(purger, 10, 3, time.Millisecond)
+	go q.Run()
+	defer closeQueue(t, q)
+
+	q.Purge(context.Background(), port.CachePurge{URLs: []string{homepagePath}})
+	waitFor(t, func() bool { return len(purger.Calls()) == 3 })
+
+	time.Sleep(20 * time.Millisecond)
+	if n := len(purger.Calls()); n != 3 {
+		t.Errorf("got %d attempts, want 3", n)
+	}
+}
+
+// Close sends every purge still queued, even while the sender is busy
+func TestPurgeQueueCloseFlushesPending(t *testing.T) {
+	purger := &flakyPurger{block: make(chan struct{}), blocked: make(chan struct{})}
+	q := NewPurgeQueue(purger, 10, 3, time.Millisecond)
+	go q.Run()
+
+	ctx := context.Background()
+	for _, key := range []string{"a", "b", "c"} {
+		q.Purge(ctx, port.CachePurge{SurrogateKeys: []string{key}})
+	}
+	<-purger.blocked
+
+	closed := make(chan struct{})
+	go func() {
+		closeQueue(t, q)
+		close(closed)
+	}()
+	close(purger.block)
+	<-closed
+
+	if n := len(purger.Purges()); n != 3 {
+		t.Errorf("sent %d purges, want 3", n)
+	}
+}
+
+// Retries scheduled before Close are abandoned
+func TestPurgeQueueCloseAbandonsRetries(t *testing.T) {
+	purger := &flakyPurger{failures: 100}
+	backoff := 20 * time.Millisecond
+	q := NewPurgeQueue(purger, 10, 5, backoff)
+	go q.Run()
+
+	q.Purge(context.Background(), port.CachePurge{URLs: []string{homepagePath}})
+	waitFor(t, func() bool { return len(purger.Calls()) == 1 })
+	closeQueue(t, q)
+
+	time.Sleep(3 * backoff)
+	if n := len(purger.Calls()); n != 1 {
+		t.Errorf("got %d attempts after Close, want 1", n)
+	}
+}
+
+func TestPurgeQueueDropsWhenFull(t *testing.T) {
+	purger := &flakyPurger{}
+	q := NewPurgeQueue(purger, 1, 3, time.Millisecond)
+
+	ctx := context.Background()
+	q.Purge(ctx, port.CachePurge{SurrogateKeys: []string{"kept"}})
+	q.Purge(ctx, port.CachePurge{SurrogateKeys: []string{"dropped"}})
+
+	go q.Run()
+	closeQueue(t, q)
+
+	purges := purger.Purges()
+	if len(purges) != 1 || purges[0].SurrogateKeys[0] != "kept" {
+		t.Errorf("sent %+v, want only the first purge", purges)
+	}
+}
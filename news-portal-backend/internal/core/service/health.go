@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"news-portal-backend/internal/core/port"
+)
+
+// HealthService runs the readiness checks. Checks run concurrently, each
+// with its own timeout, so one hanging dependency can't stall the probe.
+type HealthService struct {
+	checks   []port.HealthCheck
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+func NewHealthService(timeout time.Duration, checks ...port.HealthCheck) *HealthService {
+	return &HealthService{checks: checks, timeout: timeout}
+}
+
+// Drain makes readiness fail from now on, so load balancers stop sending
+// traffic before the server shuts down
+func (s *HealthService) Drain() {
+	s.draining.Store(true)
+}
+
+func (s *HealthService) Ready(ctx context.Context) *port.HealthReport {
+	if s.draining.Load() {
+		return &port.HealthReport{Status: port.HealthDraining}
+	}
+
+	report := &port.HealthReport{
+		Status: port.HealthOK,
+		Checks: make(map[string]port.DependencyHealth, len(s.checks)),
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range s.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := s.run(ctx, c)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[c.Name] = result
+			if result.Status != port.HealthOK {
+				report.Status = port.HealthUnavailable
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+func (s *HealthService) run(ctx context.Context, c port.HealthCheck) port.DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	// A check that ignores its context still can't hold up the probe
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- c.Check(ctx) }()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result := port.DependencyHealth{
+		Status:     port.HealthOK,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = port.HealthUnavailable
+		port.Logger(ctx).Warn("Readiness check failed", "check", c.Name, "error", err)
+	}
+	return result
+}
+
+// CachedCheck remembers check's result for ttl, for dependencies that are
+// slow or billed per request. Concurrent probes share one in-flight call.
+func CachedCheck(check func(ctx context.Context) error, ttl time.Duration) func(ctx context.Context) error {
+	var mu sync.Mutex
+	var err error
+	var checkedAt time.Time
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !checkedAt.IsZero() && time.Since(checkedAt) < ttl {
+			return err
+		}
+		err = check(ctx)
+		// A probe that gave up says nothing about the dependency
+		if ctx.Err() == nil {
+			checkedAt = time.Now()
+		}
+		return err
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"news-portal-backend/internal/core/port"
+)
+
+func TestReadyKeepsErrorsOutOfReport(t *testing.T) {
+	s := NewHealthService(time.Second,
+		port.HealthCheck{Name: "database", Check: func(ctx context.Context) error { return nil }},
+		port.HealthCheck{Name: "storage", Check: func(ctx context.Context) error {
+			return errors.New("dial tcp 10.0.0.7:443: connection refused")
+		}},
+	)
+
+	report := s.Ready(context.Background())
+	if report.Status != port.HealthUnavailable {
+		t.Errorf("Status = %q, want %q", report.Status, port.HealthUnavailable)
+	}
+	if got := report.Checks["storage"].Status; got != port.HealthUnavailable {
+		t.Errorf("storage Status = %q, want %q", got, port.HealthUnavailable)
+	}
+	body, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(body), "10.0.0.7") {
+		t.Errorf("report leaks the check error: %s", body)
+	}
+}
+
+func TestCachedCheck(t *testing.T) {
+	calls := 0
+	failure := errors.New("unavailable")
+	check := CachedCheck(func(ctx context.Context) error {
+		calls++
+		return failure
+	}, time.Hour)
+
+	for range 3 {
+		if err := check(context.Background()); !errors.Is(err, failure) {
+			t.Fatalf("err = %v, want %v", err, failure)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("check ran %d times within the TTL, want 1", calls)
+	}
+}
+
+func TestCachedCheckSkipsAbandonedProbes(t *testing.T) {
+	calls := 0
+	check := CachedCheck(func(ctx context.Context) error {
+		calls++
+		return ctx.Err()
+	}, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := check(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if err := check(context.Background()); err != nil {
+		t.Fatalf("abandoned probe's result was cached: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("check ran %d times, want 2", calls)
+	}
+}
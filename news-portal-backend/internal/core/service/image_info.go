@@ -0,0 +1,199 @@
+package service
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+
+	_ "golang.org/x/image/webp"
+
+	"news-portal-backend/internal/core/domain"
+)
+
+const (
+	// Images above this many pixels only get their dimensions recorded,
+	// decoding them fully costs too much memory.
+	maxAnalyzePixels = 40_000_000
+	// Longest side of the grid sampled for the placeholder and colour
+	sampleSize     = 32
+	blurHashXComps = 4
+	blurHashYComps = 3
+)
+
+// AnalyzeImage reads an uploaded image and derives what the frontend needs to
+// reserve space and show a placeholder: intrinsic size, a BlurHash and the
+// dominant colour. The reader is left positioned at the start.
+func AnalyzeImage(r io.ReadSeeker) (*domain.ImageInfo, error) {
+	defer r.Seek(0, io.SeekStart)
+
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image header: %w", err)
+	}
+	info := &domain.ImageInfo{Width: cfg.Width, Height: cfg.Height}
+	if cfg.Width*cfg.Height > maxAnalyzePixels {
+		return info, nil
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	pixels, w, h := samplePixels(img)
+	if w == 0 || h == 0 {
+		return info, nil
+	}
+	info.BlurHash = encodeBlurHash(pixels, w, h, blurHashXComps, blurHashYComps)
+	info.DominantColor = dominantColor(pixels)
+	return info, nil
+}
+
+type rgb struct{ r, g, b uint8 }
+
+// samplePixels takes a nearest-neighbour sample of the image on a small grid
+func samplePixels(img image.Image) ([]rgb, int, int) {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW == 0 || srcH == 0 {
+		return nil, 0, 0
+	}
+
+	w, h := sampleSize, sampleSize
+	if srcW > srcH {
+		h = max(1, sampleSize*srcH/srcW)
+	} else {
+		w = max(1, sampleSize*srcW/srcH)
+	}
+	w, h = min(w, srcW), min(h, srcH)
+
+	pixels := make([]rgb, 0, w*h)
+	for y := 0; y < h; y++ {
+		sy := bounds.Min.Y + (y*srcH+srcH/2)/h
+		for x := 0; x < w; x++ {
+			sx := bounds.Min.X + (x*srcW+srcW/2)/w
+			r, g, b, _ := img.At(sx, sy).RGBA()
+			pixels = append(pixels, rgb{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8)})
+		}
+	}
+	return pixels, w, h
+}
+
+// dominantColor returns the average of the most populated colour bucket as #rrggbb
+func dominantColor(pixels []rgb) string {
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+	buckets := map[int]*bucket{}
+	var best *bucket
+	for _, p := range pixels {
+		key := int(p.r>>4)<<8 | int(p.g>>4)<<4 | int(p.b>>4)
+		bk, ok := buckets[key]
+		if !ok {
+			bk = &bucket{}
+			buckets[key] = bk
+		}
+		bk.count++
+		bk.r += int(p.r)
+		bk.g += int(p.g)
+		bk.b += int(p.b)
+		if best == nil || bk.count > best.count {
+			best = bk
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.count, best.g/best.count, best.b/best.count)
+}
+
+// BlurHash encoding, see https://github.com/woltapp/blurhash
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+func encodeBlurHash(pixels []rgb, w, h, xComps, yComps int) string {
+	factors := make([][3]float64, 0, xComps*yComps)
+	for j := 0; j < yComps; j++ {
+		for i := 0; i < xComps; i++ {
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1.0
+			}
+			var fr, fg, fb float64
+			for y := 0; y < h; y++ {
+				basisY := math.Cos(math.Pi * float64(j) * float64(y) / float64(h))
+				for x := 0; x < w; x++ {
+					basis := basisY * math.Cos(math.Pi*float64(i)*float64(x)/float64(w))
+					p := pixels[y*w+x]
+					fr += basis * sRGBToLinear(p.r)
+					fg += basis * sRGBToLinear(p.g)
+					fb += basis * sRGBToLinear(p.b)
+				}
+			}
+			scale := normalisation / float64(w*h)
+			factors = append(factors, [3]float64{fr * scale, fg * scale, fb * scale})
+		}
+	}
+
+	dc, ac := factors[0], factors[1:]
+	hash := encodeBase83((xComps-1)+(yComps-1)*9, 1)
+
+	maxValue := 1.0
+	if len(ac) > 0 {
+		actualMax := 0.0
+		for _, f := range ac {
+			actualMax = math.Max(actualMax, math.Max(math.Abs(f[0]), math.Max(math.Abs(f[1]), math.Abs(f[2]))))
+		}
+		quantisedMax := int(math.Max(0, math.Min(82, math.Floor(actualMax*166-0.5))))
+		maxValue = float64(quantisedMax+1) / 166
+		hash += encodeBase83(quantisedMax, 1)
+	} else {
+		hash += encodeBase83(0, 1)
+	}
+
+	hash += encodeBase83(linearToSRGB(dc[0])<<16+linearToSRGB(dc[1])<<8+linearToSRGB(dc[2]), 4)
+	for _, f := range ac {
+		quant := func(v float64) int {
+			return int(math.Max(0, math.Min(18, math.Floor(signPow(v/maxValue, 0.5)*9+9.5))))
+		}
+		hash += encodeBase83(quant(f[0])*19*19+quant(f[1])*19+quant(f[2]), 2)
+	}
+	return hash
+}
+
+func encodeBase83(value, length int) string {
+	out := make([]byte, length)
+	for i := 1; i <= length; i++ {
+		digit := (value / int(math.Pow(83, float64(length-i)))) % 83
+		out[i-1] = base83Chars[digit]
+	}
+	return string(out)
+}
+
+func sRGBToLinear(c uint8) float64 {
+	v := float64(c) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) int {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
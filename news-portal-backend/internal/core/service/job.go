@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job runs a background task immediately and then on a fixed interval until
+// it is closed.
+type Job struct {
+	name     string
+	interval time.Duration
+	timeout  time.Duration
+	task     func(ctx context.Context) error
+
+	stop    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// NewJob creates a job; each run of task is cancelled after timeout.
+func NewJob(name string, interval, timeout time.Duration, task func(ctx context.Context) error) *Job {
+	return &Job{
+		name:     name,
+		interval: interval,
+		timeout:  timeout,
+		task:     task,
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// Run blocks until Close is called. Start it in its own goroutine.
+func (j *Job) Run() {
+	defer close(j.stopped)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.runOnce()
+		select {
+		case <-ticker.C:
+		case <-j.stop:
+			return
+		}
+	}
+}
+
+// Close stops the job, waiting for a run in progress to finish
+func (j *Job) Close(ctx context.Context) error {
+	j.once.Do(func() { close(j.stop) })
+
+	select {
+	case <-j.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (j *Job) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+	defer cancel()
+
+	if err := j.task(ctx); err != nil {
+		slog.Error("Background job failed", "job", j.name, "error", err)
+	}
+}
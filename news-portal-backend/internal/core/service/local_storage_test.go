@@ -0,0 +1,181 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/png"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"news-portal-backend/internal/core/domain"
+)
+
+func newTestLocalStorage(t *testing.T) *LocalStorageService {
+	t.Helper()
+	s, err := NewLocalStorageService(t.TempDir(), "http://localhost/uploads", "http://localhost/api/v1/media/local", "upload-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func pngBytes(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// presignedUpload presigns body and returns the upload the signed URL
+// describes, as the handler would rebuild it from the request
+func presignedUpload(t *testing.T, s *LocalStorageService, contentType string, body []byte, sum string) SignedUpload {
+	t.Helper()
+	key := newObjectKey(contentType)
+	req, err := s.PresignUpload(context.Background(), key, contentType, int64(len(body)), sum, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	expires, _ := strconv.ParseInt(q.Get("expires"), 10, 64)
+	size, _ := strconv.ParseInt(q.Get("size"), 10, 64)
+	return SignedUpload{
+		Key:         path.Base(u.Path),
+		ContentType: req.Headers["Content-Type"],
+		Size:        size,
+		SHA256:      q.Get("sha256"),
+		Expires:     expires,
+		Signature:   q.Get("signature"),
+	}
+}
+
+func TestAcceptSignedUpload(t *testing.T) {
+	s := newTestLocalStorage(t)
+	body := pngBytes(t)
+	upload := presignedUpload(t, s, "image/png", body, sha256Hex(body))
+
+	if err := s.AcceptSignedUpload(upload, bytes.NewReader(body)); err != nil {
+		t.Fatal(err)
+	}
+	info, err := s.StatObject(context.Background(), upload.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size != int64(len(body)) || info.ContentType != "image/png" {
+		t.Errorf("StatObject = %+v, want %d bytes of image/png", info, len(body))
+	}
+}
+
+func TestAcceptSignedUploadRejects(t *testing.T) {
+	body := pngBytes(t)
+	html := []byte("<html><script>alert(1)</script></html>")
+
+	tests := []struct {
+		name   string
+		tamper func(u *SignedUpload)
+		body   []byte
+	}{
+		{"bad signature", func(u *SignedUpload) { u.Signature = strings.Repeat("0", 64) }, body},
+		{"expired", func(u *SignedUpload) { u.Expires = time.Now().Add(-time.Minute).Unix() }, body},
+		{"other key", func(u *SignedUpload) { u.Key = "other.png" }, body},
+		{"other content type", func(u *SignedUpload) { u.ContentType = "text/html" }, body},
+		{"other size", func(u *SignedUpload) { u.Size++ }, body},
+		{"short body", nil, body[:len(body)-1]},
+		{"long body", nil, append(append([]byte{}, body...), 0)},
+		{"checksum mismatch", nil, append(append([]byte{}, body[:len(body)-1]...), body[len(body)-1]^0xff)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestLocalStorage(t)
+			upload := presignedUpload(t, s, "image/png", body, sha256Hex(body))
+			if tt.tamper != nil {
+				tt.tamper(&upload)
+			}
+			err := s.AcceptSignedUpload(upload, bytes.NewReader(tt.body))
+			if !errors.Is(err, domain.ErrInvalidInput) {
+				t.Fatalf("err = %v, want ErrInvalidInput", err)
+			}
+			if _, err := s.StatObject(context.Background(), upload.Key); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("rejected upload was stored: %v", err)
+			}
+		})
+	}
+
+	// Without a signed checksum only the size check stops trailing bytes
+	t.Run("long body without checksum", func(t *testing.T) {
+		s := newTestLocalStorage(t)
+		upload := presignedUpload(t, s, "image/png", body, "")
+		long := append(append([]byte{}, body...), html...)
+		if err := s.AcceptSignedUpload(upload, bytes.NewReader(long)); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Fatalf("err = %v, want ErrInvalidInput", err)
+		}
+		if _, err := s.StatObject(context.Background(), upload.Key); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("rejected upload was stored: %v", err)
+		}
+	})
+
+	t.Run("content doesn't match signed type", func(t *testing.T) {
+		s := newTestLocalStorage(t)
+		upload := presignedUpload(t, s, "image/png", html, "")
+		if err := s.AcceptSignedUpload(upload, bytes.NewReader(html)); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Fatalf("err = %v, want ErrInvalidInput", err)
+		}
+		if _, err := s.StatObject(context.Background(), upload.Key); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("rejected upload was stored: %v", err)
+		}
+	})
+}
+
+// A rejected body must not replace an object already stored under the key
+func TestAcceptSignedUploadKeepsObjectOnMismatch(t *testing.T) {
+	s := newTestLocalStorage(t)
+	body := pngBytes(t)
+	upload := presignedUpload(t, s, "image/png", body, sha256Hex(body))
+	if err := s.AcceptSignedUpload(upload, bytes.NewReader(body)); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := bytes.Repeat([]byte{0}, len(body))
+	if err := s.AcceptSignedUpload(upload, bytes.NewReader(bad)); err == nil {
+		t.Fatal("mismatched body was accepted")
+	}
+	stored, err := os.ReadFile(filepath.Join(s.dir, upload.Key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stored, body) {
+		t.Error("stored object was replaced by a rejected body")
+	}
+}
+
+func TestLocalPutObjectRejectsWrongSize(t *testing.T) {
+	s := newTestLocalStorage(t)
+	body := pngBytes(t)
+	err := s.PutObject(context.Background(), "a.png", "image/png", bytes.NewReader(body), int64(len(body))+1)
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("err = %v, want ErrInvalidInput", err)
+	}
+	if _, err := s.StatObject(context.Background(), "a.png"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("short object was stored: %v", err)
+	}
+}
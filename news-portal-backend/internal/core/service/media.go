@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"news-portal-backend/internal/core/domain"
+	"news-portal-backend/internal/core/port"
+)
+
+const uploadURLTTL = 15 * time.Minute
+
+// mediaExtensions lists the content types that may be stored and the
+// extension their objects get. Only types net/http can sniff are listed, so
+// the stored bytes can always be checked against the declared type. SVG is
+// deliberately missing: it can carry script, and local uploads are served
+// from the API's own origin.
+var mediaExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"video/mp4":  ".mp4",
+	"video/webm": ".webm",
+	"audio/mpeg": ".mp3",
+	"audio/wave": ".wav",
+}
+
+type MediaService struct {
+	repo    port.MediaRepository
+	files   port.FileService
+	maxSize int64
+}
+
+func NewMediaService(repo port.MediaRepository, files port.FileService, maxSize int64) *MediaService {
+	return &MediaService{
+		repo:    repo,
+		files:   files,
+		maxSize: maxSize,
+	}
+}
+
+// UploadFile stores a file received by the API. The content is hashed as it
+// streams to storage and, when identical bytes were already stored, the new
+// object is dropped and the existing one reused.
+func (s *MediaService) UploadFile(ctx context.Context, ownerID uuid.UUID, file multipart.File, header *multipart.FileHeader, accept string) (*port.UploadedFile, error) {
+	contentType, err := sniffUpload(file)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(contentType, accept) {
+		return nil, fmt.Errorf("%w: content type %s is not allowed here", domain.ErrInvalidInput, contentType)
+	}
+	imageInfo := analyzeUpload(ctx, file, header, contentType)
+
+	key := newObjectKey(contentType)
+	hasher := sha256.New()
+	if err := s.files.PutObject(ctx, key, contentType, io.TeeReader(file, hasher), header.Size); err != nil {
+		return nil, err
+	}
+	sum := hex.EncodeToString(hasher.Sum(nil))
+
+	// The server hashed these bytes itself, so any owner's copy can be reused
+	existing, err := s.reuseExisting(ctx, sum, header.Size, nil)
+	if err != nil {
+		s.discardObject(ctx, key)
+		return nil, err
+	}
+	if existing != nil {
+		s.discardObject(ctx, key)
+		return &port.UploadedFile{Key: existing.ObjectKey, URL: existing.URL, Image: existing.Image}, nil
+	}
+
+	uploaded := &port.UploadedFile{Key: key, URL: s.files.ObjectURL(key), Image: imageInfo}
+	_, err = s.repo.CreateMedia(ctx, &domain.Media{
+		OwnerID:     ownerID,
+		ObjectKey:   uploaded.Key,
+		URL:         uploaded.URL,
+		Filename:    header.Filename,
+		ContentType: contentType,
+		SizeBytes:   header.Size,
+		SHA256:      sum,
+		Image:       uploaded.Image,
+		Status:      domain.MediaStatusReady,
+	})
+	if err != nil {
+		// The object is stored and usable, it just won't be deduplicated
+		port.Logger(ctx).Error("Failed to record uploaded media", "key", uploaded.Key, "error", err)
+	}
+
+	return uploaded, nil
+}
+
+// RequestUpload creates a pending media record and a presigned URL the client
+// uploads the bytes to, bypassing the API. Clients that send the SHA-256 of
+// the file get their own existing media back when they already stored the
+// content. A declared hash proves nothing about owning the bytes, so other
+// owners' copies are only reused once the upload is verified, in
+// CompleteUpload.
+func (s *MediaService) RequestUpload(ctx context.Context, ownerID uuid.UUID, filename, contentType string, size int64, sum string) (*port.MediaUpload, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !isAllowedMediaType(mediaType) {
+		return nil, fmt.Errorf("%w: unsupported content type", domain.ErrInvalidInput)
+	}
+	if size <= 0 || size > s.maxSize {
+		return nil, fmt.Errorf("%w: file size must be between 1 and %d bytes", domain.ErrInvalidInput, s.maxSize)
+	}
+	if filename == "" {
+		return nil, fmt.Errorf("%w: filename is required", domain.ErrInvalidInput)
+	}
+
+	if sum != "" {
+		sum = strings.ToLower(sum)
+		if decoded, err := hex.DecodeString(sum); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("%w: sha256 must be a hex encoded SHA-256 digest", domain.ErrInvalidInput)
+		}
+		existing, err := s.reuseExisting(ctx, sum, size, &ownerID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return &port.MediaUpload{Media: existing, Duplicate: true}, nil
+		}
+	}
+
+	// The checksum is part of the signature, so storage refuses other content
+	// and the hash we record can be trusted for later deduplication.
+	key := newObjectKey(mediaType)
+	presigned, err := s.files.PresignUpload(ctx, key, mediaType, size, sum, uploadURLTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	media, err := s.repo.CreateMedia(ctx, &domain.Media{
+		OwnerID:     ownerID,
+		ObjectKey:   key,
+		URL:         s.files.ObjectURL(key),
+		Filename:    filename,
+		ContentType: mediaType,
+		SizeBytes:   size,
+		SHA256:      sum,
+		Status:      domain.MediaStatusPending,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(uploadURLTTL)
+	return &port.MediaUpload{
+		Media:     media,
+		UploadURL: presigned.URL,
+		Method:    "PUT",
+		Headers:   presigned.Headers,
+		ExpiresAt: &expiresAt,
+	}, nil
+}
+
+// CompleteUpload checks the stored object against what was declared and
+// marks the media ready. Objects that don't match are removed. Uploads
+// without a signed checksum are hashed here, and when the same content is
+// already stored the new object and record are dropped in favour of it.
+func (s *MediaService) CompleteUpload(ctx context.Context, ownerID, id uuid.UUID) (*domain.Media, error) {
+	media, err := s.repo.GetMediaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if media == nil || media.OwnerID != ownerID {
+		return nil, domain.ErrNotFound
+	}
+	if media.Status == domain.MediaStatusReady {
+		return media, nil
+	}
+	if media.Status != domain.MediaStatusPending {
+		return nil, fmt.Errorf("%w: upload was rejected", domain.ErrInvalidInput)
+	}
+
+	info, err := s.files.StatObject(ctx, media.ObjectKey)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("%w: object has not been uploaded yet", domain.ErrInvalidInput)
+		}
+		return nil, err
+	}
+
+	if reason := verifyObject(media, info); reason != "" {
+		if err := s.files.DeleteObject(ctx, media.ObjectKey); err != nil {
+			port.Logger(ctx).Error("Failed to delete rejected upload", "key", media.ObjectKey, "error", err)
+		}
+		if err := s.repo.UpdateMediaStatus(ctx, media.ID, domain.MediaStatusRejected); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidInput, reason)
+	}
+
+	if media.SHA256 == "" {
+		sum, err := s.hashObject(ctx, media.ObjectKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.repo.SetMediaHash(ctx, media.ID, sum); err != nil {
+			return nil, err
+		}
+		media.SHA256 = sum
+	}
+
+	existing, err := s.reuseExisting(ctx, media.SHA256, media.SizeBytes, nil)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		s.discardObject(ctx, media.ObjectKey)
+		if err := s.repo.DeleteMedia(ctx, media.ID); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}
+
+	if err := s.repo.UpdateMediaStatus(ctx, media.ID, domain.MediaStatusReady); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	media.Status = domain.MediaStatusReady
+	media.CompletedAt = &now
+	return media, nil
+}
+
+// reuseExisting returns stored media with the same content, counting the
+// reuse. ownerID limits the search to one owner's uploads.
+func (s *MediaService) reuseExisting(ctx context.Context, sum string, size int64, ownerID *uuid.UUID) (*domain.Media, error) {
+	existing, err := s.repo.FindReadyMediaByHash(ctx, sum, size, ownerID)
+	if err != nil || existing == nil {
+		return nil, err
+	}
+	if err := s.repo.IncrementMediaReuse(ctx, existing.ID); err != nil {
+		return nil, err
+	}
+	existing.ReuseCount++
+	return existing, nil
+}
+
+// hashObject reads a stored object back to hash it
+func (s *MediaService) hashObject(ctx context.Context, key string) (string, error) {
+	r, err := s.files.OpenObject(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, r); err != nil {
+		return "", fmt.Errorf("failed to hash object: %w", err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// discardObject deletes an object nothing will point to. A failure only
+// leaves an orphan behind, so it is logged rather than returned.
+func (s *MediaService) discardObject(ctx context.Context, key string) {
+	if err := s.files.DeleteObject(ctx, key); err != nil {
+		port.Logger(ctx).Error("Failed to delete duplicate upload", "key", key, "error", err)
+	}
+}
+
+func verifyObject(media *domain.Media, info *port.ObjectInfo) string {
+	if info.Size != media.SizeBytes {
+		return fmt.Sprintf("size mismatch: expected %d bytes, got %d", media.SizeBytes, info.Size)
+	}
+	actual, _, err := mime.ParseMediaType(info.ContentType)
+	if err != nil || actual != media.ContentType {
+		return fmt.Sprintf("content type mismatch: expected %s, got %s", media.ContentType, info.ContentType)
+	}
+	return ""
+}
+
+func isAllowedMediaType(mediaType string) bool {
+	_, ok := mediaExtensions[mediaType]
+	return ok
+}
+
+// detectContentType sniffs the media type of r's content and rewinds it
+func detectContentType(r io.ReadSeeker) (string, error) {
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", fmt.Errorf("failed to read upload: %w", err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return sniffContentType(buf[:n]), nil
+}
+
+// sniffContentType returns the media type of content starting with head,
+// without parameters. Only the first 512 bytes are considered.
+func sniffContentType(head []byte) string {
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	return mediaType
+}
+
+// analyzeUpload extracts image placeholder data from an upload. Failures only
+// cost us the placeholder, so they are logged rather than returned.
+func analyzeUpload(ctx context.Context, file multipart.File, header *multipart.FileHeader, contentType string) *domain.ImageInfo {
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil
+	}
+	info, err := AnalyzeImage(file)
+	if err != nil {
+		port.Logger(ctx).Warn("Failed to analyze uploaded image", "filename", header.Filename, "error", err)
+		return nil
+	}
+	return info
+}
+
+// sniffUpload returns the content type of an upload received by the API,
+// judged by its bytes rather than what the client claimed
+func sniffUpload(file io.ReadSeeker) (string, error) {
+	contentType, err := detectContentType(file)
+	if err != nil {
+		return "", err
+	}
+	if !isAllowedMediaType(contentType) {
+		return "", fmt.Errorf("%w: unsupported content type %s", domain.ErrInvalidInput, contentType)
+	}
+	return contentType, nil
+}
@@ -0,0 +1,393 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"news-portal-backend/internal/core/domain"
+	"news-portal-backend/internal/core/port"
+)
+
+// memoryMediaRepo keeps media in insertion order, like created_at. Reads
+// return copies, as rows scanned from the database would be.
+type memoryMediaRepo struct {
+	port.MediaRepository
+	media []*domain.Media
+}
+
+func (r *memoryMediaRepo) CreateMedia(ctx context.Context, m *domain.Media) (*domain.Media, error) {
+	m.ID = uuid.New()
+	m.CreatedAt = time.Now()
+	stored := *m
+	r.media = append(r.media, &stored)
+	return m, nil
+}
+
+func (r *memoryMediaRepo) find(id uuid.UUID) *domain.Media {
+	for _, m := range r.media {
+		if m.ID == id {
+			return m
+		}
+	}
+	return nil
+}
+
+func (r *memoryMediaRepo) GetMediaByID(ctx context.Context, id uuid.UUID) (*domain.Media, error) {
+	if m := r.find(id); m != nil {
+		c := *m
+		return &c, nil
+	}
+	return nil, nil
+}
+
+func (r *memoryMediaRepo) UpdateMediaStatus(ctx context.Context, id uuid.UUID, status string) error {
+	m := r.find(id)
+	if m == nil {
+		return domain.ErrNotFound
+	}
+	m.Status = status
+	return nil
+}
+
+func (r *memoryMediaRepo) FindReadyMediaByHash(ctx context.Context, sum string, size int64, ownerID *uuid.UUID) (*domain.Media, error) {
+	for _, m := range r.media {
+		if m.SHA256 == sum && m.SizeBytes == size && m.Status == domain.MediaStatusReady && (ownerID == nil || m.OwnerID == *ownerID) {
+			c := *m
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryMediaRepo) SetMediaHash(ctx context.Context, id uuid.UUID, sum string) error {
+	m := r.find(id)
+	if m == nil {
+		return domain.ErrNotFound
+	}
+	m.SHA256 = sum
+	return nil
+}
+
+func (r *memoryMediaRepo) DeleteMedia(ctx context.Context, id uuid.UUID) error {
+	for i, m := range r.media {
+		if m.ID == id {
+			r.media = append(r.media[:i], r.media[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (r *memoryMediaRepo) IncrementMediaReuse(ctx context.Context, id uuid.UUID) error {
+	m := r.find(id)
+	if m == nil {
+		return domain.ErrNotFound
+	}
+	m.ReuseCount++
+	return nil
+}
+
+// memoryFiles is a FileService holding objects in memory. Presigned uploads
+// are simulated by writing to objects directly. Like the real backends,
+// StatObject sniffs the content type instead of reporting the stored one.
+type memoryFiles struct {
+	port.FileService
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func newMemoryFiles() *memoryFiles {
+	return &memoryFiles{objects: make(map[string][]byte), types: make(map[string]string)}
+}
+
+func (f *memoryFiles) PutObject(ctx context.Context, key, contentType string, body io.Reader, size int64) error {
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	f.objects[key] = b
+	f.types[key] = contentType
+	return nil
+}
+
+func (f *memoryFiles) PresignUpload(ctx context.Context, key, contentType string, size int64, sha256 string, ttl time.Duration) (*port.PresignedRequest, error) {
+	return &port.PresignedRequest{URL: "https://storage.example.com/" + key}, nil
+}
+
+func (f *memoryFiles) StatObject(ctx context.Context, key string) (*port.ObjectInfo, error) {
+	b, ok := f.objects[key]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &port.ObjectInfo{Size: int64(len(b)), ContentType: sniffContentType(b)}, nil
+}
+
+func (f *memoryFiles) OpenObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	b, ok := f.objects[key]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (f *memoryFiles) DeleteObject(ctx context.Context, key string) error {
+	delete(f.objects, key)
+	return nil
+}
+
+func (f *memoryFiles) ObjectURL(key string) string {
+	return "https://cdn.example.com/" + key
+}
+
+func uploadFile(t *testing.T, s *MediaService, ownerID uuid.UUID, filename, declared string, content []byte) (*port.UploadedFile, error) {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "upload-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	if _, err := f.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	header := &multipart.FileHeader{
+		Filename: filename,
+		Header:   textproto.MIMEHeader{"Content-Type": {declared}},
+		Size:     int64(len(content)),
+	}
+	return s.UploadFile(context.Background(), ownerID, f, header, "image/")
+}
+
+func TestUploadFileKeyFollowsSniffedType(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		declared string
+		body     []byte
+		wantExt  string
+	}{
+		{"png named html", "evil.html", "image/png", pngBytes(t), ".png"},
+		{"png without extension", "photo", "image/png", pngBytes(t), ".png"},
+		{"svg", "logo.svg", "image/svg+xml", []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`), ""},
+		{"html claiming png", "a.png", "image/png", []byte("<html><script>alert(1)</script></html>"), ""},
+		{"video claiming png", "a.png", "image/png", []byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := newMemoryFiles()
+			s := NewMediaService(&memoryMediaRepo{}, files, 1<<20)
+
+			uploaded, err := uploadFile(t, s, uuid.New(), tt.filename, tt.declared, tt.body)
+			if tt.wantExt == "" {
+				if !errors.Is(err, domain.ErrInvalidInput) {
+					t.Fatalf("err = %v, want ErrInvalidInput", err)
+				}
+				if len(files.objects) != 0 {
+					t.Error("rejected upload was stored")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if filepath.Ext(uploaded.Key) != tt.wantExt {
+				t.Errorf("key = %q, want extension %q", uploaded.Key, tt.wantExt)
+			}
+			if files.types[uploaded.Key] != "image/png" {
+				t.Errorf("stored as %q, want image/png", files.types[uploaded.Key])
+			}
+		})
+	}
+}
+
+func TestUploadFileReusesIdenticalContent(t *testing.T) {
+	repo := &memoryMediaRepo{}
+	files := newMemoryFiles()
+	s := NewMediaService(repo, files, 1<<20)
+	body := pngBytes(t)
+
+	first, err := uploadFile(t, s, uuid.New(), "a.png", "image/png", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.media[0].SHA256; got != sha256Hex(body) {
+		t.Errorf("recorded hash = %s, want %s", got, sha256Hex(body))
+	}
+
+	// The server hashed the bytes itself, so other owners share the object
+	second, err := uploadFile(t, s, uuid.New(), "b.png", "image/png", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Key != first.Key {
+		t.Errorf("second upload stored as %q, want reuse of %q", second.Key, first.Key)
+	}
+	if len(files.objects) != 1 {
+		t.Errorf("%d objects stored, want 1", len(files.objects))
+	}
+	if len(repo.media) != 1 || repo.media[0].ReuseCount != 1 {
+		t.Errorf("media = %+v, want one record reused once", repo.media)
+	}
+}
+
+func TestRequestUploadOnlyReusesOwnMediaByDeclaredHash(t *testing.T) {
+	repo := &memoryMediaRepo{}
+	s := NewMediaService(repo, newMemoryFiles(), 1<<20)
+	owner, other := uuid.New(), uuid.New()
+	body := pngBytes(t)
+	sum := sha256Hex(body)
+	repo.CreateMedia(context.Background(), &domain.Media{
+		OwnerID: owner, ObjectKey: "a.png", SizeBytes: int64(len(body)), SHA256: sum, Status: domain.MediaStatusReady,
+	})
+
+	upload, err := s.RequestUpload(context.Background(), other, "b.png", "image/png", int64(len(body)), sum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upload.Duplicate || upload.Media.ObjectKey == "a.png" {
+		t.Fatal("another owner's media was handed out for a declared hash")
+	}
+
+	upload, err = s.RequestUpload(context.Background(), owner, "c.png", "image/png", int64(len(body)), sum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !upload.Duplicate || upload.Media.ObjectKey != "a.png" {
+		t.Errorf("owner's own media wasn't reused: %+v", upload.Media)
+	}
+}
+
+func TestRequestUploadRejectsUnlistedTypes(t *testing.T) {
+	s := NewMediaService(&memoryMediaRepo{}, newMemoryFiles(), 1<<20)
+	for _, contentType := range []string{"image/svg+xml", "text/html", "application/octet-stream"} {
+		if _, err := s.RequestUpload(context.Background(), uuid.New(), "f", contentType, 10, ""); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("%s: err = %v, want ErrInvalidInput", contentType, err)
+		}
+	}
+}
+
+func TestCompleteUpload(t *testing.T) {
+	body := pngBytes(t)
+	ctx := context.Background()
+
+	t.Run("hashes uploads without a checksum", func(t *testing.T) {
+		repo := &memoryMediaRepo{}
+		files := newMemoryFiles()
+		s := NewMediaService(repo, files, 1<<20)
+		owner := uuid.New()
+
+		upload, err := s.RequestUpload(ctx, owner, "a.png", "image/png", int64(len(body)), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		files.PutObject(ctx, upload.Media.ObjectKey, "image/png", bytes.NewReader(body), int64(len(body)))
+
+		media, err := s.CompleteUpload(ctx, owner, upload.Media.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if media.Status != domain.MediaStatusReady || media.SHA256 != sha256Hex(body) {
+			t.Errorf("media = %+v, want ready with hash %s", media, sha256Hex(body))
+		}
+	})
+
+	t.Run("drops a verified duplicate of another owner's media", func(t *testing.T) {
+		repo := &memoryMediaRepo{}
+		files := newMemoryFiles()
+		s := NewMediaService(repo, files, 1<<20)
+		existing, _ := repo.CreateMedia(ctx, &domain.Media{
+			OwnerID: uuid.New(), ObjectKey: "a.png", SizeBytes: int64(len(body)), SHA256: sha256Hex(body), Status: domain.MediaStatusReady,
+		})
+		owner := uuid.New()
+
+		upload, err := s.RequestUpload(ctx, owner, "b.png", "image/png", int64(len(body)), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		files.PutObject(ctx, upload.Media.ObjectKey, "image/png", bytes.NewReader(body), int64(len(body)))
+
+		media, err := s.CompleteUpload(ctx, owner, upload.Media.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if media.ID != existing.ID || repo.find(existing.ID).ReuseCount != 1 {
+			t.Errorf("got %+v, want the existing media reused once", media)
+		}
+		if _, ok := files.objects[upload.Media.ObjectKey]; ok {
+			t.Error("duplicate object was kept")
+		}
+		if m, _ := repo.GetMediaByID(ctx, upload.Media.ID); m != nil {
+			t.Error("duplicate record was kept")
+		}
+	})
+
+	t.Run("rejects content that doesn't match", func(t *testing.T) {
+		repo := &memoryMediaRepo{}
+		files := newMemoryFiles()
+		s := NewMediaService(repo, files, 1<<20)
+		owner := uuid.New()
+
+		upload, err := s.RequestUpload(ctx, owner, "a.png", "image/png", int64(len(body)), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		files.PutObject(ctx, upload.Media.ObjectKey, "image/png", bytes.NewReader(body[1:]), int64(len(body)-1))
+
+		if _, err := s.CompleteUpload(ctx, owner, upload.Media.ID); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Fatalf("err = %v, want ErrInvalidInput", err)
+		}
+		if _, ok := files.objects[upload.Media.ObjectKey]; ok {
+			t.Error("rejected object was kept")
+		}
+		if m, _ := repo.GetMediaByID(ctx, upload.Media.ID); m.Status != domain.MediaStatusRejected {
+			t.Errorf("status = %s, want rejected", m.Status)
+		}
+	})
+
+	// Storage keeps whatever Content-Type the client uploaded with, so only
+	// the sniffed type counts
+	t.Run("rejects content declared as the requested type", func(t *testing.T) {
+		repo := &memoryMediaRepo{}
+		files := newMemoryFiles()
+		s := NewMediaService(repo, files, 1<<20)
+		owner := uuid.New()
+		html := []byte("<html><script>alert(1)</script></html>")
+
+		upload, err := s.RequestUpload(ctx, owner, "a.png", "image/png", int64(len(html)), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		files.PutObject(ctx, upload.Media.ObjectKey, "image/png", bytes.NewReader(html), int64(len(html)))
+
+		if _, err := s.CompleteUpload(ctx, owner, upload.Media.ID); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Fatalf("err = %v, want ErrInvalidInput", err)
+		}
+		if _, ok := files.objects[upload.Media.ObjectKey]; ok {
+			t.Error("rejected object was kept")
+		}
+	})
+
+	t.Run("hides other owners' uploads", func(t *testing.T) {
+		repo := &memoryMediaRepo{}
+		s := NewMediaService(repo, newMemoryFiles(), 1<<20)
+		upload, err := s.RequestUpload(ctx, uuid.New(), "a.png", "image/png", int64(len(body)), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.CompleteUpload(ctx, uuid.New(), upload.Media.ID); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("err = %v, want ErrNotFound", err)
+		}
+	})
+}
@@ -17,71 +17,227 @@ import (
 type NewsService struct {
 	repo         port.NewsRepository
 	categoryRepo port.CategoryRepository
+	views        port.ViewCounter
+	cache        *ResponseCache
+	viewCounts   *ResponseCache
+	purger       port.CachePurger
 	p            *bluemonday.Policy
 }
 
-func NewNewsService(repo port.NewsRepository, categoryRepo port.CategoryRepository) *NewsService {
+// Response cache keys. List keys continue with the category ID (or "all")
+// and the paging and sort parameters.
+const (
+	homepageCacheKey     = "homepage"
+	newsSlugCachePrefix  = "news:slug:"
+	newsIDCachePrefix    = "news:id:"
+	newsViewsCachePrefix = "news:views:"
+	newsListCachePrefix  = "news:list:"
+	categorySlugCacheKey = "category:slug:"
+)
+
+// viewCountCacheTTL bounds how far an article's view count may lag behind
+// the database, and how often each article's count is read from it
+const viewCountCacheTTL = 10 * time.Second
+
+// Public API paths purged from the CDN after a write. Listings vary by
+// query string, so they are purged by surrogate key instead.
+const (
+	homepagePath   = "/api/v1/news/homepage"
+	newsPathPrefix = "/api/v1/news/"
+	categoriesPath = "/api/v1/categories"
+)
+
+func NewNewsService(repo port.NewsRepository, categoryRepo port.CategoryRepository, views port.ViewCounter, cache *ResponseCache, purger port.CachePurger) *NewsService {
 	p := bluemonday.UGCPolicy()
 	// Allow TipTap alignment classes and the tiptap class itself
 	p.AllowAttrs("class").Matching(regexp.MustCompile(`^(text-align-(left|center|right|justify)|tiptap)$`)).OnElements("p", "h1", "h2", "h3", "h4", "h5", "h6", "div", "span")
 	// Allow inline styles for colors
 	p.AllowAttrs("style").OnElements("span", "p", "h1", "h2", "h3", "h4", "h5", "h6")
 
+	// View counts move with every flush of the view counter, so they are
+	// cached separately and only briefly
+	var viewCounts *ResponseCache
+	if cache != nil {
+		viewCounts = NewResponseCache(cache.cache, viewCountCacheTTL, viewCountCacheTTL)
+	}
+
 	return &NewsService{
 		repo:         repo,
 		categoryRepo: categoryRepo,
+		views:        views,
+		cache:        cache,
+		viewCounts:   viewCounts,
+		purger:       purger,
 		p:            p,
 	}
 }
 
-func (s *NewsService) CreateNews(ctx context.Context, authorID, categoryID uuid.UUID, title, excerpt, content, thumbnail string, isFeatured bool) (*domain.News, error) {
+func (s *NewsService) CreateNews(ctx context.Context, authorID, categoryID uuid.UUID, title, excerpt, content, thumbnail string, thumbnailInfo *domain.ImageInfo, thumbnailMeta domain.ThumbnailMeta, isFeatured, allowMissingAlt bool) (*domain.News, error) {
+	if err := validateThumbnailMeta(thumbnail, &thumbnailMeta, allowMissingAlt); err != nil {
+		return nil, err
+	}
+
 	slug := generateUniqueSlug(title)
 	sanitizedContent := s.p.Sanitize(content)
 
 	news := &domain.News{
-		AuthorID:   authorID,
-		CategoryID: categoryID,
-		Title:      title,
-		Excerpt:    &excerpt,
-		Content:    sanitizedContent,
-		Thumbnail:  thumbnail,
-		Slug:       slug,
-		IsFeatured: isFeatured,
+		AuthorID:      authorID,
+		CategoryID:    categoryID,
+		Title:         title,
+		Excerpt:       &excerpt,
+		Content:       sanitizedContent,
+		Thumbnail:     thumbnail,
+		ThumbnailInfo: thumbnailInfo,
+		ThumbnailMeta: &thumbnailMeta,
+		Slug:          slug,
+		IsFeatured:    isFeatured,
 	}
-	return s.repo.CreateNews(ctx, news)
+	created, err := s.repo.CreateNews(ctx, news)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateNews(ctx, isFeatured, created)
+	return created, nil
 }
 
-func (s *NewsService) UpdateNews(ctx context.Context, id uuid.UUID, categoryID uuid.UUID, title, excerpt, content, thumbnail string, isFeatured bool) error {
+func (s *NewsService) UpdateNews(ctx context.Context, id uuid.UUID, categoryID uuid.UUID, title, excerpt, content, thumbnail string, thumbnailInfo *domain.ImageInfo, thumbnailMeta domain.ThumbnailMeta, isFeatured, allowMissingAlt bool) error {
+	if err := validateThumbnailMeta(thumbnail, &thumbnailMeta, allowMissingAlt); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetNewsByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return domain.ErrNotFound
+	}
+
 	sanitizedContent := s.p.Sanitize(content)
 	news := &domain.News{
-		ID:         id,
-		CategoryID: categoryID,
-		Title:      title,
-		Excerpt:    &excerpt,
-		Content:    sanitizedContent,
-		Thumbnail:  thumbnail,
-		IsFeatured: isFeatured,
+		ID:            id,
+		CategoryID:    categoryID,
+		Title:         title,
+		Excerpt:       &excerpt,
+		Content:       sanitizedContent,
+		Thumbnail:     thumbnail,
+		ThumbnailInfo: thumbnailInfo,
+		ThumbnailMeta: &thumbnailMeta,
+		IsFeatured:    isFeatured,
 	}
-	return s.repo.UpdateNews(ctx, news)
+	if err := s.repo.UpdateNews(ctx, news); err != nil {
+		return err
+	}
+
+	// The slug never changes on update; the category might
+	news.Slug = existing.Slug
+	s.invalidateNews(ctx, isFeatured != existing.IsFeatured, existing, news)
+	return nil
 }
 
 func (s *NewsService) DeleteNews(ctx context.Context, id uuid.UUID) error {
-	return s.repo.DeleteNews(ctx, id)
+	existing, err := s.repo.GetNewsByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteNews(ctx, id); err != nil {
+		return err
+	}
+	if existing != nil {
+		s.invalidateNews(ctx, existing.IsFeatured, existing)
+	}
+	return nil
 }
 
-func (s *NewsService) GetNewsBySlug(ctx context.Context, slug string) (*domain.News, error) {
-	news, err := s.repo.GetNewsBySlug(ctx, slug)
+// invalidateNews drops the cached homepage, the articles' detail pages and
+// the listings they appear in, locally and at the CDN. A featured change
+// also touches whichever article lost the featured spot, so every local
+// detail and listing is dropped.
+func (s *NewsService) invalidateNews(ctx context.Context, featuredChanged bool, articles ...*domain.News) {
+	s.purgeNews(ctx, articles...)
+
+	if featuredChanged {
+		s.cache.Invalidate(ctx, []string{homepageCacheKey}, newsSlugCachePrefix, newsListCachePrefix)
+		return
+	}
+
+	keys := []string{homepageCacheKey}
+	prefixes := []string{newsListCachePrefix + "all:"}
+	for _, n := range articles {
+		keys = append(keys, newsSlugCachePrefix+n.Slug)
+		prefixes = append(prefixes, newsListCachePrefix+n.CategoryID.String()+":")
+	}
+	s.cache.Invalidate(ctx, keys, prefixes...)
+}
+
+// purgeNews asks the CDN to drop the homepage, every listing and the
+// articles' own pages
+func (s *NewsService) purgeNews(ctx context.Context, articles ...*domain.News) {
+	purge := port.CachePurge{
+		URLs:          []string{homepagePath},
+		SurrogateKeys: []string{port.SurrogateKeyHomepage, port.SurrogateKeyNewsList},
+	}
+	seen := make(map[uuid.UUID]bool)
+	for _, n := range articles {
+		if seen[n.ID] {
+			continue
+		}
+		seen[n.ID] = true
+		purge.URLs = append(purge.URLs, newsPathPrefix+n.Slug)
+		purge.SurrogateKeys = append(purge.SurrogateKeys, port.SurrogateKeyForNews(n.ID))
+	}
+	if err := s.purger.Purge(ctx, purge); err != nil {
+		port.Logger(ctx).Warn("Failed to queue CDN purge", "error", err)
+	}
+}
+
+func (s *NewsService) GetNewsBySlug(ctx context.Context, slug string, viewer port.Viewer) (*domain.News, error) {
+	// Missing slugs are cached too, so repeated requests for a dead link
+	// don't reach the database
+	news, err := cachedLoad(ctx, s.cache, newsSlugCachePrefix+slug, func(ctx context.Context) (*domain.News, error) {
+		news, err := s.repo.GetNewsBySlug(ctx, slug)
+		if news != nil {
+			// The view count is cached on its own below
+			news.ViewsCount, news.RawViewsCount = 0, 0
+		}
+		return news, err
+	})
+	if err != nil || news == nil {
+		return nil, err
+	}
+	s.views.RecordView(news.ID, viewer)
+
+	// Concurrent callers of one load share the pointer, so the count goes
+	// on a copy
+	views, err := cachedLoad(ctx, s.viewCounts, newsViewsCachePrefix+news.ID.String(), func(ctx context.Context) (int64, error) {
+		return s.repo.GetNewsViewsCount(ctx, news.ID)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if news != nil {
-		// Increment views in background
-		go func() {
-			_ = s.repo.IncrementNewsViews(context.Background(), slug)
-		}()
+	article := *news
+	article.ViewsCount = views
+	return &article, nil
+}
+
+// RecordShare counts a share button click. Only articles that exist are
+// cached, so made-up IDs can't crowd the cache; clicks on an article deleted
+// since are discarded when the counter is flushed.
+func (s *NewsService) RecordShare(ctx context.Context, id uuid.UUID, network string, viewer port.Viewer) error {
+	_, err := cachedLoad(ctx, s.cache, newsIDCachePrefix+id.String(), func(ctx context.Context) (bool, error) {
+		news, err := s.repo.GetNewsByID(ctx, id)
+		if err == nil && news == nil {
+			err = domain.NewError(domain.ErrNotFound, domain.CodeNotFound, "News not found")
+		}
+		return err == nil, err
+	})
+	if err != nil {
+		return err
 	}
-	return news, nil
+	s.views.RecordShare(id, network, viewer)
+	return nil
 }
+
 func (s *NewsService) ListNews(ctx context.Context, page, limit int32, categorySlug string, authorID *uuid.UUID, sortBy string, isFeatured *bool, search string) ([]*domain.News, int64, error) {
 	if page < 1 {
 		page = 1
@@ -91,29 +247,77 @@ func (s *NewsService) ListNews(ctx context.Context, page, limit int32, categoryS
 	}
 	offset := (page - 1) * limit
 
-	var categoryID *uuid.UUID
-	if categorySlug != "" {
-		cat, err := s.categoryRepo.GetCategoryBySlug(ctx, categorySlug)
+	categoryID, err := s.resolveCategory(ctx, categorySlug)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	load := func(ctx context.Context) (newsPage, error) {
+		news, err := s.repo.ListNews(ctx, limit, offset, categoryID, authorID, sortBy, isFeatured, search)
 		if err != nil {
-			return nil, 0, err
+			return newsPage{}, err
 		}
-		if cat != nil {
-			categoryID = &cat.ID
+
+		// Get total count with filters applied
+		total, err := s.repo.CountNews(ctx, categoryID, authorID, isFeatured, search)
+		if err != nil {
+			return newsPage{}, err
 		}
+		return newsPage{News: news, Total: total}, nil
 	}
 
-	news, err := s.repo.ListNews(ctx, limit, offset, categoryID, authorID, sortBy, isFeatured, search)
+	// Searches and author pages are too varied to be worth caching
+	var result newsPage
+	if search == "" && authorID == nil {
+		result, err = cachedLoad(ctx, s.cache, newsListCacheKey(categoryID, page, limit, sortBy, isFeatured), load)
+	} else {
+		result, err = load(ctx)
+	}
 	if err != nil {
 		return nil, 0, err
 	}
+	return result.News, result.Total, nil
+}
+
+type newsPage struct {
+	News  []*domain.News `json:"news"`
+	Total int64          `json:"total"`
+}
+
+func newsListCacheKey(categoryID *uuid.UUID, page, limit int32, sortBy string, isFeatured *bool) string {
+	category := "all"
+	if categoryID != nil {
+		category = categoryID.String()
+	}
+	featured := "any"
+	if isFeatured != nil {
+		featured = fmt.Sprint(*isFeatured)
+	}
+	return fmt.Sprintf("%s%s:%d:%d:%s:%s", newsListCachePrefix, category, page, limit, sortBy, featured)
+}
+
+// resolveCategory maps a category slug to its ID. Unknown slugs resolve to
+// nil, which lists every category.
+func (s *NewsService) resolveCategory(ctx context.Context, categorySlug string) (*uuid.UUID, error) {
+	if categorySlug == "" {
+		return nil, nil
+	}
+	cat, err := cachedLoad(ctx, s.cache, categorySlugCacheKey+categorySlug, func(ctx context.Context) (*domain.Category, error) {
+		return s.categoryRepo.GetCategoryBySlug(ctx, categorySlug)
+	})
+	if err != nil || cat == nil {
+		return nil, err
+	}
+	return &cat.ID, nil
+}
 
-	// Get total count with filters applied
-	total, err := s.repo.CountNews(ctx, categoryID, authorID, isFeatured, search)
+func (s *NewsService) ExportNews(ctx context.Context, categorySlug string, authorID *uuid.UUID, sortBy string, isFeatured *bool, search string, fn func(*domain.News) error) error {
+	categoryID, err := s.resolveCategory(ctx, categorySlug)
 	if err != nil {
-		return nil, 0, err
+		return err
 	}
 
-	return news, total, nil
+	return s.repo.StreamNews(ctx, categoryID, authorID, sortBy, isFeatured, search, fn)
 }
 
 func (s *NewsService) CheckSlug(ctx context.Context, slug string) (bool, error) {
@@ -121,6 +325,10 @@ func (s *NewsService) CheckSlug(ctx context.Context, slug string) (bool, error)
 }
 
 func (s *NewsService) GetHomepageData(ctx context.Context) (*port.HomepageData, error) {
+	return cachedLoad(ctx, s.cache, homepageCacheKey, s.loadHomepageData)
+}
+
+func (s *NewsService) loadHomepageData(ctx context.Context) (*port.HomepageData, error) {
 	// 1. Fetch Featured News (Limit 1)
 	isFeatured := true
 	featuredList, err := s.repo.ListNews(ctx, 1, 0, nil, nil, "latest", &isFeatured, "")
@@ -142,7 +350,7 @@ func (s *NewsService) GetHomepageData(ctx context.Context) (*port.HomepageData,
 	}
 
 	// Filter out Featured from Latest
-	var latest []*domain.News
+	latest := []*domain.News{}
 	count := 0
 	for _, news := range latestList {
 		if news.ID != featuredID {
@@ -154,8 +362,8 @@ func (s *NewsService) GetHomepageData(ctx context.Context) (*port.HomepageData,
 		}
 	}
 
-	// 3. Fetch Popular News (Limit 5)
-	popularList, err := s.repo.ListNews(ctx, 5, 0, nil, nil, "popular", nil, "")
+	// 3. Fetch Popular News (Limit 5), ranked by recent trending score
+	popularList, err := s.repo.ListNews(ctx, 5, 0, nil, nil, "trending", nil, "")
 	if err != nil {
 		return nil, err
 	}
@@ -167,6 +375,20 @@ func (s *NewsService) GetHomepageData(ctx context.Context) (*port.HomepageData,
 	}, nil
 }
 
+// validateThumbnailMeta trims the thumbnail details and blocks publishing an
+// image without alt text unless an editor explicitly allowed it.
+func validateThumbnailMeta(thumbnail string, meta *domain.ThumbnailMeta, allowMissingAlt bool) error {
+	meta.Alt = strings.TrimSpace(meta.Alt)
+	meta.Caption = strings.TrimSpace(meta.Caption)
+	meta.Credit = strings.TrimSpace(meta.Credit)
+	meta.SourceURL = strings.TrimSpace(meta.SourceURL)
+
+	if thumbnail != "" && meta.Alt == "" && !allowMissingAlt {
+		return fmt.Errorf("%w: thumbnail alt text is required to publish", domain.ErrInvalidInput)
+	}
+	return nil
+}
+
 func generateRawSlug(title string) string {
 	// Convert to lowercase
 	slug := strings.ToLower(title)
@@ -193,18 +415,27 @@ func generateCleanSlug(title string) string {
 	return generateRawSlug(title)
 }
 
+// maxRawSlugRunes leaves room for the timestamp suffix inside the VARCHAR(255) slug column
+const maxRawSlugRunes = 240
+
 func generateUniqueSlug(title string) string {
-	return fmt.Sprintf("%s-%d", generateRawSlug(title), time.Now().Unix())
+	raw := generateRawSlug(title)
+	if runes := []rune(raw); len(runes) > maxRawSlugRunes {
+		raw = strings.TrimRight(string(runes[:maxRawSlugRunes]), "-")
+	}
+	return fmt.Sprintf("%s-%d", raw, time.Now().Unix())
 }
 
 // Category Service
 
 type CategoryService struct {
-	repo port.CategoryRepository
+	repo   port.CategoryRepository
+	cache  *ResponseCache
+	purger port.CachePurger
 }
 
-func NewCategoryService(repo port.CategoryRepository) *CategoryService {
-	return &CategoryService{repo: repo}
+func NewCategoryService(repo port.CategoryRepository, cache *ResponseCache, purger port.CachePurger) *CategoryService {
+	return &CategoryService{repo: repo, cache: cache, purger: purger}
 }
 
 func (s *CategoryService) CreateCategory(ctx context.Context, name, nameBN, description string) (*domain.Category, error) {
@@ -215,7 +446,12 @@ func (s *CategoryService) CreateCategory(ctx context.Context, name, nameBN, desc
 		Slug:        slug,
 		Description: &description,
 	}
-	return s.repo.CreateCategory(ctx, category)
+	created, err := s.repo.CreateCategory(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx)
+	return created, nil
 }
 
 func (s *CategoryService) UpdateCategory(ctx context.Context, id uuid.UUID, name, nameBN, description string) error {
@@ -227,11 +463,35 @@ func (s *CategoryService) UpdateCategory(ctx context.Context, id uuid.UUID, name
 		Slug:        slug,
 		Description: &description,
 	}
-	return s.repo.UpdateCategory(ctx, category)
+	if err := s.repo.UpdateCategory(ctx, category); err != nil {
+		return err
+	}
+	s.invalidate(ctx)
+	return nil
 }
 
 func (s *CategoryService) DeleteCategory(ctx context.Context, id uuid.UUID) error {
-	return s.repo.DeleteCategory(ctx, id)
+	if err := s.repo.DeleteCategory(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate(ctx)
+	return nil
+}
+
+// invalidate drops every cached response. Category names and slugs are
+// embedded in articles and listings, and categories rarely change. At the
+// CDN only the category list, homepage and listings are purged; article
+// pages are private and never stored there.
+func (s *CategoryService) invalidate(ctx context.Context) {
+	s.cache.Invalidate(ctx, nil, "")
+
+	purge := port.CachePurge{
+		URLs:          []string{categoriesPath, homepagePath},
+		SurrogateKeys: []string{port.SurrogateKeyCategories, port.SurrogateKeyHomepage, port.SurrogateKeyNewsList},
+	}
+	if err := s.purger.Purge(ctx, purge); err != nil {
+		port.Logger(ctx).Warn("Failed to queue CDN purge", "error", err)
+	}
 }
 
 func (s *CategoryService) ListCategories(ctx context.Context) ([]*domain.Category, error) {
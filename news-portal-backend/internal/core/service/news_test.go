@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"news-portal-backend/internal/core/domain"
+	"news-portal-backend/internal/core/port"
+)
+
+// memoryNewsRepo holds articles by ID, counting the slug lookups that reach it
+type memoryNewsRepo struct {
+	port.NewsRepository
+	news         map[uuid.UUID]*domain.News
+	slugLookups  int
+	countLookups int
+}
+
+func newMemoryNewsRepo(articles ...*domain.News) *memoryNewsRepo {
+	r := &memoryNewsRepo{news: make(map[uuid.UUID]*domain.News)}
+	for _, n := range articles {
+		r.news[n.ID] = n
+	}
+	return r
+}
+
+func (r *memoryNewsRepo) GetNewsBySlug(ctx context.Context, slug string) (*domain.News, error) {
+	r.slugLookups++
+	for _, n := range r.news {
+		if n.Slug == slug {
+			article := *n
+			return &article, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryNewsRepo) GetNewsViewsCount(ctx context.Context, id uuid.UUID) (int64, error) {
+	r.countLookups++
+	if n, ok := r.news[id]; ok {
+		return n.ViewsCount, nil
+	}
+	return 0, nil
+}
+
+// countingViews counts recorded views and shares without buffering them
+type countingViews struct {
+	views  int
+	shares int
+}
+
+func (v *countingViews) RecordView(id uuid.UUID, viewer port.Viewer) {
+	v.views++
+}
+
+func (v *countingViews) RecordShare(id uuid.UUID, network string, viewer port.Viewer) {
+	v.shares++
+}
+
+// The article body and its view count are cached separately, and a view
+// is recorded on every request
+func TestGetNewsBySlugCachesViewCount(t *testing.T) {
+	article := &domain.News{ID: uuid.New(), Slug: "cached-views", Title: "Cached views", ViewsCount: 7, RawViewsCount: 9}
+	repo := newMemoryNewsRepo(article)
+	views := &countingViews{}
+	cache := NewResponseCache(NewMemoryCache(10), time.Minute, time.Minute)
+	s := NewNewsService(repo, nil, views, cache, NoopCachePurger{})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		got, err := s.GetNewsBySlug(ctx, "cached-views", port.Viewer{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ViewsCount != 7 || got.RawViewsCount != 0 {
+			t.Errorf("read %d counts = %d/%d, want 7/0", i, got.ViewsCount, got.RawViewsCount)
+		}
+	}
+	if repo.slugLookups != 1 || repo.countLookups != 1 {
+		t.Errorf("article loaded %d times and its count %d times, want once each", repo.slugLookups, repo.countLookups)
+	}
+	if views.views != 3 {
+		t.Errorf("recorded %d views, want 3", views.views)
+	}
+
+	// Once the count expires it is read again, without reloading the article
+	s.viewCounts.cache.Delete(ctx, newsViewsCachePrefix+article.ID.String())
+	article.ViewsCount = 12
+	got, err := s.GetNewsBySlug(ctx, "cached-views", port.Viewer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ViewsCount != 12 || repo.slugLookups != 1 {
+		t.Errorf("views = %d after %d article loads, want 12 after 1", got.ViewsCount, repo.slugLookups)
+	}
+}
+
+// Shares are only counted for articles that exist
+func TestRecordShareChecksArticle(t *testing.T) {
+	article := &domain.News{ID: uuid.New(), Slug: "shared"}
+	views := &countingViews{}
+	cache := NewResponseCache(NewMemoryCache(10), time.Minute, time.Minute)
+	s := NewNewsService(newMemoryNewsRepo(article), nil, views, cache, NoopCachePurger{})
+	ctx := context.Background()
+
+	if err := s.RecordShare(ctx, article.ID, "facebook", port.Viewer{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RecordShare(ctx, uuid.New(), "facebook", port.Viewer{}); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+	if views.shares != 1 {
+		t.Errorf("recorded %d shares, want 1", views.shares)
+	}
+}
+
+func (r *memoryNewsRepo) CreateNews(ctx context.Context, news *domain.News) (*domain.News, error) {
+	news.ID = uuid.New()
+	article := *news
+	r.news[news.ID] = &article
+	return news, nil
+}
+
+func (r *memoryNewsRepo) GetNewsByID(ctx context.Context, id uuid.UUID) (*domain.News, error) {
+	n, ok := r.news[id]
+	if !ok {
+		return nil, nil
+	}
+	article := *n
+	return &article, nil
+}
+
+func (r *memoryNewsRepo) UpdateNews(ctx context.Context, news *domain.News) error {
+	existing, ok := r.news[news.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	article := *news
+	article.Slug = existing.Slug
+	r.news[news.ID] = &article
+	return nil
+}
+
+func (r *memoryNewsRepo) DeleteNews(ctx context.Context, id uuid.UUID) error {
+	delete(r.news, id)
+	return nil
+}
+
+// Every article write purges the homepage, the listings and the article's
+// own page and surrogate key
+func TestNewsWritesPurgeCDN(t *testing.T) {
+	repo := newMemoryNewsRepo()
+	purger := &RecordingCachePurger{}
+	s := NewNewsService(repo, nil, &countingViews{}, nil, purger)
+	ctx := context.Background()
+
+	created, err := s.CreateNews(ctx, uuid.New(), uuid.New(), "Purge me", "", "<p>Body</p>", "", nil, domain.ThumbnailMeta{}, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateNews(ctx, created.ID, uuid.New(), "Purge me again", "", "<p>Body</p>", "", nil, domain.ThumbnailMeta{}, true, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteNews(ctx, created.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	want := port.CachePurge{
+		URLs:          []string{homepagePath, newsPathPrefix + created.Slug},
+		SurrogateKeys: []string{port.SurrogateKeyHomepage, port.SurrogateKeyNewsList, port.SurrogateKeyForNews(created.ID)},
+	}
+	purges := purger.Purges()
+	if len(purges) != 3 {
+		t.Fatalf("got %d purges, want 3: %+v", len(purges), purges)
+	}
+	for i, write := range []string{"create", "update", "delete"} {
+		if !reflect.DeepEqual(purges[i], want) {
+			t.Errorf("%s purged %+v, want %+v", write, purges[i], want)
+		}
+	}
+}
+
+// memoryCategoryRepo accepts every category write
+type memoryCategoryRepo struct {
+	port.CategoryRepository
+}
+
+func (memoryCategoryRepo) CreateCategory(ctx context.Context, category *domain.Category) (*domain.Category, error) {
+	category.ID = uuid.New()
+	return category, nil
+}
+
+func (memoryCategoryRepo) UpdateCategory(ctx context.Context, category *domain.Category) error {
+	return nil
+}
+
+func (memoryCategoryRepo) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+// Category writes purge the category list, the homepage and the listings,
+// but no article pages
+func TestCategoryWritesPurgeCDN(t *testing.T) {
+	purger := &RecordingCachePurger{}
+	s := NewCategoryService(memoryCategoryRepo{}, nil, purger)
+	ctx := context.Background()
+
+	created, err := s.CreateCategory(ctx, "Sports", "খেলা", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateCategory(ctx, created.ID, "Sport", "খেলা", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteCategory(ctx, created.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	want := port.CachePurge{
+		URLs:          []string{categoriesPath, homepagePath},
+		SurrogateKeys: []string{port.SurrogateKeyCategories, port.SurrogateKeyHomepage, port.SurrogateKeyNewsList},
+	}
+	purges := purger.Purges()
+	if len(purges) != 3 {
+		t.Fatalf("got %d purges, want 3: %+v", len(purges), purges)
+	}
+	for i, write := range []string{"create", "update", "delete"} {
+		if !reflect.DeepEqual(purges[i], want) {
+			t.Errorf("%s purged %+v, want %+v", write, purges[i], want)
+		}
+	}
+}
@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"mime/multipart"
-	"path/filepath"
+	"io"
 	"strings"
 	"time"
 
@@ -12,16 +14,24 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	smithymiddleware "github.com/aws/smithy-go/middleware"
 	"github.com/google/uuid"
+
+	"news-portal-backend/internal/core/domain"
+	"news-portal-backend/internal/core/port"
 )
 
 type R2Service struct {
 	client     *s3.Client
+	presigner  *s3.PresignClient
 	bucketName string
 	publicURL  string
 }
 
-func NewR2Service(accountId, accessKey, secretKey, bucketName, publicURL string) (*R2Service, error) {
+// NewR2Service connects to an R2 bucket. apiOptions are added to every
+// client operation's middleware stack, e.g. for tracing.
+func NewR2Service(accountId, accessKey, secretKey, bucketName, publicURL string, apiOptions ...func(*smithymiddleware.Stack) error) (*R2Service, error) {
 	r2Resolver := aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
 		return aws.Endpoint{
 			URL: fmt.Sprintf("https://%s.r2.cloudflarestorage.com", accountId),
@@ -32,6 +42,7 @@ func NewR2Service(accountId, accessKey, secretKey, bucketName, publicURL string)
 		config.WithEndpointResolverWithOptions(r2Resolver),
 		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
 		config.WithRegion("auto"),
+		config.WithAPIOptions(apiOptions),
 	)
 	if err != nil {
 		return nil, err
@@ -41,25 +52,143 @@ func NewR2Service(accountId, accessKey, secretKey, bucketName, publicURL string)
 
 	return &R2Service{
 		client:     client,
+		presigner:  s3.NewPresignClient(client),
 		bucketName: bucketName,
 		publicURL:  publicURL,
 	}, nil
 }
 
-func (s *R2Service) UploadFile(file multipart.File, header *multipart.FileHeader) (string, error) {
-	ext := filepath.Ext(header.Filename)
-	filename := fmt.Sprintf("%d-%s%s", time.Now().Unix(), uuid.New().String(), ext)
+// PutObject streams body to R2. The length is sent up front so the body
+// doesn't need to be seekable.
+func (s *R2Service) PutObject(ctx context.Context, key, contentType string, body io.Reader, size int64) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucketName),
+		Key:           aws.String(key),
+		Body:          body,
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload to R2: %w", err)
+	}
+	return nil
+}
+
+// PresignUpload signs a PUT for the exact content type and length, so R2
+// rejects bodies that don't match what the client declared.
+func (s *R2Service) PresignUpload(ctx context.Context, key, contentType string, size int64, sha256 string, ttl time.Duration) (*port.PresignedRequest, error) {
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucketName),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}
+	headers := map[string]string{"Content-Type": contentType}
+	if sha256 != "" {
+		sum, err := hex.DecodeString(sha256)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid sha256", domain.ErrInvalidInput)
+		}
+		checksum := base64.StdEncoding.EncodeToString(sum)
+		input.ChecksumSHA256 = aws.String(checksum)
+		headers["x-amz-checksum-sha256"] = checksum
+	}
+
+	req, err := s.presigner.PresignPutObject(ctx, input, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign R2 upload: %w", err)
+	}
+	return &port.PresignedRequest{URL: req.URL, Headers: headers}, nil
+}
+
+func (s *R2Service) StatObject(ctx context.Context, key string) (*port.ObjectInfo, error) {
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to stat R2 object: %w", err)
+	}
+	size := aws.ToInt64(out.ContentLength)
+
+	// The stored Content-Type is whatever the client sent with its PUT, so
+	// the type is sniffed from the first bytes like the local backend does.
+	// An empty object can't be fetched by range.
+	var head []byte
+	if size > 0 {
+		obj, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(s.bucketName),
+			Key:    aws.String(key),
+			Range:  aws.String("bytes=0-511"),
+		})
+		if err != nil {
+			var noSuchKey *types.NoSuchKey
+			if errors.As(err, &noSuchKey) {
+				return nil, domain.ErrNotFound
+			}
+			return nil, fmt.Errorf("failed to read R2 object: %w", err)
+		}
+		defer obj.Body.Close()
+		head, err = io.ReadAll(io.LimitReader(obj.Body, 512))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read R2 object: %w", err)
+		}
+	}
+
+	return &port.ObjectInfo{
+		Size:        size,
+		ContentType: sniffContentType(head),
+	}, nil
+}
+
+func (s *R2Service) OpenObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read R2 object: %w", err)
+	}
+	return out.Body, nil
+}
 
-	_, err := s.client.PutObject(context.TODO(), &s3.PutObjectInput{
-		Bucket:      aws.String(s.bucketName),
-		Key:         aws.String(filename),
-		Body:        file,
-		ContentType: aws.String(header.Header.Get("Content-Type")),
+func (s *R2Service) Ping(ctx context.Context) error {
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(s.bucketName)})
+	if err != nil {
+		return fmt.Errorf("failed to reach R2 bucket: %w", err)
+	}
+	return nil
+}
+
+func (s *R2Service) DeleteObject(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to upload to R2: %w", err)
+		return fmt.Errorf("failed to delete R2 object: %w", err)
 	}
+	return nil
+}
+
+func (s *R2Service) ObjectURL(key string) string {
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(s.publicURL, "/"), key)
+}
 
-	// Return the direct public R2 URL for maximum performance
-	return fmt.Sprintf("%s/%s", strings.TrimSuffix(s.publicURL, "/"), filename), nil
+// newObjectKey builds the time-uuid.ext key used for every stored object.
+// The extension follows the allowlisted content type, never the client's
+// filename, so a file can't be served as something it wasn't checked as.
+func newObjectKey(contentType string) string {
+	ext := mediaExtensions[contentType]
+	return fmt.Sprintf("%d-%s%s", time.Now().Unix(), uuid.New().String(), ext)
 }
+
+var _ port.FileService = (*R2Service)(nil)
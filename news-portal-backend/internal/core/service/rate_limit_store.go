@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"news-portal-backend/internal/core/port"
+)
+
+// rateLimitSweepInterval is how often idle buckets are evicted
+const rateLimitSweepInterval = time.Minute
+
+// MemoryRateLimitStore is an in-process port.RateLimitStore. Each key is a
+// token bucket holding limit tokens that refills over window.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*rateLimitBucket
+	lastSweep time.Time
+}
+
+type rateLimitBucket struct {
+	limiter  *rate.Limiter
+	window   time.Duration
+	lastSeen time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets:   make(map[string]*rateLimitBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryRateLimitStore) TakeRateLimit(ctx context.Context, key string, limit int, window time.Duration) (*port.RateLimitResult, error) {
+	return s.rateLimit(key, limit, window, true), nil
+}
+
+func (s *MemoryRateLimitStore) CheckRateLimit(ctx context.Context, key string, limit int, window time.Duration) (*port.RateLimitResult, error) {
+	return s.rateLimit(key, limit, window, false), nil
+}
+
+// rateLimit reports the limit on key, taking a token when take is set
+func (s *MemoryRateLimitStore) rateLimit(key string, limit int, window time.Duration, take bool) *port.RateLimitResult {
+	now := time.Now()
+	refill := rate.Limit(float64(limit) / window.Seconds())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= rateLimitSweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		if !take {
+			return &port.RateLimitResult{Allowed: true, Remaining: limit}
+		}
+		b = &rateLimitBucket{limiter: rate.NewLimiter(refill, limit), window: window}
+		s.buckets[key] = b
+	}
+
+	var result *port.RateLimitResult
+	if take {
+		b.lastSeen = now
+		result = &port.RateLimitResult{Allowed: b.limiter.AllowN(now, 1)}
+	} else {
+		result = &port.RateLimitResult{Allowed: b.limiter.TokensAt(now) >= 1}
+	}
+	tokens := b.limiter.TokensAt(now)
+	result.Remaining = max(int(math.Floor(tokens)), 0)
+	result.Reset = time.Duration((float64(limit) - tokens) / float64(refill) * float64(time.Second))
+	if !result.Allowed {
+		result.RetryAfter = time.Duration((1 - tokens) / float64(refill) * float64(time.Second))
+	}
+	return result
+}
+
+// sweep drops buckets idle for a full window: they have refilled and are no
+// different from a new one
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if now.Sub(b.lastSeen) >= max(b.window, rateLimitSweepInterval) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+// FallbackRateLimitStore uses a shared store and falls back to a local one
+// when the shared store can't be reached or is slow, so a database hiccup
+// degrades limits to per replica instead of rejecting or stalling traffic.
+// After an outage the shared store is skipped for a cooldown, so it doesn't
+// add the timeout to every request. Other errors, such as a key the store
+// rejects, are returned without affecting other keys.
+type FallbackRateLimitStore struct {
+	primary  port.RateLimitStore
+	fallback port.RateLimitStore
+	timeout  time.Duration
+	cooldown time.Duration
+
+	// Unix nanoseconds until which the primary is skipped
+	skipUntil atomic.Int64
+}
+
+func NewFallbackRateLimitStore(primary, fallback port.RateLimitStore, timeout, cooldown time.Duration) *FallbackRateLimitStore {
+	return &FallbackRateLimitStore{
+		primary:  primary,
+		fallback: fallback,
+		timeout:  timeout,
+		cooldown: cooldown,
+	}
+}
+
+func (s *FallbackRateLimitStore) TakeRateLimit(ctx context.Context, key string, limit int, window time.Duration) (*port.RateLimitResult, error) {
+	return s.rateLimit(ctx, port.RateLimitStore.TakeRateLimit, key, limit, window)
+}
+
+func (s *FallbackRateLimitStore) CheckRateLimit(ctx context.Context, key string, limit int, window time.Duration) (*port.RateLimitResult, error) {
+	return s.rateLimit(ctx, port.RateLimitStore.CheckRateLimit, key, limit, window)
+}
+
+type rateLimitFunc func(store port.RateLimitStore, ctx context.Context, key string, limit int, window time.Duration) (*port.RateLimitResult, error)
+
+func (s *FallbackRateLimitStore) rateLimit(ctx context.Context, fn rateLimitFunc, key string, limit int, window time.Duration) (*port.RateLimitResult, error) {
+	now := time.Now()
+	if now.UnixNano() >= s.skipUntil.Load() {
+		primaryCtx, cancel := context.WithTimeout(ctx, s.timeout)
+		result, err := fn(s.primary, primaryCtx, key, limit, window)
+		timedOut := primaryCtx.Err() != nil
+		cancel()
+		if err == nil {
+			return result, nil
+		}
+		// The client going away isn't the store's fault
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !timedOut && !errors.Is(err, port.ErrRateLimitStoreUnavailable) {
+			return nil, err
+		}
+		if s.skipUntil.Swap(now.Add(s.cooldown).UnixNano()) < now.UnixNano() {
+			slog.Warn("Shared rate limit store failed, using per-replica limits", "cooldown", s.cooldown, "error", err)
+		}
+	}
+	return fn(s.fallback, ctx, key, limit, window)
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"news-portal-backend/internal/core/port"
+)
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryRateLimitStore()
+
+	for i := 2; i >= 0; i-- {
+		r, err := s.TakeRateLimit(ctx, "a", 3, 3*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !r.Allowed || r.Remaining != i {
+			t.Fatalf("request %d: %+v", 3-i, r)
+		}
+	}
+	r, _ := s.TakeRateLimit(ctx, "a", 3, 3*time.Second)
+	if r.Allowed {
+		t.Fatal("fourth request was allowed")
+	}
+	if r.RetryAfter <= 0 || r.RetryAfter > time.Second {
+		t.Errorf("RetryAfter = %v, want up to one refill interval", r.RetryAfter)
+	}
+	if r.Reset <= 2*time.Second || r.Reset > 3*time.Second {
+		t.Errorf("Reset = %v, want about the full window", r.Reset)
+	}
+
+	// Keys are independent
+	if r, _ := s.TakeRateLimit(ctx, "b", 3, 3*time.Second); !r.Allowed {
+		t.Error("another key was limited")
+	}
+}
+
+func TestMemoryRateLimitStoreCheckDoesNotCount(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryRateLimitStore()
+
+	for i := 0; i < 3; i++ {
+		if r, _ := s.CheckRateLimit(ctx, "a", 1, time.Minute); !r.Allowed || r.Remaining != 1 {
+			t.Fatalf("check %d: %+v", i, r)
+		}
+	}
+	s.TakeRateLimit(ctx, "a", 1, time.Minute)
+	if r, _ := s.CheckRateLimit(ctx, "a", 1, time.Minute); r.Allowed || r.RetryAfter <= 0 {
+		t.Fatalf("check after the limit: %+v", r)
+	}
+}
+
+func TestMemoryRateLimitStoreEvictsIdleBuckets(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryRateLimitStore()
+	s.TakeRateLimit(ctx, "short", 1, time.Second)
+	s.TakeRateLimit(ctx, "long", 1, time.Hour)
+
+	s.mu.Lock()
+	s.sweep(time.Now().Add(2 * time.Minute))
+	_, short := s.buckets["short"]
+	_, long := s.buckets["long"]
+	s.mu.Unlock()
+
+	if short {
+		t.Error("a bucket idle for longer than its window was kept")
+	}
+	if !long {
+		t.Error("a bucket still refilling was evicted")
+	}
+}
+
+type stubRateLimitStore struct {
+	calls  int
+	err    error
+	result *port.RateLimitResult
+	delay  time.Duration
+}
+
+func (s *stubRateLimitStore) TakeRateLimit(ctx context.Context, key string, limit int, window time.Duration) (*port.RateLimitResult, error) {
+	s.calls++
+	if s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return s.result, s.err
+}
+
+func (s *stubRateLimitStore) CheckRateLimit(ctx context.Context, key string, limit int, window time.Duration) (*port.RateLimitResult, error) {
+	return s.TakeRateLimit(ctx, key, limit, window)
+}
+
+func TestFallbackRateLimitStoreUsesPrimary(t *testing.T) {
+	primary := &stubRateLimitStore{result: &port.RateLimitResult{Allowed: false}}
+	fallback := &stubRateLimitStore{result: &port.RateLimitResult{Allowed: true}}
+	s := NewFallbackRateLimitStore(primary, fallback, time.Second, time.Minute)
+
+	r, err := s.TakeRateLimit(context.Background(), "k", 1, time.Second)
+	if err != nil || r.Allowed {
+		t.Fatalf("got %+v, %v; want the primary's rejection", r, err)
+	}
+	if fallback.calls != 0 {
+		t.Error("fallback was used while the primary works")
+	}
+}
+
+func TestFallbackRateLimitStoreToleratesFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		primary *stubRateLimitStore
+	}{
+		{"error", &stubRateLimitStore{err: fmt.Errorf("%w: connection refused", port.ErrRateLimitStoreUnavailable)}},
+		{"timeout", &stubRateLimitStore{delay: time.Second, result: &port.RateLimitResult{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallback := NewMemoryRateLimitStore()
+			s := NewFallbackRateLimitStore(tt.primary, fallback, 10*time.Millisecond, time.Minute)
+
+			for i := 0; i < 3; i++ {
+				r, err := s.TakeRateLimit(context.Background(), "k", 2, time.Minute)
+				if err != nil {
+					t.Fatalf("request %d: %v", i, err)
+				}
+				if r.Allowed != (i < 2) {
+					t.Fatalf("request %d: allowed = %v; the fallback must still enforce the limit", i, r.Allowed)
+				}
+			}
+			if tt.primary.calls != 1 {
+				t.Errorf("primary called %d times, want it skipped during the cooldown", tt.primary.calls)
+			}
+		})
+	}
+}
+
+// An error from the store rejecting the request, rather than from not
+// reaching it, doesn't switch every key to the fallback
+func TestFallbackRateLimitStoreReturnsRequestErrors(t *testing.T) {
+	rejected := errors.New("value too long")
+	primary := &stubRateLimitStore{err: rejected}
+	fallback := &stubRateLimitStore{result: &port.RateLimitResult{Allowed: true}}
+	s := NewFallbackRateLimitStore(primary, fallback, time.Second, time.Minute)
+
+	if _, err := s.TakeRateLimit(context.Background(), "k", 1, time.Second); !errors.Is(err, rejected) {
+		t.Fatalf("err = %v, want the primary's error", err)
+	}
+	if fallback.calls != 0 || s.skipUntil.Load() != 0 {
+		t.Error("a rejected request opened the circuit")
+	}
+}
+
+func TestFallbackRateLimitStoreRetriesAfterCooldown(t *testing.T) {
+	primary := &stubRateLimitStore{err: fmt.Errorf("%w: connection refused", port.ErrRateLimitStoreUnavailable)}
+	s := NewFallbackRateLimitStore(primary, NewMemoryRateLimitStore(), time.Second, time.Millisecond)
+
+	s.TakeRateLimit(context.Background(), "k", 10, time.Minute)
+	time.Sleep(5 * time.Millisecond)
+	s.TakeRateLimit(context.Background(), "k", 10, time.Minute)
+	if primary.calls != 2 {
+		t.Errorf("primary called %d times, want a retry after the cooldown", primary.calls)
+	}
+}
+
+func TestFallbackRateLimitStoreCanceledRequest(t *testing.T) {
+	primary := &stubRateLimitStore{delay: time.Second}
+	s := NewFallbackRateLimitStore(primary, NewMemoryRateLimitStore(), time.Second, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.TakeRateLimit(ctx, "k", 1, time.Second); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if s.skipUntil.Load() != 0 {
+		t.Error("a client going away opened the circuit")
+	}
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"news-portal-backend/internal/core/domain"
+	"news-portal-backend/internal/core/port"
+)
+
+const (
+	defaultReportTimezone = "Asia/Dhaka"
+	maxReportRecipients   = 50
+	reportRunHistory      = 50
+)
+
+// ReportService manages editorial report schedules and sends the reports.
+// RunDue is meant to be run every minute as a Job.
+type ReportService struct {
+	repo   port.ReportRepository
+	stats  port.StatsService
+	mailer port.Mailer
+}
+
+func NewReportService(repo port.ReportRepository, stats port.StatsService, mailer port.Mailer) *ReportService {
+	return &ReportService{repo: repo, stats: stats, mailer: mailer}
+}
+
+func (s *ReportService) CreateSchedule(ctx context.Context, ownerID uuid.UUID, input port.ReportScheduleInput) (*domain.ReportSchedule, error) {
+	schedule := &domain.ReportSchedule{OwnerID: ownerID, Enabled: true}
+	if err := applyReportScheduleInput(schedule, input); err != nil {
+		return nil, err
+	}
+	return s.repo.CreateReportSchedule(ctx, schedule)
+}
+
+func (s *ReportService) UpdateSchedule(ctx context.Context, ownerID, id uuid.UUID, input port.ReportScheduleInput) (*domain.ReportSchedule, error) {
+	schedule, err := s.ownedSchedule(ctx, ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyReportScheduleInput(schedule, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateReportSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *ReportService) DeleteSchedule(ctx context.Context, ownerID, id uuid.UUID) error {
+	if _, err := s.ownedSchedule(ctx, ownerID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteReportSchedule(ctx, id)
+}
+
+func (s *ReportService) ListSchedules(ctx context.Context, ownerID uuid.UUID) ([]*domain.ReportSchedule, error) {
+	return s.repo.ListReportSchedules(ctx, ownerID)
+}
+
+func (s *ReportService) ListRuns(ctx context.Context, ownerID, scheduleID uuid.UUID) ([]*domain.ReportRun, error) {
+	if _, err := s.ownedSchedule(ctx, ownerID, scheduleID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListReportRuns(ctx, scheduleID, reportRunHistory)
+}
+
+func (s *ReportService) SendNow(ctx context.Context, ownerID, scheduleID uuid.UUID) (*domain.ReportRun, error) {
+	schedule, err := s.ownedSchedule(ctx, ownerID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	occurrence, err := lastOccurrence(schedule, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return s.run(ctx, schedule, occurrence)
+}
+
+// RunDue sends every enabled report whose most recent scheduled time has
+// not been handled yet. A report missed while the API was down is sent once
+// when it comes back; older misses are not replayed.
+func (s *ReportService) RunDue(ctx context.Context) error {
+	schedules, err := s.repo.ListEnabledReportSchedules(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, schedule := range schedules {
+		occurrence, err := lastOccurrence(schedule, now)
+		if err != nil {
+			slog.Error("Invalid report schedule", "schedule_id", schedule.ID, "error", err)
+			continue
+		}
+		// Schedules don't fire for times before they were created
+		if occurrence.Before(schedule.CreatedAt) {
+			continue
+		}
+		if schedule.LastScheduledAt != nil && !schedule.LastScheduledAt.Before(occurrence) {
+			continue
+		}
+
+		claimed, err := s.repo.ClaimReportSchedule(ctx, schedule.ID, occurrence)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		if _, err := s.run(ctx, schedule, occurrence); err != nil {
+			slog.Error("Scheduled report failed", "schedule_id", schedule.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// run renders and sends the report for the period ending at occurrence and
+// records the attempt. The returned run carries the final status; the error
+// is only set when the run could not be recorded at all.
+func (s *ReportService) run(ctx context.Context, schedule *domain.ReportSchedule, occurrence time.Time) (*domain.ReportRun, error) {
+	from, to, err := reportPeriod(schedule, occurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := s.repo.CreateReportRun(ctx, &domain.ReportRun{
+		ScheduleID: schedule.ID,
+		Status:     domain.ReportRunRunning,
+		PeriodFrom: from,
+		PeriodTo:   to,
+		Recipients: schedule.Recipients,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sendErr := s.send(ctx, schedule, from, to)
+
+	run.Status = domain.ReportRunSent
+	if sendErr != nil {
+		run.Status = domain.ReportRunFailed
+		msg := sendErr.Error()
+		run.Error = &msg
+	}
+	// Record the outcome even if the send used up the caller's deadline
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := s.repo.FinishReportRun(finishCtx, run.ID, run.Status, run.Error); err != nil {
+		return nil, err
+	}
+	finished := time.Now()
+	run.FinishedAt = &finished
+	return run, nil
+}
+
+func (s *ReportService) send(ctx context.Context, schedule *domain.ReportSchedule, from, to time.Time) error {
+	stats, err := s.stats.GetDashboardStats(ctx, port.StatsFilter{From: from, To: to})
+	if err != nil {
+		return fmt.Errorf("load stats: %w", err)
+	}
+
+	msg, err := renderReport(schedule, from, to, stats)
+	if err != nil {
+		return fmt.Errorf("render report: %w", err)
+	}
+	msg.To = schedule.Recipients
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("send report: %w", err)
+	}
+	return nil
+}
+
+// ownedSchedule loads a schedule and hides schedules of other owners
+func (s *ReportService) ownedSchedule(ctx context.Context, ownerID, id uuid.UUID) (*domain.ReportSchedule, error) {
+	schedule, err := s.repo.GetReportSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil || schedule.OwnerID != ownerID {
+		return nil, domain.ErrNotFound
+	}
+	return schedule, nil
+}
+
+func applyReportScheduleInput(schedule *domain.ReportSchedule, input port.ReportScheduleInput) error {
+	var problems []string
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > 255 {
+		problems = append(problems, "name is required and must be at most 255 characters")
+	}
+
+	recipients := make([]string, 0, len(input.Recipients))
+	for _, r := range input.Recipients {
+		addr, err := mail.ParseAddress(strings.TrimSpace(r))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("invalid recipient %q", r))
+			continue
+		}
+		recipients = append(recipients, addr.Address)
+	}
+	if len(input.Recipients) == 0 || len(input.Recipients) > maxReportRecipients {
+		problems = append(problems, fmt.Sprintf("between 1 and %d recipients are required", maxReportRecipients))
+	}
+
+	frequency := input.Frequency
+	if frequency == "" {
+		frequency = domain.ReportDaily
+	}
+	if frequency != domain.ReportDaily && frequency != domain.ReportWeekly {
+		problems = append(problems, "frequency must be daily or weekly")
+	}
+	if input.Weekday < 0 || input.Weekday > 6 {
+		problems = append(problems, "weekday must be between 0 (Sunday) and 6")
+	}
+	if input.SendHour < 0 || input.SendHour > 23 || input.SendMinute < 0 || input.SendMinute > 59 {
+		problems = append(problems, "send time must be a valid hour and minute")
+	}
+
+	timezone := input.Timezone
+	if timezone == "" {
+		timezone = defaultReportTimezone
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		problems = append(problems, fmt.Sprintf("unknown timezone %q", timezone))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidInput, strings.Join(problems, "; "))
+	}
+
+	schedule.Name = name
+	schedule.Recipients = recipients
+	schedule.Frequency = frequency
+	schedule.Weekday = input.Weekday
+	schedule.SendHour = input.SendHour
+	schedule.SendMinute = input.SendMinute
+	schedule.Timezone = timezone
+	if input.Enabled != nil {
+		schedule.Enabled = *input.Enabled
+	}
+	return nil
+}
+
+// lastOccurrence returns the most recent time at or before now that the
+// schedule is due, in the schedule's timezone. Days are stepped back on the
+// calendar and the send time set afresh on each, since a send time that
+// falls in a DST gap is shifted and must not carry over to other days.
+func lastOccurrence(schedule *domain.ReportSchedule, now time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	local := now.In(loc)
+	for back := 0; back <= 7; back++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()-back, 0, 0, 0, 0, time.UTC)
+		if schedule.Frequency == domain.ReportWeekly && int(day.Weekday()) != schedule.Weekday {
+			continue
+		}
+		t := time.Date(day.Year(), day.Month(), day.Day(), schedule.SendHour, schedule.SendMinute, 0, 0, loc)
+		if !t.After(now) {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no occurrence of weekday %d", schedule.Weekday)
+}
+
+// reportPeriod returns the whole days a report sent at occurrence covers:
+// the day before for daily reports, the seven days before for weekly ones
+func reportPeriod(schedule *domain.ReportSchedule, occurrence time.Time) (time.Time, time.Time, error) {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	local := occurrence.In(loc)
+	to := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	days := 1
+	if schedule.Frequency == domain.ReportWeekly {
+		days = 7
+	}
+	return to.AddDate(0, 0, -days), to, nil
+}
+
+var _ port.ReportService = (*ReportService)(nil)
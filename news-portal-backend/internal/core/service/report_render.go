@@ -0,0 +1,126 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"text/template"
+	"time"
+
+	"news-portal-backend/internal/core/domain"
+	"news-portal-backend/internal/core/port"
+)
+
+type reportData struct {
+	Name  string
+	From  time.Time
+	To    time.Time
+	Stats *port.DashboardStats
+}
+
+// LastDay is the last day covered, for display; the period itself ends at
+// midnight
+func (d reportData) LastDay() time.Time {
+	return d.To.Add(-time.Nanosecond)
+}
+
+// SingleDay allows for the 25-hour day when clocks go back
+func (d reportData) SingleDay() bool {
+	return d.To.Sub(d.From) <= 25*time.Hour
+}
+
+var reportFuncs = map[string]any{
+	"date":  formatReportDate,
+	"delta": formatReportDelta,
+	"avg":   func(v float64) string { return fmt.Sprintf("%.1f", v) },
+	"inc":   func(i int) int { return i + 1 },
+}
+
+func formatReportDate(t time.Time) string {
+	return t.Format("Mon, 2 Jan 2006")
+}
+
+// formatReportDelta shows the change against the previous period, e.g. "+12.5%"
+func formatReportDelta(d port.StatDelta) string {
+	if d.Percent == nil {
+		return ""
+	}
+	return fmt.Sprintf("(%+.1f%%)", *d.Percent)
+}
+
+const reportText = `{{.Name}}
+{{if .SingleDay}}{{date .From}}{{else}}{{date .From}} - {{date .LastDay}}{{end}}
+
+Articles published: {{.Stats.Period.ArticlesPublished}} {{delta .Stats.Deltas.ArticlesPublished}}
+Views: {{.Stats.Period.Views}} {{delta .Stats.Deltas.Views}}
+
+TOP STORIES
+{{range $i, $n := .Stats.TopNews}}{{inc $i}}. {{$n.Title}} ({{$n.Views}} views)
+{{else}}No views recorded.
+{{end}}
+TRAFFIC BY CATEGORY
+{{range .Stats.CategoryStats}}- {{.Name}}: {{.Value}} views
+{{else}}No views recorded.
+{{end}}
+ARTICLES PER REPORTER
+{{range .Stats.Authors}}- {{.Name}}: {{.ArticlesPublished}} published, {{.Views}} views ({{avg .AvgViewsPerArticle}} per article)
+{{else}}Nothing published.
+{{end}}`
+
+const reportHTML = `<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, sans-serif; color: #111; max-width: 640px;">
+<h2 style="margin-bottom: 0;">{{.Name}}</h2>
+<p style="color: #666; margin-top: 4px;">{{if .SingleDay}}{{date .From}}{{else}}{{date .From}} &ndash; {{date .LastDay}}{{end}}</p>
+
+<table cellpadding="8" style="border-collapse: collapse;">
+<tr>
+<td><strong style="font-size: 22px;">{{.Stats.Period.ArticlesPublished}}</strong><br>articles published <span style="color: #666;">{{delta .Stats.Deltas.ArticlesPublished}}</span></td>
+<td><strong style="font-size: 22px;">{{.Stats.Period.Views}}</strong><br>views <span style="color: #666;">{{delta .Stats.Deltas.Views}}</span></td>
+</tr>
+</table>
+
+<h3>Top stories</h3>
+{{if .Stats.TopNews}}<ol>{{range .Stats.TopNews}}<li>{{.Title}} <span style="color: #666;">({{.Views}} views)</span></li>{{end}}</ol>
+{{else}}<p>No views recorded.</p>{{end}}
+
+<h3>Traffic by category</h3>
+{{if .Stats.CategoryStats}}<table cellpadding="4" style="border-collapse: collapse;">
+{{range .Stats.CategoryStats}}<tr><td>{{.Name}}</td><td align="right">{{.Value}}</td></tr>
+{{end}}</table>
+{{else}}<p>No views recorded.</p>{{end}}
+
+<h3>Articles per reporter</h3>
+{{if .Stats.Authors}}<table cellpadding="4" style="border-collapse: collapse;">
+<tr><th align="left">Reporter</th><th align="right">Published</th><th align="right">Views</th><th align="right">Per article</th></tr>
+{{range .Stats.Authors}}<tr><td>{{.Name}}</td><td align="right">{{.ArticlesPublished}}</td><td align="right">{{.Views}}</td><td align="right">{{avg .AvgViewsPerArticle}}</td></tr>
+{{end}}</table>
+{{else}}<p>Nothing published.</p>{{end}}
+</body>
+</html>
+`
+
+var (
+	reportTextTemplate = template.Must(template.New("report.txt").Funcs(reportFuncs).Parse(reportText))
+	reportHTMLTemplate = htmltemplate.Must(htmltemplate.New("report.html").Funcs(reportFuncs).Parse(reportHTML))
+)
+
+// renderReport builds the subject and the plain text and HTML bodies of a
+// report. The recipients are left for the caller to fill in.
+func renderReport(schedule *domain.ReportSchedule, from, to time.Time, stats *port.DashboardStats) (port.MailMessage, error) {
+	data := reportData{Name: schedule.Name, From: from, To: to, Stats: stats}
+
+	var text, html bytes.Buffer
+	if err := reportTextTemplate.Execute(&text, data); err != nil {
+		return port.MailMessage{}, err
+	}
+	if err := reportHTMLTemplate.Execute(&html, data); err != nil {
+		return port.MailMessage{}, err
+	}
+
+	subject := schedule.Name + " – " + formatReportDate(from)
+	if !data.SingleDay() {
+		subject += " to " + formatReportDate(data.LastDay())
+	}
+	return port.MailMessage{Subject: subject, Text: text.String(), HTML: html.String()}, nil
+}
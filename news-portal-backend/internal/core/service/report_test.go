@@ -0,0 +1,211 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"news-portal-backend/internal/core/domain"
+	"news-portal-backend/internal/core/port"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("timezone %s is not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestLastOccurrence(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	dhaka := mustLoadLocation(t, "Asia/Dhaka")
+
+	daily := func(tz string, hour, minute int) *domain.ReportSchedule {
+		return &domain.ReportSchedule{Frequency: domain.ReportDaily, Timezone: tz, SendHour: hour, SendMinute: minute}
+	}
+	weekly := func(tz string, weekday time.Weekday, hour, minute int) *domain.ReportSchedule {
+		s := daily(tz, hour, minute)
+		s.Frequency = domain.ReportWeekly
+		s.Weekday = int(weekday)
+		return s
+	}
+
+	tests := []struct {
+		name     string
+		schedule *domain.ReportSchedule
+		now      time.Time
+		want     time.Time
+	}{
+		{"later the same day", daily("America/New_York", 8, 0),
+			time.Date(2024, 5, 10, 9, 0, 0, 0, newYork), time.Date(2024, 5, 10, 8, 0, 0, 0, newYork)},
+		{"exactly at the send time", daily("America/New_York", 8, 0),
+			time.Date(2024, 5, 10, 8, 0, 0, 0, newYork), time.Date(2024, 5, 10, 8, 0, 0, 0, newYork)},
+		{"before the send time", daily("America/New_York", 8, 0),
+			time.Date(2024, 5, 10, 7, 59, 0, 0, newYork), time.Date(2024, 5, 9, 8, 0, 0, 0, newYork)},
+		{"back over a leap day", daily("America/New_York", 8, 0),
+			time.Date(2024, 3, 1, 7, 0, 0, 0, newYork), time.Date(2024, 2, 29, 8, 0, 0, 0, newYork)},
+		{"back over the end of a 31-day month", daily("America/New_York", 8, 0),
+			time.Date(2024, 8, 1, 7, 0, 0, 0, newYork), time.Date(2024, 7, 31, 8, 0, 0, 0, newYork)},
+		{"back over the year end", daily("America/New_York", 23, 30),
+			time.Date(2025, 1, 1, 0, 10, 0, 0, newYork), time.Date(2024, 12, 31, 23, 30, 0, 0, newYork)},
+		// 23:30 UTC on 30 June is already 1 July in Dhaka
+		{"local day differs from UTC", daily("Asia/Dhaka", 6, 0),
+			time.Date(2024, 6, 30, 23, 30, 0, 0, time.UTC), time.Date(2024, 6, 30, 6, 0, 0, 0, dhaka)},
+		{"local day after UTC", daily("Asia/Dhaka", 6, 0),
+			time.Date(2024, 7, 1, 0, 30, 0, 0, time.UTC), time.Date(2024, 7, 1, 6, 0, 0, 0, dhaka)},
+		// 02:30 doesn't exist on 10 March 2024 in New York
+		{"send time skipped by DST, before the gap", daily("America/New_York", 2, 30),
+			time.Date(2024, 3, 10, 1, 0, 0, 0, newYork), time.Date(2024, 3, 9, 2, 30, 0, 0, newYork)},
+		{"send time skipped by DST, after the gap", daily("America/New_York", 2, 30),
+			time.Date(2024, 3, 10, 12, 0, 0, 0, newYork), time.Date(2024, 3, 10, 2, 30, 0, 0, newYork)},
+		{"day after DST starts", daily("America/New_York", 8, 0),
+			time.Date(2024, 3, 11, 7, 0, 0, 0, newYork), time.Date(2024, 3, 10, 8, 0, 0, 0, newYork)},
+		{"day DST ends", daily("America/New_York", 8, 0),
+			time.Date(2024, 11, 3, 9, 0, 0, 0, newYork), time.Date(2024, 11, 3, 8, 0, 0, 0, newYork)},
+		{"weekly later in the week", weekly("America/New_York", time.Monday, 9, 0),
+			time.Date(2024, 6, 30, 12, 0, 0, 0, newYork), time.Date(2024, 6, 24, 9, 0, 0, 0, newYork)},
+		{"weekly on the day, before the send time", weekly("America/New_York", time.Monday, 9, 0),
+			time.Date(2024, 7, 1, 8, 0, 0, 0, newYork), time.Date(2024, 6, 24, 9, 0, 0, 0, newYork)},
+		{"weekly on the day, after the send time", weekly("America/New_York", time.Monday, 9, 0),
+			time.Date(2024, 7, 1, 10, 0, 0, 0, newYork), time.Date(2024, 7, 1, 9, 0, 0, 0, newYork)},
+		{"weekly back over a month end", weekly("America/New_York", time.Wednesday, 9, 0),
+			time.Date(2024, 3, 2, 12, 0, 0, 0, newYork), time.Date(2024, 2, 28, 9, 0, 0, 0, newYork)},
+		{"weekly back over DST starting", weekly("America/New_York", time.Sunday, 2, 30),
+			time.Date(2024, 3, 16, 12, 0, 0, 0, newYork), time.Date(2024, 3, 10, 2, 30, 0, 0, newYork)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lastOccurrence(tt.schedule, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("lastOccurrence = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// RunDue sends whenever the occurrence moves past the last one handled, so
+// across a DST change every day must still yield exactly one new occurrence
+func TestLastOccurrenceOncePerDayAcrossDST(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	for _, tt := range []struct {
+		name  string
+		start time.Time
+	}{
+		{"clocks go forward", time.Date(2024, 3, 8, 12, 0, 0, 0, newYork)},
+		{"clocks go back", time.Date(2024, 11, 1, 12, 0, 0, 0, newYork)},
+	} {
+		for _, hour := range []int{0, 1, 2, 3, 23} {
+			schedule := &domain.ReportSchedule{Frequency: domain.ReportDaily, Timezone: "America/New_York", SendHour: hour, SendMinute: 30}
+
+			var last time.Time
+			fired := 0
+			for now := tt.start; now.Before(tt.start.Add(96 * time.Hour)); now = now.Add(15 * time.Minute) {
+				occurrence, err := lastOccurrence(schedule, now)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if occurrence.Before(last) {
+					t.Fatalf("%s, %02d:30: occurrence went back from %v to %v at %v", tt.name, hour, last, occurrence, now)
+				}
+				if occurrence.After(last) {
+					fired++
+					last = occurrence
+				}
+			}
+			// The first check fires for the day before the window
+			if fired != 5 {
+				t.Errorf("%s, %02d:30: fired %d times over four days, want 5", tt.name, hour, fired)
+			}
+		}
+	}
+}
+
+func TestReportPeriod(t *testing.T) {
+	london := mustLoadLocation(t, "Europe/London")
+
+	tests := []struct {
+		name       string
+		frequency  string
+		occurrence time.Time
+		from, to   time.Time
+		hours      float64
+	}{
+		{"daily", domain.ReportDaily,
+			time.Date(2024, 5, 10, 8, 0, 0, 0, london), time.Date(2024, 5, 9, 0, 0, 0, 0, london), time.Date(2024, 5, 10, 0, 0, 0, 0, london), 24},
+		{"daily over a leap day", domain.ReportDaily,
+			time.Date(2024, 3, 1, 8, 0, 0, 0, london), time.Date(2024, 2, 29, 0, 0, 0, 0, london), time.Date(2024, 3, 1, 0, 0, 0, 0, london), 24},
+		{"daily over the year end", domain.ReportDaily,
+			time.Date(2025, 1, 1, 8, 0, 0, 0, london), time.Date(2024, 12, 31, 0, 0, 0, 0, london), time.Date(2025, 1, 1, 0, 0, 0, 0, london), 24},
+		{"daily when clocks go forward", domain.ReportDaily,
+			time.Date(2024, 4, 1, 8, 0, 0, 0, london), time.Date(2024, 3, 31, 0, 0, 0, 0, london), time.Date(2024, 4, 1, 0, 0, 0, 0, london), 23},
+		{"daily when clocks go back", domain.ReportDaily,
+			time.Date(2024, 10, 28, 8, 0, 0, 0, london), time.Date(2024, 10, 27, 0, 0, 0, 0, london), time.Date(2024, 10, 28, 0, 0, 0, 0, london), 25},
+		// Sent just after midnight UTC, which is still the previous day locally
+		{"daily sent from a UTC instant", domain.ReportDaily,
+			time.Date(2024, 6, 30, 23, 30, 0, 0, time.UTC), time.Date(2024, 6, 30, 0, 0, 0, 0, london), time.Date(2024, 7, 1, 0, 0, 0, 0, london), 24},
+		{"weekly over a month end", domain.ReportWeekly,
+			time.Date(2024, 3, 4, 9, 0, 0, 0, london), time.Date(2024, 2, 26, 0, 0, 0, 0, london), time.Date(2024, 3, 4, 0, 0, 0, 0, london), 168},
+		{"weekly when clocks go forward", domain.ReportWeekly,
+			time.Date(2024, 4, 1, 9, 0, 0, 0, london), time.Date(2024, 3, 25, 0, 0, 0, 0, london), time.Date(2024, 4, 1, 0, 0, 0, 0, london), 167},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &domain.ReportSchedule{Frequency: tt.frequency, Timezone: "Europe/London"}
+			from, to, err := reportPeriod(schedule, tt.occurrence)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !from.Equal(tt.from) || !to.Equal(tt.to) {
+				t.Errorf("period = %v to %v, want %v to %v", from, to, tt.from, tt.to)
+			}
+			if hours := to.Sub(from).Hours(); hours != tt.hours {
+				t.Errorf("period lasts %v hours, want %v", hours, tt.hours)
+			}
+		})
+	}
+}
+
+func TestRenderReportDates(t *testing.T) {
+	london := mustLoadLocation(t, "Europe/London")
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		subject  string
+	}{
+		{"single day", time.Date(2024, 5, 9, 0, 0, 0, 0, london), time.Date(2024, 5, 10, 0, 0, 0, 0, london),
+			"Report – Thu, 9 May 2024"},
+		{"23-hour day", time.Date(2024, 3, 31, 0, 0, 0, 0, london), time.Date(2024, 4, 1, 0, 0, 0, 0, london),
+			"Report – Sun, 31 Mar 2024"},
+		{"25-hour day", time.Date(2024, 10, 27, 0, 0, 0, 0, london), time.Date(2024, 10, 28, 0, 0, 0, 0, london),
+			"Report – Sun, 27 Oct 2024"},
+		{"week over a month end", time.Date(2024, 2, 26, 0, 0, 0, 0, london), time.Date(2024, 3, 4, 0, 0, 0, 0, london),
+			"Report – Mon, 26 Feb 2024 to Sun, 3 Mar 2024"},
+		{"week when clocks go back", time.Date(2024, 10, 21, 0, 0, 0, 0, london), time.Date(2024, 10, 28, 0, 0, 0, 0, london),
+			"Report – Mon, 21 Oct 2024 to Sun, 27 Oct 2024"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := renderReport(&domain.ReportSchedule{Name: "Report"}, tt.from, tt.to, &port.DashboardStats{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg.Subject != tt.subject {
+				t.Errorf("subject = %q, want %q", msg.Subject, tt.subject)
+			}
+			dates := strings.TrimPrefix(tt.subject, "Report – ")
+			if want := strings.Replace(dates, " to ", " - ", 1); !strings.Contains(msg.Text, want) {
+				t.Errorf("text body doesn't show %q:\n%s", want, msg.Text)
+			}
+			if want := strings.Replace(dates, " to ", " &ndash; ", 1); !strings.Contains(msg.HTML, want) {
+				t.Errorf("HTML body doesn't show %q", want)
+			}
+		})
+	}
+}
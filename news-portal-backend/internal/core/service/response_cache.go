@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"news-portal-backend/internal/core/port"
+)
+
+const responseCacheLoadTimeout = 10 * time.Second
+
+// ResponseCache caches read results in a port.Cache as JSON.
+//
+// Entries younger than fresh are served as is. Entries between fresh and
+// stale are served immediately while a background load refreshes them
+// (stale-while-revalidate). Concurrent misses for the same key share a
+// single load.
+type ResponseCache struct {
+	cache port.Cache
+	fresh time.Duration
+	stale time.Duration
+	group singleflight.Group
+
+	// generation is bumped on every invalidation so a load that started
+	// before a write doesn't store its outdated result afterwards
+	generation atomic.Uint64
+}
+
+type cachedValue struct {
+	StoredAt time.Time       `json:"stored_at"`
+	Value    json.RawMessage `json:"value"`
+}
+
+func NewResponseCache(cache port.Cache, fresh, stale time.Duration) *ResponseCache {
+	if stale < fresh {
+		stale = fresh
+	}
+	return &ResponseCache{cache: cache, fresh: fresh, stale: stale}
+}
+
+// Invalidate removes exact keys and every key starting with one of the
+// prefixes. Cache errors are logged; the entries then expire on their own.
+func (c *ResponseCache) Invalidate(ctx context.Context, keys []string, prefixes ...string) {
+	if c == nil {
+		return
+	}
+	c.generation.Add(1)
+
+	if len(keys) > 0 {
+		if err := c.cache.Delete(ctx, keys...); err != nil {
+			port.Logger(ctx).Error("Failed to invalidate cache", "keys", strings.Join(keys, ","), "error", err)
+		}
+	}
+	for _, prefix := range prefixes {
+		if err := c.cache.DeletePrefix(ctx, prefix); err != nil {
+			port.Logger(ctx).Error("Failed to invalidate cache", "prefix", prefix, "error", err)
+		}
+	}
+}
+
+// cachedLoad returns the cached value for key, calling load on a miss. With
+// a nil cache it always calls load.
+//
+// Loads are shared per key and generation: a request arriving after an
+// invalidation starts its own load rather than joining one that may have
+// read the data before the write.
+func cachedLoad[T any](ctx context.Context, c *ResponseCache, key string, load func(context.Context) (T, error)) (T, error) {
+	if c == nil {
+		return load(ctx)
+	}
+
+	gen := c.generation.Load()
+	flight := fmt.Sprintf("%s@%d", key, gen)
+
+	raw, ok, err := c.cache.Get(ctx, key)
+	if err != nil {
+		// A broken shared cache shouldn't take reads down with it
+		port.Logger(ctx).Error("Cache read failed", "key", key, "error", err)
+	}
+	if ok {
+		var entry cachedValue
+		var value T
+		if json.Unmarshal(raw, &entry) == nil && json.Unmarshal(entry.Value, &value) == nil {
+			if time.Since(entry.StoredAt) > c.fresh {
+				c.group.DoChan(flight, func() (any, error) {
+					return loadAndStore(c, key, gen, load)
+				})
+			}
+			return value, nil
+		}
+	}
+
+	ch := c.group.DoChan(flight, func() (any, error) {
+		return loadAndStore(c, key, gen, load)
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			var zero T
+			return zero, res.Err
+		}
+		return res.Val.(T), nil
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// loadAndStore runs load detached from the request that triggered it, so a
+// client that disconnects doesn't fail everyone waiting on the same key. The
+// result is only stored if nothing was invalidated since generation gen.
+func loadAndStore[T any](c *ResponseCache, key string, gen uint64, load func(context.Context) (T, error)) (any, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), responseCacheLoadTimeout)
+	defer cancel()
+
+	value, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c.generation.Load() != gen {
+		return value, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return value, nil
+	}
+	entry, err := json.Marshal(cachedValue{StoredAt: time.Now(), Value: raw})
+	if err != nil {
+		return value, nil
+	}
+	if err := c.cache.Set(ctx, key, entry, c.stale); err != nil {
+		port.Logger(ctx).Error("Cache write failed", "key", key, "error", err)
+	}
+	return value, nil
+}
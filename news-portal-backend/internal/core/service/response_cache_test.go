@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// A read that arrives after an invalidation must not join a load that
+// started before it, nor may that load's result be cached
+func TestCachedLoadAfterInvalidation(t *testing.T) {
+	c := NewResponseCache(NewMemoryCache(10), time.Minute, time.Minute)
+	ctx := context.Background()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	before := make(chan string, 1)
+	go func() {
+		v, err := cachedLoad(ctx, c, "key", func(ctx context.Context) (string, error) {
+			close(started)
+			<-release
+			return "old", nil
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		before <- v
+	}()
+	<-started
+
+	c.Invalidate(ctx, []string{"key"})
+	after, err := cachedLoad(ctx, c, "key", func(ctx context.Context) (string, error) {
+		return "new", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after != "new" {
+		t.Errorf("read after invalidation = %q, want %q", after, "new")
+	}
+
+	close(release)
+	if v := <-before; v != "old" {
+		t.Errorf("read before invalidation = %q, want %q", v, "old")
+	}
+	cached, err := cachedLoad(ctx, c, "key", func(ctx context.Context) (string, error) {
+		t.Error("cached value was not used")
+		return "", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached != "new" {
+		t.Errorf("cached value = %q, want %q", cached, "new")
+	}
+}
@@ -2,60 +2,259 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"news-portal-backend/internal/core/domain"
 	"news-portal-backend/internal/core/port"
 )
 
+const (
+	defaultTimeSeriesRange = 7 * 24 * time.Hour
+	maxHourlyRange         = 31 * 24 * time.Hour
+	maxDailyRange          = 2 * 366 * 24 * time.Hour
+
+	defaultStatsRange   = 30 * 24 * time.Hour
+	defaultTrafficRange = 30 * 24 * time.Hour
+	defaultTrafficLimit = 10
+	maxTrafficLimit     = 100
+
+	dashboardQueryConcurrency = 4
+)
+
 type StatsService struct {
-	newsRepo     port.NewsRepository
-	categoryRepo port.CategoryRepository
-	ownerRepo    port.OwnerRepository
+	newsRepo      port.NewsRepository
+	categoryRepo  port.CategoryRepository
+	ownerRepo     port.OwnerRepository
+	mediaRepo     port.MediaRepository
+	analyticsRepo port.AnalyticsRepository
 }
 
-func NewStatsService(newsRepo port.NewsRepository, categoryRepo port.CategoryRepository, ownerRepo port.OwnerRepository) *StatsService {
+func NewStatsService(newsRepo port.NewsRepository, categoryRepo port.CategoryRepository, ownerRepo port.OwnerRepository, mediaRepo port.MediaRepository, analyticsRepo port.AnalyticsRepository) *StatsService {
 	return &StatsService{
-		newsRepo:     newsRepo,
-		categoryRepo: categoryRepo,
-		ownerRepo:    ownerRepo,
+		newsRepo:      newsRepo,
+		categoryRepo:  categoryRepo,
+		ownerRepo:     ownerRepo,
+		mediaRepo:     mediaRepo,
+		analyticsRepo: analyticsRepo,
 	}
 }
 
-func (s *StatsService) GetDashboardStats(ctx context.Context) (*port.DashboardStats, error) {
-	totalNews, err := s.newsRepo.CountNews(ctx, nil, nil, nil, "")
-	if err != nil {
-		return nil, err
+// GetDashboardStats returns lifetime totals alongside activity in a period
+// and how it compares with the period of the same length just before it. The
+// period defaults to the last 30 days. A category or author narrows every
+// figure except the category, user and media counts.
+func (s *StatsService) GetDashboardStats(ctx context.Context, filter port.StatsFilter) (*port.DashboardStats, error) {
+	if filter.To.IsZero() {
+		filter.To = time.Now()
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.Add(-defaultStatsRange)
+	}
+	if !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("%w: from must be before to", domain.ErrInvalidInput)
+	}
+	if filter.To.Sub(filter.From) > maxDailyRange {
+		return nil, fmt.Errorf("%w: ranges are limited to two years", domain.ErrInvalidInput)
 	}
 
-	totalCategories, err := s.categoryRepo.CountCategories(ctx)
-	if err != nil {
+	previousFilter := filter
+	previousFilter.From = filter.From.Add(-filter.To.Sub(filter.From))
+	previousFilter.To = filter.From
+
+	// The queries are independent, so they run side by side. The limit keeps
+	// one dashboard from taking over the connection pool.
+	var (
+		totalNews, totalCategories, totalUsers int64
+		totalViews, totalRawViews              int64
+		categoryStats                          []port.CategoryViewStat
+		topNews                                []port.NewsViewStat
+		mediaDedup                             *port.MediaDedupStat
+		referrers                              map[string][]port.TrafficStat
+		period, previous                       *port.PeriodStats
+		authors                                []port.AuthorStat
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(dashboardQueryConcurrency)
+	g.Go(func() (err error) {
+		totalNews, err = s.newsRepo.CountNews(gctx, filter.CategoryID, filter.AuthorID, nil, "")
+		return err
+	})
+	g.Go(func() (err error) {
+		totalCategories, err = s.categoryRepo.CountCategories(gctx)
+		return err
+	})
+	g.Go(func() (err error) {
+		totalUsers, err = s.ownerRepo.CountOwners(gctx)
+		return err
+	})
+	g.Go(func() (err error) {
+		totalViews, err = s.newsRepo.CountTotalViews(gctx, filter.CategoryID, filter.AuthorID)
+		return err
+	})
+	g.Go(func() (err error) {
+		totalRawViews, err = s.newsRepo.CountTotalRawViews(gctx, filter.CategoryID, filter.AuthorID)
+		return err
+	})
+	g.Go(func() (err error) {
+		categoryStats, err = s.newsRepo.GetCategoryViewStats(gctx, filter)
+		return err
+	})
+	g.Go(func() (err error) {
+		topNews, err = s.newsRepo.GetTopNews(gctx, filter, 5) // Limit 5
+		return err
+	})
+	g.Go(func() (err error) {
+		mediaDedup, err = s.mediaRepo.GetMediaDedupStats(gctx)
+		return err
+	})
+	g.Go(func() (err error) {
+		referrers, err = s.analyticsRepo.GetTrafficBreakdown(gctx, port.TrafficFilter{
+			CategoryID: filter.CategoryID,
+			AuthorID:   filter.AuthorID,
+			From:       filter.From,
+			To:         filter.To,
+			Limit:      5,
+		})
+		return err
+	})
+	g.Go(func() (err error) {
+		period, err = s.analyticsRepo.GetPeriodStats(gctx, filter)
+		return err
+	})
+	g.Go(func() (err error) {
+		previous, err = s.analyticsRepo.GetPeriodStats(gctx, previousFilter)
+		return err
+	})
+	g.Go(func() (err error) {
+		authors, err = s.analyticsRepo.GetAuthorStats(gctx, filter)
+		return err
+	})
+	if err := g.Wait(); err != nil {
 		return nil, err
 	}
 
-	totalUsers, err := s.ownerRepo.CountOwners(ctx)
-	if err != nil {
-		return nil, err
+	return &port.DashboardStats{
+		TotalNews:       totalNews,
+		TotalCategories: totalCategories,
+		TotalUsers:      totalUsers,
+		TotalViews:      totalViews,
+		TotalRawViews:   totalRawViews,
+		CategoryStats:   categoryStats,
+		TopNews:         topNews,
+		MediaDedup:      mediaDedup,
+		TopReferrers:    nonNilStats(referrers[port.TrafficReferrer]),
+		Period:          *period,
+		PreviousPeriod:  *previous,
+		Deltas: port.PeriodDeltas{
+			ArticlesPublished: delta(period.ArticlesPublished, previous.ArticlesPublished),
+			Views:             delta(period.Views, previous.Views),
+			RawViews:          delta(period.RawViews, previous.RawViews),
+		},
+		Authors: authors,
+	}, nil
+}
+
+func delta(current, previous int64) port.StatDelta {
+	d := port.StatDelta{Change: current - previous}
+	if previous != 0 {
+		pct := float64(current-previous) / float64(previous) * 100
+		d.Percent = &pct
 	}
+	return d
+}
 
-	totalViews, err := s.newsRepo.CountTotalViews(ctx)
-	if err != nil {
-		return nil, err
+// GetViewTimeSeries returns views per hour or day for an article, a category
+// or the whole site. The range defaults to the last 7 days and the
+// granularity to hourly for ranges up to two days, daily otherwise.
+func (s *StatsService) GetViewTimeSeries(ctx context.Context, filter port.TimeSeriesFilter) (*port.TimeSeries, error) {
+	if filter.To.IsZero() {
+		filter.To = time.Now()
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.Add(-defaultTimeSeriesRange)
+	}
+	if !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("%w: from must be before to", domain.ErrInvalidInput)
+	}
+
+	span := filter.To.Sub(filter.From)
+	switch filter.Granularity {
+	case "":
+		filter.Granularity = port.GranularityDay
+		if span <= 48*time.Hour {
+			filter.Granularity = port.GranularityHour
+		}
+	case port.GranularityHour, port.GranularityDay:
+	default:
+		return nil, fmt.Errorf("%w: granularity must be hour or day", domain.ErrInvalidInput)
+	}
+
+	if filter.Granularity == port.GranularityHour && span > maxHourlyRange {
+		return nil, fmt.Errorf("%w: hourly ranges are limited to 31 days", domain.ErrInvalidInput)
+	}
+	if span > maxDailyRange {
+		return nil, fmt.Errorf("%w: ranges are limited to two years", domain.ErrInvalidInput)
 	}
 
-	categoryStats, err := s.newsRepo.GetCategoryViewStats(ctx)
+	points, err := s.analyticsRepo.GetViewTimeSeries(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	topNews, err := s.newsRepo.GetMonthlyTopNews(ctx, 5) // Limit 5
+	return &port.TimeSeries{
+		From:        filter.From,
+		To:          filter.To,
+		Granularity: filter.Granularity,
+		Points:      points,
+	}, nil
+}
+
+// GetTrafficBreakdown returns where an article's, or the whole site's,
+// unique views came from and how often it was shared. The range defaults to
+// the last 30 days.
+func (s *StatsService) GetTrafficBreakdown(ctx context.Context, filter port.TrafficFilter) (*port.TrafficBreakdown, error) {
+	if filter.To.IsZero() {
+		filter.To = time.Now()
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.Add(-defaultTrafficRange)
+	}
+	if !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("%w: from must be before to", domain.ErrInvalidInput)
+	}
+	if filter.To.Sub(filter.From) > maxDailyRange {
+		return nil, fmt.Errorf("%w: ranges are limited to two years", domain.ErrInvalidInput)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultTrafficLimit
+	}
+	if filter.Limit > maxTrafficLimit {
+		filter.Limit = maxTrafficLimit
+	}
+
+	stats, err := s.analyticsRepo.GetTrafficBreakdown(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	return &port.DashboardStats{
-		TotalNews:       totalNews,
-		TotalCategories: totalCategories,
-		TotalUsers:      totalUsers,
-		TotalViews:      totalViews,
-		CategoryStats:   categoryStats,
-		TopNews:         topNews,
+	return &port.TrafficBreakdown{
+		From:       filter.From,
+		To:         filter.To,
+		Referrers:  nonNilStats(stats[port.TrafficReferrer]),
+		UTMSources: nonNilStats(stats[port.TrafficUTMSource]),
+		UTMMediums: nonNilStats(stats[port.TrafficUTMMedium]),
+		Campaigns:  nonNilStats(stats[port.TrafficUTMCampaign]),
+		Shares:     nonNilStats(stats[port.TrafficShare]),
 	}, nil
 }
+
+// nonNilStats keeps empty breakdowns as [] rather than null in JSON
+func nonNilStats(stats []port.TrafficStat) []port.TrafficStat {
+	if stats == nil {
+		return []port.TrafficStat{}
+	}
+	return stats
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"news-portal-backend/internal/core/port"
+)
+
+// statsRepos records the category and author every dashboard query was
+// narrowed by
+type statsRepos struct {
+	port.NewsRepository
+	port.CategoryRepository
+	port.OwnerRepository
+	port.MediaRepository
+	port.AnalyticsRepository
+
+	mu   sync.Mutex
+	seen map[string][2]*uuid.UUID
+}
+
+func (r *statsRepos) record(query string, categoryID, authorID *uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seen[query] = [2]*uuid.UUID{categoryID, authorID}
+}
+
+func (r *statsRepos) CountNews(ctx context.Context, categoryID, authorID *uuid.UUID, isFeatured *bool, search string) (int64, error) {
+	r.record("CountNews", categoryID, authorID)
+	return 0, nil
+}
+
+func (r *statsRepos) CountTotalViews(ctx context.Context, categoryID, authorID *uuid.UUID) (int64, error) {
+	r.record("CountTotalViews", categoryID, authorID)
+	return 0, nil
+}
+
+func (r *statsRepos) CountTotalRawViews(ctx context.Context, categoryID, authorID *uuid.UUID) (int64, error) {
+	r.record("CountTotalRawViews", categoryID, authorID)
+	return 0, nil
+}
+
+func (r *statsRepos) GetCategoryViewStats(ctx context.Context, filter port.StatsFilter) ([]port.CategoryViewStat, error) {
+	r.record("GetCategoryViewStats", filter.CategoryID, filter.AuthorID)
+	return nil, nil
+}
+
+func (r *statsRepos) GetTopNews(ctx context.Context, filter port.StatsFilter, limit int) ([]port.NewsViewStat, error) {
+	r.record("GetTopNews", filter.CategoryID, filter.AuthorID)
+	return nil, nil
+}
+
+func (r *statsRepos) GetTrafficBreakdown(ctx context.Context, filter port.TrafficFilter) (map[string][]port.TrafficStat, error) {
+	r.record("GetTrafficBreakdown", filter.CategoryID, filter.AuthorID)
+	return nil, nil
+}
+
+func (r *statsRepos) GetPeriodStats(ctx context.Context, filter port.StatsFilter) (*port.PeriodStats, error) {
+	r.record("GetPeriodStats", filter.CategoryID, filter.AuthorID)
+	return &port.PeriodStats{From: filter.From, To: filter.To}, nil
+}
+
+func (r *statsRepos) GetAuthorStats(ctx context.Context, filter port.StatsFilter) ([]port.AuthorStat, error) {
+	r.record("GetAuthorStats", filter.CategoryID, filter.AuthorID)
+	return nil, nil
+}
+
+func (r *statsRepos) CountCategories(ctx context.Context) (int64, error) { return 0, nil }
+
+func (r *statsRepos) CountOwners(ctx context.Context) (int64, error) { return 0, nil }
+
+func (r *statsRepos) GetMediaDedupStats(ctx context.Context) (*port.MediaDedupStat, error) {
+	return &port.MediaDedupStat{}, nil
+}
+
+func TestDashboardStatsApplyFilterEverywhere(t *testing.T) {
+	repos := &statsRepos{seen: make(map[string][2]*uuid.UUID)}
+	s := NewStatsService(repos, repos, repos, repos, repos)
+	categoryID, authorID := uuid.New(), uuid.New()
+
+	_, err := s.GetDashboardStats(context.Background(), port.StatsFilter{
+		From:       time.Now().Add(-7 * 24 * time.Hour),
+		To:         time.Now(),
+		CategoryID: &categoryID,
+		AuthorID:   &authorID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, query := range []string{
+		"CountNews", "CountTotalViews", "CountTotalRawViews", "GetCategoryViewStats",
+		"GetTopNews", "GetTrafficBreakdown", "GetPeriodStats", "GetAuthorStats",
+	} {
+		got, ok := repos.seen[query]
+		if !ok {
+			t.Errorf("%s was not called", query)
+			continue
+		}
+		if got[0] == nil || *got[0] != categoryID || got[1] == nil || *got[1] != authorID {
+			t.Errorf("%s was not narrowed to the category and author", query)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"news-portal-backend/internal/core/port"
+)
+
+const (
+	maxTrafficValueLen = 100
+
+	// Referrer values that don't come from a hostname
+	ReferrerDirect   = "direct"
+	ReferrerInternal = "internal"
+)
+
+// Hostnames that belong to the same source, keyed by their registrable domain
+var referrerAliases = map[string]string{
+	"t.co":           "twitter.com",
+	"x.com":          "twitter.com",
+	"fb.com":         "facebook.com",
+	"fb.me":          "facebook.com",
+	"messenger.com":  "facebook.com",
+	"youtu.be":       "youtube.com",
+	"lnkd.in":        "linkedin.com",
+	"googleapis.com": "google.com",
+}
+
+// Subdomains that only distinguish a mobile site or a link shim
+var referrerPrefixes = []string{"www.", "m.", "mobile.", "l.", "lm.", "amp."}
+
+// Networks accepted by the share beacon; anything else is counted as "other"
+var shareNetworks = map[string]bool{
+	"facebook":  true,
+	"twitter":   true,
+	"whatsapp":  true,
+	"messenger": true,
+	"linkedin":  true,
+	"telegram":  true,
+	"email":     true,
+	"copy":      true,
+	"native":    true,
+}
+
+// TrafficClassifier turns the referrer and UTM parameters of a view into the
+// daily traffic counters it contributes to
+type TrafficClassifier struct {
+	internalHosts map[string]bool
+}
+
+// NewTrafficClassifier creates a classifier that reports referrers from the
+// given origins or hostnames as internal navigation
+func NewTrafficClassifier(internalOrigins []string) *TrafficClassifier {
+	hosts := make(map[string]bool, len(internalOrigins))
+	for _, origin := range internalOrigins {
+		origin = strings.TrimSpace(origin)
+		if origin == "" || origin == "*" {
+			continue
+		}
+		if !strings.Contains(origin, "://") {
+			origin = "https://" + origin
+		}
+		if u, err := url.Parse(origin); err == nil && u.Hostname() != "" {
+			hosts[trimReferrerHost(u.Hostname())] = true
+		}
+	}
+	return &TrafficClassifier{internalHosts: hosts}
+}
+
+// Classify returns one key per dimension the view carries. The referrer is
+// always recorded; UTM dimensions only when present.
+func (c *TrafficClassifier) Classify(newsID uuid.UUID, at time.Time, viewer port.Viewer) []port.TrafficKey {
+	day := at.UTC().Truncate(24 * time.Hour)
+	key := func(dimension, value string) port.TrafficKey {
+		return port.TrafficKey{NewsID: newsID, Day: day, Dimension: dimension, Value: value}
+	}
+
+	keys := []port.TrafficKey{key(port.TrafficReferrer, c.NormalizeReferrer(viewer.Referrer))}
+	if v := normalizeUTM(viewer.UTMSource); v != "" {
+		keys = append(keys, key(port.TrafficUTMSource, v))
+	}
+	if v := normalizeUTM(viewer.UTMMedium); v != "" {
+		keys = append(keys, key(port.TrafficUTMMedium, v))
+	}
+	if v := normalizeUTM(viewer.UTMCampaign); v != "" {
+		keys = append(keys, key(port.TrafficUTMCampaign, v))
+	}
+	return keys
+}
+
+// NormalizeReferrer reduces a referrer URL to the domain of the site that
+// sent the visitor, e.g. "https://l.facebook.com/l.php?u=..." to
+// "facebook.com". Android app referrers ("android-app://com.google...") keep
+// their package name.
+func (c *TrafficClassifier) NormalizeReferrer(referrer string) string {
+	referrer = strings.TrimSpace(referrer)
+	if referrer == "" {
+		return ReferrerDirect
+	}
+
+	u, err := url.Parse(referrer)
+	if err != nil {
+		return ReferrerDirect
+	}
+	if u.Scheme == "android-app" {
+		return truncate(strings.ToLower(u.Host), maxTrafficValueLen)
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		// Bare hostnames, as sent in ?ref=facebook.com
+		if u2, err := url.Parse("https://" + referrer); err == nil {
+			host = u2.Hostname()
+		}
+	}
+	if host == "" {
+		return ReferrerDirect
+	}
+
+	host = trimReferrerHost(host)
+	if c.internalHosts[host] {
+		return ReferrerInternal
+	}
+	if alias, ok := referrerAliases[host]; ok {
+		return alias
+	}
+	// Collapse regional search domains such as google.com.bd and google.co.uk
+	if strings.HasPrefix(host, "google.") {
+		return "google.com"
+	}
+	return truncate(host, maxTrafficValueLen)
+}
+
+// NormalizeShareNetwork maps a share button name to one of the known networks
+func NormalizeShareNetwork(network string) string {
+	network = strings.ToLower(strings.TrimSpace(network))
+	switch network {
+	case "x":
+		network = "twitter"
+	case "fb":
+		network = "facebook"
+	case "wa":
+		network = "whatsapp"
+	}
+	if shareNetworks[network] {
+		return network
+	}
+	return "other"
+}
+
+func trimReferrerHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, prefix := range referrerPrefixes {
+		if strings.HasPrefix(host, prefix) && strings.Count(host, ".") > 1 {
+			return strings.TrimPrefix(host, prefix)
+		}
+	}
+	return host
+}
+
+// normalizeUTM lowercases a campaign parameter so "Facebook" and "facebook"
+// count together
+func normalizeUTM(value string) string {
+	return truncate(strings.ToLower(strings.TrimSpace(value)), maxTrafficValueLen)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	// Don't cut a multi-byte character in half
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
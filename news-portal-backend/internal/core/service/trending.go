@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"news-portal-backend/internal/core/port"
+)
+
+// TrendingScorer recomputes the trending score of every article from its
+// recent hourly views. Each bucket's views are weighted by
+// 2^(-age/halfLife), so a story has to keep being read to stay on top.
+type TrendingScorer struct {
+	repo     port.AnalyticsRepository
+	halfLife time.Duration
+	window   time.Duration
+}
+
+// NewTrendingScorer only considers views from the last window, which should
+// be several half-lives long.
+func NewTrendingScorer(repo port.AnalyticsRepository, halfLife, window time.Duration) *TrendingScorer {
+	return &TrendingScorer{repo: repo, halfLife: halfLife, window: window}
+}
+
+func (t *TrendingScorer) Refresh(ctx context.Context) error {
+	return t.repo.RefreshTrendingScores(ctx, t.halfLife, t.window)
+}
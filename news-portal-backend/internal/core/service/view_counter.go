@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"news-portal-backend/internal/core/port"
+)
+
+// ViewCounter buffers article view increments in memory and writes them to
+// the database in one batched statement per interval. This keeps hot articles
+// from serializing every read on a row lock.
+//
+// Every request counts as a raw view. Only requests from real visitors that
+// haven't seen the article within the dedup window count as unique views, and
+// only unique views are attributed to a referrer or campaign.
+type ViewCounter struct {
+	repo      port.NewsRepository
+	analytics port.AnalyticsRepository
+	dedup     *VisitorDedup
+	sources   *TrafficClassifier
+	interval  time.Duration
+	maxKeys   int
+
+	mu      sync.Mutex
+	pending map[port.ViewKey]port.ViewDelta
+	traffic map[port.TrafficKey]int64
+
+	// Increments lost to a full buffer since the last flush: raw views, and
+	// traffic counts (sources and shares)
+	droppedViews   int64
+	droppedTraffic int64
+
+	flushNow chan struct{}
+	stop     chan struct{}
+	stopped  chan struct{}
+	once     sync.Once
+}
+
+// NewViewCounter creates a counter that flushes every interval. At most
+// maxKeys distinct article/hour pairs, and as many traffic counters, are
+// buffered between flushes.
+func NewViewCounter(repo port.NewsRepository, analytics port.AnalyticsRepository, dedup *VisitorDedup, sources *TrafficClassifier, interval time.Duration, maxKeys int) *ViewCounter {
+	return &ViewCounter{
+		repo:      repo,
+		analytics: analytics,
+		dedup:     dedup,
+		sources:   sources,
+		interval:  interval,
+		maxKeys:   maxKeys,
+		pending:   make(map[port.ViewKey]port.ViewDelta),
+		traffic:   make(map[port.TrafficKey]int64),
+		flushNow:  make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+}
+
+// RecordView counts one view. It never blocks on the database.
+func (c *ViewCounter) RecordView(newsID uuid.UUID, viewer port.Viewer) {
+	delta := port.ViewDelta{Raw: 1}
+	human := !viewer.Prefetch && !IsBotUserAgent(viewer.UserAgent)
+	if human && c.dedup.FirstView(newsID, viewer.IP, viewer.UserAgent) {
+		delta.Unique = 1
+	}
+
+	now := time.Now().UTC()
+	key := port.ViewKey{NewsID: newsID, Hour: now.Truncate(time.Hour)}
+
+	var sources []port.TrafficKey
+	if delta.Unique > 0 {
+		sources = c.sources.Classify(newsID, now, viewer)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.pending[key]; !ok && len(c.pending) >= c.maxKeys {
+		c.dropLocked(1, int64(len(sources)))
+		return
+	}
+	c.pending[key] = c.pending[key].Add(delta)
+
+	for _, source := range sources {
+		c.addTrafficLocked(source, 1)
+	}
+}
+
+// RecordShare counts a click on a social share button, once per visitor,
+// article and network within the dedup window
+func (c *ViewCounter) RecordShare(newsID uuid.UUID, network string, viewer port.Viewer) {
+	if IsBotUserAgent(viewer.UserAgent) {
+		return
+	}
+	network = NormalizeShareNetwork(network)
+	if !c.dedup.FirstShare(newsID, network, viewer.IP, viewer.UserAgent) {
+		return
+	}
+	key := port.TrafficKey{
+		NewsID:    newsID,
+		Day:       time.Now().UTC().Truncate(24 * time.Hour),
+		Dimension: port.TrafficShare,
+		Value:     network,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addTrafficLocked(key, 1)
+}
+
+func (c *ViewCounter) addTrafficLocked(key port.TrafficKey, n int64) {
+	if _, ok := c.traffic[key]; !ok && len(c.traffic) >= c.maxKeys {
+		c.dropLocked(0, n)
+		return
+	}
+	c.traffic[key] += n
+}
+
+// dropLocked discards increments because the buffer is full and asks for an
+// early flush
+func (c *ViewCounter) dropLocked(views, traffic int64) {
+	c.droppedViews += views
+	c.droppedTraffic += traffic
+	select {
+	case c.flushNow <- struct{}{}:
+	default:
+	}
+}
+
+// Run flushes periodically until Close is called. It should be started once
+// in its own goroutine.
+func (c *ViewCounter) Run() {
+	defer close(c.stopped)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.flush(context.Background())
+		case <-c.flushNow:
+			c.flush(context.Background())
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// Close stops the flush loop and writes whatever is still buffered.
+func (c *ViewCounter) Close(ctx context.Context) error {
+	c.once.Do(func() { close(c.stop) })
+
+	select {
+	case <-c.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return c.flush(ctx)
+}
+
+func (c *ViewCounter) flush(ctx context.Context) error {
+	c.mu.Lock()
+	views, traffic := c.pending, c.traffic
+	droppedViews, droppedTraffic := c.droppedViews, c.droppedTraffic
+	c.pending = make(map[port.ViewKey]port.ViewDelta, len(views))
+	c.traffic = make(map[port.TrafficKey]int64, len(traffic))
+	c.droppedViews, c.droppedTraffic = 0, 0
+	c.mu.Unlock()
+
+	if droppedViews > 0 || droppedTraffic > 0 {
+		slog.Warn("View counter buffer full, counts dropped", "views", droppedViews, "traffic", droppedTraffic)
+	}
+
+	var firstErr error
+	if len(views) > 0 {
+		if err := c.repo.AddNewsViews(ctx, views); err != nil {
+			slog.Error("Failed to flush view counts", "keys", len(views), "error", err)
+			c.requeue(views, nil)
+			firstErr = err
+		}
+	}
+	if len(traffic) > 0 {
+		if err := c.analytics.AddTrafficCounts(ctx, traffic); err != nil {
+			slog.Error("Failed to flush traffic counts", "keys", len(traffic), "error", err)
+			c.requeue(nil, traffic)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// requeue puts a failed batch back so the next flush retries it
+func (c *ViewCounter) requeue(views map[port.ViewKey]port.ViewDelta, traffic map[port.TrafficKey]int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, delta := range views {
+		if _, ok := c.pending[key]; !ok && len(c.pending) >= c.maxKeys {
+			c.droppedViews += delta.Raw
+			continue
+		}
+		c.pending[key] = c.pending[key].Add(delta)
+	}
+	for key, n := range traffic {
+		if _, ok := c.traffic[key]; !ok && len(c.traffic) >= c.maxKeys {
+			c.droppedTraffic += n
+			continue
+		}
+		c.traffic[key] += n
+	}
+}
+
+var _ port.ViewCounter = (*ViewCounter)(nil)
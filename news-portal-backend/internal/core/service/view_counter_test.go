@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"news-portal-backend/internal/core/port"
+)
+
+// failingViewRepos fails every write, calling during first so the test can
+// fill the buffer while a batch is out
+type failingViewRepos struct {
+	port.NewsRepository
+	port.AnalyticsRepository
+	during func()
+}
+
+func (r *failingViewRepos) AddNewsViews(ctx context.Context, views map[port.ViewKey]port.ViewDelta) error {
+	r.during()
+	return errors.New("database unavailable")
+}
+
+func (r *failingViewRepos) AddTrafficCounts(ctx context.Context, counts map[port.TrafficKey]int64) error {
+	r.during()
+	return errors.New("database unavailable")
+}
+
+// Increments that don't fit back into a full buffer are counted as dropped
+// in their own unit: raw views for views, counts for traffic
+func TestViewCounterRequeueOverflow(t *testing.T) {
+	repos := &failingViewRepos{}
+	c := NewViewCounter(repos, repos, NewVisitorDedup(time.Hour, 100), NewTrafficClassifier(nil), time.Hour, 1)
+
+	first, second := uuid.New(), uuid.New()
+	prefetch := port.Viewer{IP: "198.51.100.4", UserAgent: "Mozilla/5.0", Prefetch: true}
+	for range 3 {
+		c.RecordView(first, prefetch)
+	}
+	// Shares are deduplicated per visitor, so these come from two
+	for _, ip := range []string{"198.51.100.5", "198.51.100.6"} {
+		c.RecordShare(first, "facebook", port.Viewer{IP: ip, UserAgent: "Mozilla/5.0"})
+	}
+
+	var filled bool
+	repos.during = func() {
+		if !filled {
+			filled = true
+			c.RecordView(second, prefetch)
+			c.RecordShare(second, "facebook", prefetch)
+		}
+	}
+	if err := c.flush(context.Background()); err == nil {
+		t.Fatal("flush succeeded, want the repository error")
+	}
+
+	if c.droppedViews != 3 {
+		t.Errorf("droppedViews = %d, want 3", c.droppedViews)
+	}
+	if c.droppedTraffic != 2 {
+		t.Errorf("droppedTraffic = %d, want 2", c.droppedTraffic)
+	}
+	if len(c.pending) != 1 || len(c.traffic) != 1 {
+		t.Errorf("buffered %d views and %d traffic counts, want the second article's", len(c.pending), len(c.traffic))
+	}
+}
+
+// A visitor sharing one article to one network again within the dedup
+// window counts once
+func TestViewCounterCountsSharesOncePerVisitor(t *testing.T) {
+	c := NewViewCounter(nil, nil, NewVisitorDedup(time.Hour, 100), NewTrafficClassifier(nil), time.Hour, 100)
+	id := uuid.New()
+	viewer := port.Viewer{IP: "198.51.100.4", UserAgent: "Mozilla/5.0"}
+
+	for range 5 {
+		c.RecordShare(id, "facebook", viewer)
+	}
+	c.RecordShare(id, "x", viewer)
+	c.RecordShare(id, "facebook", port.Viewer{IP: "198.51.100.5", UserAgent: "Mozilla/5.0"})
+
+	var total int64
+	for key, n := range c.traffic {
+		if key.Dimension == port.TrafficShare {
+			total += n
+		}
+	}
+	if total != 3 {
+		t.Errorf("counted %d shares, want 3", total)
+	}
+}
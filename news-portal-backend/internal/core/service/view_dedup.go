@@ -0,0 +1,140 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Substrings found in the user agents of crawlers, monitors and scripts
+var botUserAgent = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|scrap|fetch|monitor|uptime|pingdom|statuscake|headless|lighthouse|preview|facebookexternalhit|whatsapp|curl|wget|python|java/|go-http-client|okhttp|axios|node-fetch|httpclient|libwww|postman|insomnia`)
+
+// IsBotUserAgent reports whether a user agent belongs to an automated client.
+// An empty user agent is treated as a bot.
+func IsBotUserAgent(ua string) bool {
+	return ua == "" || botUserAgent.MatchString(ua)
+}
+
+// VisitorDedup remembers which visitors have recently viewed which article.
+// Visitors are identified by a hash of IP and user agent salted with a random
+// value that is replaced every UTC day, so the stored keys can't be linked
+// back to an IP or across days. The previous day's salt is kept for one more
+// window, so a visitor seen just before midnight isn't counted again just
+// after it.
+//
+// Each API replica remembers only the visitors it served itself, so behind
+// a load balancer without sticky sessions a visitor can be counted once per
+// replica within the window.
+type VisitorDedup struct {
+	window     time.Duration
+	maxEntries int
+
+	mu       sync.Mutex
+	salt     []byte
+	saltDay  string
+	prevSalt []byte
+	// prevUntil is when keys under the previous salt have all expired
+	prevUntil time.Time
+	seen      map[[sha256.Size]byte]time.Time
+}
+
+func NewVisitorDedup(window time.Duration, maxEntries int) *VisitorDedup {
+	return &VisitorDedup{
+		window:     window,
+		maxEntries: maxEntries,
+		seen:       make(map[[sha256.Size]byte]time.Time),
+	}
+}
+
+// FirstView reports whether this visitor has not viewed the article within
+// the window, and records the view.
+func (d *VisitorDedup) FirstView(newsID uuid.UUID, ip, userAgent string) bool {
+	return d.first(newsID, "view", ip, userAgent)
+}
+
+// FirstShare reports whether this visitor has not shared the article to the
+// network within the window, and records the share.
+func (d *VisitorDedup) FirstShare(newsID uuid.UUID, network, ip, userAgent string) bool {
+	return d.first(newsID, "share:"+network, ip, userAgent)
+}
+
+// first records that the visitor did action on the article, reporting
+// whether they hadn't within the window
+func (d *VisitorDedup) first(newsID uuid.UUID, action, ip, userAgent string) bool {
+	now := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.rotateSalt(now)
+
+	key := visitorKey(d.salt, newsID, action, ip, userAgent)
+	if d.seenAt(key, now) {
+		return false
+	}
+	if d.prevSalt != nil && d.seenAt(visitorKey(d.prevSalt, newsID, action, ip, userAgent), now) {
+		return false
+	}
+
+	if len(d.seen) >= d.maxEntries {
+		d.evictExpired(now)
+		if len(d.seen) >= d.maxEntries {
+			// Still full: count the view rather than grow without bound
+			return true
+		}
+	}
+	d.seen[key] = now.Add(d.window)
+	return true
+}
+
+func visitorKey(salt []byte, newsID uuid.UUID, action, ip, userAgent string) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(ip))
+	h.Write([]byte{0})
+	h.Write([]byte(userAgent))
+	h.Write([]byte{0})
+	h.Write(newsID[:])
+	h.Write([]byte(action))
+	var key [sha256.Size]byte
+	copy(key[:], h.Sum(nil))
+	return key
+}
+
+func (d *VisitorDedup) seenAt(key [sha256.Size]byte, now time.Time) bool {
+	expires, ok := d.seen[key]
+	return ok && now.Before(expires)
+}
+
+func (d *VisitorDedup) rotateSalt(now time.Time) {
+	if d.prevSalt != nil && !now.Before(d.prevUntil) {
+		d.prevSalt = nil
+	}
+
+	day := now.UTC().Format(time.DateOnly)
+	if day == d.saltDay {
+		return
+	}
+	if d.salt != nil {
+		// Keys under the old salt stay until they expire
+		d.prevSalt = d.salt
+		d.prevUntil = now.Add(d.window)
+		d.evictExpired(now)
+	}
+	salt := make([]byte, 32)
+	rand.Read(salt)
+	d.salt = salt
+	d.saltDay = day
+}
+
+func (d *VisitorDedup) evictExpired(now time.Time) {
+	for key, expires := range d.seen {
+		if !now.Before(expires) {
+			delete(d.seen, key)
+		}
+	}
+}
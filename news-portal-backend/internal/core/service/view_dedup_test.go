@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A visitor seen just before the salt rotates at midnight isn't counted
+// again just after it
+func TestVisitorDedupAcrossSaltRotation(t *testing.T) {
+	d := NewVisitorDedup(time.Hour, 100)
+	id := uuid.New()
+
+	if !d.FirstView(id, "198.51.100.4", "Mozilla/5.0") {
+		t.Fatal("first view was not counted")
+	}
+	d.saltDay = "2000-01-01"
+	if d.FirstView(id, "198.51.100.4", "Mozilla/5.0") {
+		t.Error("view after the rotation was counted again")
+	}
+	if !d.FirstView(uuid.New(), "198.51.100.4", "Mozilla/5.0") {
+		t.Error("view of another article was not counted")
+	}
+
+	// Once the old keys have expired the old salt is forgotten
+	d.prevUntil = time.Now()
+	d.rotateSalt(time.Now())
+	if d.prevSalt != nil {
+		t.Error("previous salt kept after its keys expired")
+	}
+}
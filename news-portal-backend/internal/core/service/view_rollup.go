@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"news-portal-backend/internal/core/port"
+)
+
+// ViewRollup compacts hourly view buckets older than the retention period
+// into daily rows. Rollup is meant to be run periodically as a Job.
+type ViewRollup struct {
+	repo      port.AnalyticsRepository
+	retention time.Duration
+}
+
+func NewViewRollup(repo port.AnalyticsRepository, retention time.Duration) *ViewRollup {
+	return &ViewRollup{repo: repo, retention: retention}
+}
+
+func (r *ViewRollup) Rollup(ctx context.Context) error {
+	compacted, err := r.repo.RollupHourlyViews(ctx, time.Now().Add(-r.retention))
+	if err != nil {
+		return err
+	}
+	if compacted > 0 {
+		slog.Info("Rolled up hourly views", "rows", compacted)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+-- Media library for uploads that go straight to object storage.
+-- A row is created as 'pending' when a presigned URL is issued and is
+-- finalized to 'ready' once the object has been verified.
+CREATE TABLE IF NOT EXISTS media (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id UUID REFERENCES owners(id) ON DELETE SET NULL,
+    object_key VARCHAR(255) NOT NULL UNIQUE,
+    url TEXT NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    status VARCHAR(20) DEFAULT 'pending' NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_owner_id ON media(owner_id);
+CREATE INDEX IF NOT EXISTS idx_media_status_created_at ON media(status, created_at DESC);
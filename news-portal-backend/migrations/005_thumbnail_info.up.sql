@@ -0,0 +1,6 @@
+-- Placeholder data for thumbnails so the frontend can reserve space and
+-- paint a blurred preview while the image loads.
+ALTER TABLE news ADD COLUMN IF NOT EXISTS thumbnail_width INTEGER;
+ALTER TABLE news ADD COLUMN IF NOT EXISTS thumbnail_height INTEGER;
+ALTER TABLE news ADD COLUMN IF NOT EXISTS thumbnail_blurhash VARCHAR(64);
+ALTER TABLE news ADD COLUMN IF NOT EXISTS thumbnail_color VARCHAR(7);
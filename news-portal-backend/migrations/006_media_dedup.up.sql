@@ -0,0 +1,11 @@
+-- Content hashes let identical uploads share one stored object.
+ALTER TABLE media ADD COLUMN IF NOT EXISTS sha256 CHAR(64);
+ALTER TABLE media ADD COLUMN IF NOT EXISTS reuse_count BIGINT DEFAULT 0 NOT NULL;
+
+-- Image placeholder data is kept with the media so reused uploads get it too
+ALTER TABLE media ADD COLUMN IF NOT EXISTS width INTEGER;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS height INTEGER;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS blurhash VARCHAR(64);
+ALTER TABLE media ADD COLUMN IF NOT EXISTS dominant_color VARCHAR(7);
+
+CREATE INDEX IF NOT EXISTS idx_media_sha256 ON media(sha256, size_bytes) WHERE status = 'ready';
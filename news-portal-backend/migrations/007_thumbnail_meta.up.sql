@@ -0,0 +1,5 @@
+-- Accessibility and attribution details for the article thumbnail
+ALTER TABLE news ADD COLUMN IF NOT EXISTS thumbnail_alt TEXT DEFAULT '' NOT NULL;
+ALTER TABLE news ADD COLUMN IF NOT EXISTS thumbnail_caption TEXT DEFAULT '' NOT NULL;
+ALTER TABLE news ADD COLUMN IF NOT EXISTS thumbnail_credit VARCHAR(255) DEFAULT '' NOT NULL;
+ALTER TABLE news ADD COLUMN IF NOT EXISTS thumbnail_source_url TEXT DEFAULT '' NOT NULL;
@@ -0,0 +1,4 @@
+-- views_count now only counts unique human views. raw_views_count keeps
+-- every request so editors can compare the two.
+ALTER TABLE news ADD COLUMN IF NOT EXISTS raw_views_count BIGINT DEFAULT 0 NOT NULL;
+UPDATE news SET raw_views_count = COALESCE(views_count, 0);
@@ -0,0 +1,21 @@
+-- Per-article view counts in hourly buckets. Old hourly rows are compacted
+-- into news_views_daily by the rollup job.
+CREATE TABLE IF NOT EXISTS news_views_hourly (
+    news_id UUID NOT NULL REFERENCES news(id) ON DELETE CASCADE,
+    bucket TIMESTAMP WITH TIME ZONE NOT NULL,
+    views BIGINT DEFAULT 0 NOT NULL,
+    raw_views BIGINT DEFAULT 0 NOT NULL,
+    PRIMARY KEY (news_id, bucket)
+);
+
+CREATE INDEX IF NOT EXISTS idx_news_views_hourly_bucket ON news_views_hourly(bucket);
+
+CREATE TABLE IF NOT EXISTS news_views_daily (
+    news_id UUID NOT NULL REFERENCES news(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    views BIGINT DEFAULT 0 NOT NULL,
+    raw_views BIGINT DEFAULT 0 NOT NULL,
+    PRIMARY KEY (news_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_news_views_daily_day ON news_views_daily(day);
@@ -0,0 +1,4 @@
+-- Time-decayed popularity, refreshed periodically from news_views_hourly
+ALTER TABLE news ADD COLUMN IF NOT EXISTS trending_score DOUBLE PRECISION DEFAULT 0 NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_news_trending_published_at ON news(trending_score DESC, published_at DESC);
@@ -0,0 +1,12 @@
+-- Daily traffic sources per article: normalized referrer domains, UTM
+-- parameters and share button clicks, one row per dimension and value.
+CREATE TABLE IF NOT EXISTS news_traffic_daily (
+    news_id UUID NOT NULL REFERENCES news(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    dimension VARCHAR(20) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    count BIGINT DEFAULT 0 NOT NULL,
+    PRIMARY KEY (news_id, day, dimension, value)
+);
+
+CREATE INDEX IF NOT EXISTS idx_news_traffic_daily_day ON news_traffic_daily(day, dimension);
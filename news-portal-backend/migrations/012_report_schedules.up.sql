@@ -0,0 +1,35 @@
+-- Editorial email reports. Each owner configures their own schedules; every
+-- attempt to send one is recorded in report_runs.
+CREATE TABLE IF NOT EXISTS report_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id UUID NOT NULL REFERENCES owners(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    recipients TEXT[] NOT NULL,
+    frequency VARCHAR(10) DEFAULT 'daily' NOT NULL,
+    -- Day of the week for weekly reports, 0 = Sunday
+    weekday SMALLINT DEFAULT 0 NOT NULL,
+    send_hour SMALLINT DEFAULT 8 NOT NULL,
+    send_minute SMALLINT DEFAULT 0 NOT NULL,
+    timezone VARCHAR(64) DEFAULT 'Asia/Dhaka' NOT NULL,
+    enabled BOOLEAN DEFAULT TRUE NOT NULL,
+    -- The most recent scheduled time a run was started for
+    last_scheduled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_schedules_owner_id ON report_schedules(owner_id);
+
+CREATE TABLE IF NOT EXISTS report_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL REFERENCES report_schedules(id) ON DELETE CASCADE,
+    status VARCHAR(20) DEFAULT 'running' NOT NULL,
+    period_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_to TIMESTAMP WITH TIME ZONE NOT NULL,
+    recipients TEXT[] NOT NULL,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_runs_schedule_started_at ON report_runs(schedule_id, started_at DESC);